@@ -0,0 +1,337 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// file apidiff.go reads the exported Go API of every package in a git tree,
+// and compares the API of two versions of a module, to reveal whether an
+// upgrade that semantic versioning deems compatible removes anything.
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io"
+	"path/filepath"
+	"strings"
+
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+const (
+	// APIAdded indicates an exported identifier that only the new version has.
+	APIAdded = "added"
+	// APIRemoved indicates an exported identifier that only the old version
+	// has.
+	APIRemoved = "removed"
+	// APIChanged indicates an exported identifier with a different
+	// declaration in each version.
+	APIChanged = "changed"
+)
+
+// API maps the name of every package to the declarations of its exported
+// identifiers, keyed by name.
+// Methods and fields are keyed by the name of their type, a dot, and their
+// own name, as in "Module.Summary".
+// Declarations omit parameter names and the values of constants and
+// variables, since changing these does not change the API.
+type API map[string]map[string]string
+
+// APIChange is an exported identifier of a package that was added, removed,
+// or changed between two versions.
+type APIChange struct {
+	Package string
+	Name    string
+	Change  string
+	Old     string
+	New     string
+}
+
+// ReadGitAPI reads the exported API of the packages in a git tree.
+// ReadGitAPI ignores commands, tests, and internal packages, which are not
+// part of the API, and returns warnings for files it cannot parse.
+func ReadGitAPI(repo *git.Repository, tree *object.Tree, name string) (API, []string, error) {
+	entry := GitEntry{
+		name: filepath.Base(name),
+		mode: filemode.Dir,
+		hash: tree.Hash,
+		repo: repo,
+	}
+	return readAPI(entry, name, gitExcludes)
+}
+
+func readAPI(entry TreeEntry, name string, excludes StringSet) (API, []string, error) {
+	api := make(API)
+	var warnings []string
+	walker := Walk(filepath.Dir(name), entry)
+	for {
+		path, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if entry, ok := entry.(GitEntry); ok && entry.IsSubmodule() {
+				warnings = append(warnings, fmt.Sprintf("Cannot read API in submodule %s: %s", path, err))
+				continue
+			}
+			return nil, warnings, err
+		}
+		if entry.IsDir() && (excludes.Has(entry.Name()) || entry.Name() == "internal") {
+			walker.Skip()
+			continue
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		reader, err := entry.Reader()
+		if err != nil {
+			return nil, warnings, err
+		}
+		err = digestGoAPI(path, reader, api)
+		reader.Close()
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("Unable to parse Go file %s: %s", path, err))
+		}
+	}
+	return api, warnings, nil
+}
+
+func digestGoAPI(path string, reader io.Reader, api API) error {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, reader, 0)
+	if err != nil {
+		return err
+	}
+	if f.Name.Name == "main" {
+		return nil
+	}
+	pkg := filepath.Dir(path)
+	decls := api[pkg]
+	if decls == nil {
+		decls = make(map[string]string)
+		api[pkg] = decls
+	}
+	declare := func(name, decl string) {
+		// Files for different platforms may declare the same identifier
+		// differently, so we keep every distinct declaration.
+		if prior, ok := decls[name]; ok && prior != decl {
+			alternatives := NewStringSet(strings.Split(prior, " | "))
+			alternatives.Add(decl)
+			decl = strings.Join(alternatives.Keys(), " | ")
+		}
+		decls[name] = decl
+	}
+
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if !decl.Name.IsExported() {
+				continue
+			}
+			if decl.Recv == nil {
+				declare(decl.Name.Name, "func "+decl.Name.Name+signatureString(fset, decl.Type))
+				continue
+			}
+			recv := decl.Recv.List[0].Type
+			typeName := receiverName(recv)
+			if !ast.IsExported(typeName) {
+				continue
+			}
+			declare(typeName+"."+decl.Name.Name, "func ("+exprString(fset, recv)+") "+decl.Name.Name+signatureString(fset, decl.Type))
+
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Name.IsExported() {
+						digestTypeAPI(fset, spec, declare)
+					}
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						if !name.IsExported() {
+							continue
+						}
+						value := decl.Tok.String() + " " + name.Name
+						if spec.Type != nil {
+							value += " " + exprString(fset, spec.Type)
+						}
+						declare(name.Name, value)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// digestTypeAPI declares an exported type, and separately each exported field
+// of a struct or method of an interface, so that changing one member does not
+// obscure the others, and unexported members do not appear at all.
+func digestTypeAPI(fset *token.FileSet, spec *ast.TypeSpec, declare func(name, decl string)) {
+	name := spec.Name.Name
+	alias := ""
+	if spec.Assign.IsValid() {
+		alias = "= "
+	}
+	switch typ := spec.Type.(type) {
+	case *ast.StructType:
+		declare(name, "type "+name+" struct")
+		for _, field := range typ.Fields.List {
+			if len(field.Names) == 0 {
+				// An embedded field is named for its type.
+				embedded := receiverName(field.Type)
+				if ast.IsExported(embedded) {
+					declare(name+"."+embedded, "field "+exprString(fset, field.Type))
+				}
+				continue
+			}
+			for _, fieldName := range field.Names {
+				if fieldName.IsExported() {
+					declare(name+"."+fieldName.Name, "field "+fieldName.Name+" "+exprString(fset, field.Type))
+				}
+			}
+		}
+	case *ast.InterfaceType:
+		declare(name, "type "+name+" interface")
+		for _, method := range typ.Methods.List {
+			if len(method.Names) == 0 {
+				declare(name+"."+exprString(fset, method.Type), "embed "+exprString(fset, method.Type))
+				continue
+			}
+			for _, methodName := range method.Names {
+				if methodName.IsExported() {
+					if signature, ok := method.Type.(*ast.FuncType); ok {
+						declare(name+"."+methodName.Name, "method "+methodName.Name+signatureString(fset, signature))
+					}
+				}
+			}
+		}
+	default:
+		declare(name, "type "+name+" "+alias+exprString(fset, spec.Type))
+	}
+}
+
+// receiverName returns the name of the type of a receiver or embedded field,
+// less any pointer or package qualifier.
+func receiverName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(expr.X)
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	case *ast.Ident:
+		return expr.Name
+	}
+	return ""
+}
+
+// signatureString renders the parameter and result types of a function,
+// without their names.
+func signatureString(fset *token.FileSet, typ *ast.FuncType) string {
+	signature := "(" + fieldTypesString(fset, typ.Params) + ")"
+	if typ.Results != nil && len(typ.Results.List) > 0 {
+		results := fieldTypesString(fset, typ.Results)
+		if len(typ.Results.List) == 1 && len(typ.Results.List[0].Names) <= 1 {
+			signature += " " + results
+		} else {
+			signature += " (" + results + ")"
+		}
+	}
+	return signature
+}
+
+func fieldTypesString(fset *token.FileSet, fields *ast.FieldList) string {
+	if fields == nil {
+		return ""
+	}
+	var types []string
+	for _, field := range fields.List {
+		typ := exprString(fset, field.Type)
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			types = append(types, typ)
+		}
+	}
+	return strings.Join(types, ", ")
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, expr); err != nil {
+		return "?"
+	}
+	// Collapse struct and interface literals onto one line.
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// DiffAPI returns the exported identifiers added, removed, or changed between
+// two versions of an API, ordered by package and name.
+func DiffAPI(old, new API) []APIChange {
+	packages := make(StringSet)
+	for pkg := range old {
+		packages.Add(pkg)
+	}
+	for pkg := range new {
+		packages.Add(pkg)
+	}
+
+	var changes []APIChange
+	for _, pkg := range packages.Keys() {
+		names := make(StringSet)
+		for name := range old[pkg] {
+			names.Add(name)
+		}
+		for name := range new[pkg] {
+			names.Add(name)
+		}
+		for _, name := range names.Keys() {
+			before, hadBefore := old[pkg][name]
+			after, hasAfter := new[pkg][name]
+			change := APIChange{Package: pkg, Name: name, Old: before, New: after}
+			switch {
+			case !hadBefore:
+				change.Change = APIAdded
+			case !hasAfter:
+				change.Change = APIRemoved
+			case before != after:
+				change.Change = APIChanged
+			default:
+				continue
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// APICompatible returns whether semantic versioning deems an upgrade from one
+// version of a module to another compatible, in which case the upgrade must
+// not remove any exported identifier.
+func APICompatible(old, new Module) bool {
+	return old.Version != NoVersion && new.Version != NoVersion && old.Version.CanUpgradeTo(new.Version)
+}
+
+// IsViolation returns whether an API change breaks the promise of a
+// compatible upgrade.
+func (change APIChange) IsViolation(compatible bool) bool {
+	return compatible && change.Change == APIRemoved
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+func TestDiffAPI(t *testing.T) {
+	repo, err := git.Init(memory.NewStorage(), nil)
+	require.NoError(t, err)
+
+	version := func(lib, internal string) *object.Tree {
+		hash := testGitTree(t, repo, []object.TreeEntry{
+			{Name: "internal", Mode: filemode.Dir, Hash: testGitTree(t, repo, []object.TreeEntry{
+				{Name: "internal.go", Mode: filemode.Regular, Hash: testGitBlob(t, repo, internal)},
+			})},
+			{Name: "lib.go", Mode: filemode.Regular, Hash: testGitBlob(t, repo, lib)},
+			{Name: "lib_test.go", Mode: filemode.Regular, Hash: testGitBlob(t, repo, "package lib\n\nfunc TestLib() {}\n")},
+		})
+		tree, err := repo.TreeObject(hash)
+		require.NoError(t, err)
+		return tree
+	}
+
+	old := version(`package lib
+
+const Answer = 42
+
+type Widget struct {
+	Name  string
+	count int
+}
+
+func (w *Widget) Count() int { return w.count }
+
+type Gadget interface {
+	Frob(name string) error
+}
+
+func Remove() {}
+
+func Change(a, b int) error { return nil }
+
+func unexported() {}
+`, "package internal\n\nfunc Hidden() {}\n")
+
+	new := version(`package lib
+
+const Answer = 43
+
+type Widget struct {
+	Name  string
+	Size  int
+	total int
+}
+
+func (w *Widget) Count() int { return w.total }
+
+type Gadget interface {
+	Frob(other string) error
+}
+
+func Change(a int, b ...int) error { return nil }
+
+func Add() {}
+`, "package internal\n\nfunc Other() {}\n")
+
+	oldAPI, warnings, err := ReadGitAPI(repo, old, "example.com/lib")
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, API{
+		"example.com/lib": {
+			"Answer":       "const Answer",
+			"Change":       "func Change(int, int) error",
+			"Gadget":       "type Gadget interface",
+			"Gadget.Frob":  "method Frob(string) error",
+			"Remove":       "func Remove()",
+			"Widget":       "type Widget struct",
+			"Widget.Count": "func (*Widget) Count() int",
+			"Widget.Name":  "field Name string",
+		},
+	}, oldAPI)
+
+	newAPI, _, err := ReadGitAPI(repo, new, "example.com/lib")
+	require.NoError(t, err)
+
+	changes := DiffAPI(oldAPI, newAPI)
+	assert.Equal(t, []APIChange{
+		{Package: "example.com/lib", Name: "Add", Change: APIAdded, New: "func Add()"},
+		{Package: "example.com/lib", Name: "Change", Change: APIChanged, Old: "func Change(int, int) error", New: "func Change(int, ...int) error"},
+		{Package: "example.com/lib", Name: "Remove", Change: APIRemoved, Old: "func Remove()"},
+		{Package: "example.com/lib", Name: "Widget.Size", Change: APIAdded, New: "field Size int"},
+	}, changes)
+
+	minor := APICompatible(Module{Version: Version{1, 0, 0}}, Module{Version: Version{1, 1, 0}})
+	major := APICompatible(Module{Version: Version{1, 0, 0}}, Module{Version: Version{2, 0, 0}})
+	assert.True(t, minor)
+	assert.False(t, major)
+	assert.Equal(t, 1, countAPIViolations(changes, minor))
+	assert.Equal(t, 0, countAPIViolations(changes, major))
+	assert.Equal(t, 0, countAPIViolations(changes, APICompatible(Module{Hash: plumbing.NewHash("a28ced3c")}, Module{Version: Version{1, 0, 0}})))
+}
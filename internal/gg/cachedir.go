@@ -0,0 +1,117 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// file cachedir.go locates the git repository that caches the refs/vendor
+// references and objects of every dependency.
+// The cache may be private to the working copy, in .gg, or shared by every
+// worktree of the project's own git repository, or shared by every project of
+// the user.
+// Each worktree stages its vendor checkout in its own index file, so
+// worktrees that share a cache do not trample each other's checkouts.
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// CacheRepositoryGG keeps the cache in a bare repository in the .gg
+	// directory of the working copy.
+	CacheRepositoryGG = "gg"
+	// CacheRepositoryGit keeps the cache in the project's own git repository,
+	// shared by all of its worktrees.
+	CacheRepositoryGit = "git"
+	// CacheRepositoryUser keeps the cache in a bare repository in the user's
+	// cache directory, shared by all of the user's projects.
+	CacheRepositoryUser = "user"
+)
+
+// CacheLocation returns the git directory of the repository cache and the
+// index file for staging the vendor checkout of the working copy, for the
+// given repository setting from gg.toml.
+func CacheLocation(workDir, repository string, getenv func(string) string) (string, string, error) {
+	switch repository {
+	case "", CacheRepositoryGG:
+		gitDir := filepath.Join(workDir, GGCachePath)
+		return gitDir, filepath.Join(gitDir, "INDEX"), nil
+
+	case CacheRepositoryGit:
+		// The common directory is shared by every worktree, but the git path
+		// of the index is particular to this worktree.
+		var stdout bytes.Buffer
+		var stderr bytes.Buffer
+		cmd := exec.Command("git", "rev-parse", "--git-common-dir", "--git-path", "gg-index")
+		cmd.Dir = workDir
+		cmd.Env = gitEnvWithout("GIT_DIR", "GIT_WORK_TREE", "GIT_INDEX_FILE")
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return "", "", fmt.Errorf("cannot use the git repository of the working copy as the cache: %s: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+		if len(lines) != 2 {
+			return "", "", fmt.Errorf("cannot use the git repository of the working copy as the cache: unexpected output from git rev-parse: %q", stdout.String())
+		}
+		gitDir, indexFile := lines[0], lines[1]
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(workDir, gitDir)
+		}
+		if !filepath.IsAbs(indexFile) {
+			indexFile = filepath.Join(workDir, indexFile)
+		}
+		return gitDir, indexFile, nil
+
+	case CacheRepositoryUser:
+		cacheHome := getenv("XDG_CACHE_HOME")
+		if cacheHome == "" {
+			home := getenv("HOME")
+			if home == "" {
+				return "", "", fmt.Errorf("cannot find the user cache directory because neither XDG_CACHE_HOME nor HOME are set")
+			}
+			cacheHome = filepath.Join(home, ".cache")
+		}
+		gitDir := filepath.Join(cacheHome, "gg")
+		// Every working copy gets its own index, keyed by its location.
+		indexDir := filepath.Join(gitDir, "indexes")
+		if err := os.MkdirAll(indexDir, 0755); err != nil {
+			return "", "", err
+		}
+		sum := sha256.Sum256([]byte(workDir))
+		return gitDir, filepath.Join(indexDir, hex.EncodeToString(sum[:8])), nil
+
+	default:
+		return "", "", fmt.Errorf("unrecognized repository %q in gg.toml, expected %q, %q, or %q", repository, CacheRepositoryGG, CacheRepositoryGit, CacheRepositoryUser)
+	}
+}
+
+// gitEnvWithout returns the os environment less the named variables, so that
+// git finds the repository of the working copy.
+func gitEnvWithout(names ...string) []string {
+	omit := NewStringSet(names)
+	env := make([]string, 0, len(os.Environ()))
+	for _, pair := range os.Environ() {
+		if !omit.Has(strings.SplitN(pair, "=", 2)[0]) {
+			env = append(env, pair)
+		}
+	}
+	return env
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheLocation(t *testing.T) {
+	root, err := ioutil.TempDir("", "gg-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	root, err = filepath.EvalSymlinks(root)
+	require.NoError(t, err)
+
+	getenv := func(name string) string {
+		if name == "HOME" {
+			return filepath.Join(root, "home")
+		}
+		return ""
+	}
+
+	t.Run("gg", func(t *testing.T) {
+		gitDir, indexFile, err := CacheLocation("/work", "", getenv)
+		require.NoError(t, err)
+		assert.Equal(t, "/work/.gg", gitDir)
+		assert.Equal(t, "/work/.gg/INDEX", indexFile)
+	})
+
+	t.Run("user", func(t *testing.T) {
+		gitDir, indexFile, err := CacheLocation("/work", "user", getenv)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(root, "home/.cache/gg"), gitDir)
+		assert.Equal(t, filepath.Join(root, "home/.cache/gg/indexes"), filepath.Dir(indexFile))
+
+		_, otherIndexFile, err := CacheLocation("/other", "user", getenv)
+		require.NoError(t, err)
+		assert.NotEqual(t, indexFile, otherIndexFile)
+	})
+
+	t.Run("git", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git is not installed")
+		}
+		main := filepath.Join(root, "main")
+		worktree := filepath.Join(root, "worktree")
+		git := func(dir string, args ...string) {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = dir
+			cmd.Env = append(gitEnvWithout("GIT_DIR", "GIT_WORK_TREE", "GIT_INDEX_FILE"),
+				"GIT_AUTHOR_NAME=gg", "GIT_AUTHOR_EMAIL=gg@example.com",
+				"GIT_COMMITTER_NAME=gg", "GIT_COMMITTER_EMAIL=gg@example.com",
+			)
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, "%s", out)
+		}
+		require.NoError(t, os.MkdirAll(main, 0755))
+		git(main, "init", "-q")
+		git(main, "commit", "-q", "--allow-empty", "-m", "First")
+		git(main, "worktree", "add", "-q", "--detach", worktree)
+
+		gitDir, indexFile, err := CacheLocation(main, "git", getenv)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(main, ".git"), gitDir)
+		assert.Equal(t, filepath.Join(main, ".git/gg-index"), indexFile)
+
+		worktreeGitDir, worktreeIndexFile, err := CacheLocation(worktree, "git", getenv)
+		require.NoError(t, err)
+		assert.Equal(t, gitDir, worktreeGitDir)
+		assert.NotEqual(t, indexFile, worktreeIndexFile)
+
+		_, _, err = CacheLocation(root, "git", getenv)
+		assert.Error(t, err)
+	})
+
+	t.Run("unrecognized", func(t *testing.T) {
+		_, _, err := CacheLocation("/work", "elsewhere", getenv)
+		assert.EqualError(t, err, `unrecognized repository "elsewhere" in gg.toml, expected "gg", "git", or "user"`)
+	})
+}
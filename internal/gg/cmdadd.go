@@ -35,6 +35,9 @@ reference like "tags/v1.0.0" or "heads/master".  The specifier can be a hash or
 hash prefix.  In the absence of a specifier, gg will choose the highest
 version, and if there are no version references, will choose "heads/master".
 
+In the place of the module, an abbreviated commit hash like "3fe1cac1" adds
+the version of whichever module in the cache has that commit.
+
 An add commands alone on the command line implies reading glide.lock in before,
 writing glide.lock out after, and checking out the new vendor.
 `
@@ -74,17 +77,23 @@ func Add(ctx context.Context, driver *Driver, spec string, test bool) error {
 	memo := driver.memo
 	state := driver.next
 
-	module, err := memo.FindModule(ctx, driver.err, spec, test)
+	var module Module
+	var err error
+	if IsHashPrefix(spec) {
+		module, err = driver.FindHashPrefixModule(ctx, spec, test)
+	} else {
+		module, err = memo.FindModule(ctx, driver.err, spec, test)
+	}
 	if err != nil {
 		return fmt.Errorf("Unable to add module %s: %s", spec, err)
 	}
 
 	state, err = state.Add(ctx, memo, driver.err, module)
-	if err != nil {
-		return fmt.Errorf("unable to add module %s: %s", module.Summary(), err)
-	}
 	driver.push(state)
 
 	ShowDiff(driver.out, driver.prev.Modules(), driver.next.Modules())
+	if err != nil {
+		return fmt.Errorf("unable to add module %s: %s", module.Summary(), err)
+	}
 	return nil
 }
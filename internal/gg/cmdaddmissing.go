@@ -25,6 +25,14 @@ that are needed to build commands and tests in the working copy.
 This command does not guarantee that it will choose the correct version, so you
 should run this, check out the solution, and run your tests to verify the
 result.  You can fall back on manually adding the correct versions.
+
+This command respects the policies in gg.toml.  It does not add modules that a
+policy holds, and it adds the newest commit of the branch for modules that a
+policy has track a branch.  It reports every module that it skips.
+
+This command does not stop for packages that it cannot find a module for, or
+modules it cannot fetch.  It stages every module it can add and reports every
+such package and module at once.
 `
 
 func addMissingCommand() Command {
@@ -45,14 +53,11 @@ func addMissingCommand() Command {
 			state := driver.next
 
 			recommended := memo.Recommended
-			state, err = AddMissing(ctx, memo, driver.err, state, name, packages, recommended)
-			if err != nil {
-				return err
-			}
+			state, err = AddMissing(ctx, memo, driver.err, state, name, packages, recommended, memo.Policies)
 			driver.push(state)
 
 			ShowDiff(driver.out, driver.prev.Modules(), driver.next.Modules())
-			return nil
+			return err
 		},
 	}
 }
@@ -0,0 +1,190 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const apiDiffUsage UsageError = `Usage: gg api-diff <module>
+Example: gg read upgrade api-diff go.uber.org/zap
+
+Compares the exported Go API of the version of a module in the most recently
+read or marked solution with the version in the staged solution, reading the
+Go files of both versions from the cache.  Lists the exported functions, types,
+methods, fields, constants, and variables that each package added, removed, or
+changed, ignoring commands, tests, and internal packages.
+
+If semantic versioning deems the upgrade compatible, any removal is a
+violation, since it may break code that depends on the old version.  Changes
+deserve review, but may be compatible, like adding a variadic parameter.
+
+In the place of the module, the api-diff command accepts a version, tag,
+branch, or abbreviated commit hash to compare against the prior version.
+`
+
+func apiDiffCommand() Command {
+	return Command{
+		Names: []string{
+			"api-diff",
+		},
+		Usage:         apiDiffUsage,
+		SuggestModule: true,
+		Monadic: func(ctx context.Context, driver *Driver, name string) error {
+			module, err := driver.FindSolutionOrExpressModule(ctx, name, false)
+			if err != nil {
+				return err
+			}
+			prior, ok := driver.prev.Solution[module.Name]
+			if !ok {
+				return fmt.Errorf("no prior version of %s to compare, since it is not in the most recently read or marked solution", module.Name)
+			}
+			old, warnings, err := driver.memo.ReadGitAPI(ctx, driver.err, prior.Module)
+			if err != nil {
+				return fmt.Errorf("cannot read the API of %s: %s", prior.Module.Summary(), err)
+			}
+			new, newWarnings, err := driver.memo.ReadGitAPI(ctx, driver.err, module)
+			if err != nil {
+				return fmt.Errorf("cannot read the API of %s: %s", module.Summary(), err)
+			}
+			for _, warning := range append(warnings, newWarnings...) {
+				fmt.Fprintf(driver.err, "%s\n", warning)
+			}
+			changes := DiffAPI(old, new)
+			compatible := APICompatible(prior.Module, module)
+			if driver.format != FormatText {
+				if err := WriteReport(driver.out, driver.format, NewAPIDiffReport(prior.Module, module, changes)); err != nil {
+					return err
+				}
+			} else {
+				ShowAPIDiff(driver.out, prior.Module, module, changes)
+			}
+			if violations := countAPIViolations(changes, compatible); violations > 0 {
+				return fmt.Errorf("%s removes %d exported identifiers of %s, a compatible upgrade by semantic versioning", module.Summary(), violations, prior.Module.Summary())
+			}
+			return nil
+		},
+	}
+}
+
+// ShowAPIDiff writes the exported identifiers that each package added,
+// removed, or changed between two versions of a module.
+func ShowAPIDiff(out io.Writer, old, new Module, changes []APIChange) {
+	compatible := APICompatible(old, new)
+	fmt.Fprintf(out, "API differences from %s to %s:\n", old.Summary(), new.Summary())
+	if len(changes) == 0 {
+		fmt.Fprintf(out, "* No differences.\n")
+	}
+	pkg := ""
+	for _, change := range changes {
+		if change.Package != pkg {
+			pkg = change.Package
+			fmt.Fprintf(out, "\n")
+			fmt.Fprintf(out, "%s\n", pkg)
+		}
+		switch change.Change {
+		case APIAdded:
+			fmt.Fprintf(out, green+"+ %s"+clear+"\n", change.New)
+		case APIRemoved:
+			if change.IsViolation(compatible) {
+				fmt.Fprintf(out, red+"- %s (violation)"+clear+"\n", change.Old)
+			} else {
+				fmt.Fprintf(out, red+"- %s"+clear+"\n", change.Old)
+			}
+		case APIChanged:
+			fmt.Fprintf(out, gray+"- %s"+clear+"\n", change.Old)
+			fmt.Fprintf(out, yellow+"+ %s (changed)"+clear+"\n", change.New)
+		}
+	}
+}
+
+func countAPIViolations(changes []APIChange, compatible bool) int {
+	count := 0
+	for _, change := range changes {
+		if change.IsViolation(compatible) {
+			count++
+		}
+	}
+	return count
+}
+
+// APIDiffReport is the machine-readable schema for the api-diff report.
+type APIDiffReport struct {
+	Old        ModuleRecord      `json:"old"`
+	New        ModuleRecord      `json:"new"`
+	Compatible bool              `json:"compatible"`
+	Violations int               `json:"violations"`
+	Changes    []APIChangeRecord `json:"changes"`
+}
+
+// APIChangeRecord is an exported identifier of a package that was added,
+// removed, or changed, with its old and new declarations, either of which is
+// empty if absent.
+type APIChangeRecord struct {
+	Package   string `json:"package"`
+	Name      string `json:"name"`
+	Change    string `json:"change"`
+	Old       string `json:"old"`
+	New       string `json:"new"`
+	Violation bool   `json:"violation"`
+}
+
+// NewAPIDiffReport returns the machine-readable report of the API differences
+// between two versions of a module.
+func NewAPIDiffReport(old, new Module, changes []APIChange) APIDiffReport {
+	compatible := APICompatible(old, new)
+	report := APIDiffReport{
+		Old:        NewModuleRecord(old),
+		New:        NewModuleRecord(new),
+		Compatible: compatible,
+		Violations: countAPIViolations(changes, compatible),
+		Changes:    make([]APIChangeRecord, 0, len(changes)),
+	}
+	for _, change := range changes {
+		report.Changes = append(report.Changes, APIChangeRecord{
+			Package:   change.Package,
+			Name:      change.Name,
+			Change:    change.Change,
+			Old:       change.Old,
+			New:       change.New,
+			Violation: change.IsViolation(compatible),
+		})
+	}
+	return report
+}
+
+// Header returns the names of the columns of the TSV rendition.
+func (report APIDiffReport) Header() []string {
+	return []string{"package", "name", "change", "violation", "old", "new"}
+}
+
+// Rows returns a TSV row for every change.
+func (report APIDiffReport) Rows() [][]string {
+	rows := make([][]string, 0, len(report.Changes))
+	for _, change := range report.Changes {
+		rows = append(rows, []string{
+			change.Package,
+			change.Name,
+			change.Change,
+			strconv.FormatBool(change.Violation),
+			change.Old,
+			change.New,
+		})
+	}
+	return rows
+}
@@ -24,7 +24,8 @@ const changelogUsage UsageError = `Usage: gg changelog/cl <module>
 Example: changelog go.uber.org/fx@1.0
 
 Shows the changelog for the module in the solution, or the changelog for the
-specified version, tag, or branch.
+specified version, tag, or branch, or the changelog for the module with the
+given abbreviated commit hash.
 
 If the most recently read glide.lock has a different version of the same
 package, shows the differences between the proposed and prior versions' change
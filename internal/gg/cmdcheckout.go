@@ -14,7 +14,10 @@
 
 package gg
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 const checkoutUsage UsageError = `Usage: gg checkout/co
 Example: gg read-only checkout
@@ -23,6 +26,23 @@ Checks out the staged solution's vendor directory, replacing whatever was
 previously there.  This is typically a preamble to writing a new glide.lock
 with "gg write-only" or "gg wo" and is implied in the command "gg write" or
 "gg w".
+
+Checkout writes only the files of modules that changed since the previous
+checkout, and files that were modified on disk since, and removes the files of
+modules that are no longer in the solution and any other files in vendor.
+Unchanged files keep their modification times, so builds and editors need not
+revisit them.
+
+If there is a glide.lock, checkout records in it the digests of the vendor
+tree that install checks, for the modules that glide.lock locks at the commits
+checked out, and for the whole vendor directory if glide.lock locks exactly
+the staged solution.  Otherwise, checkout removes the digest of the whole
+vendor directory, which no longer matches.  Checkout does not otherwise change
+glide.lock.  The write and write-only commands write it anew.
+
+Checkout leaves out tests, unused packages, and non-Go files of the modules
+that the [prune] settings of gg.toml or Gopkg.toml prune.  See "gg help
+config".
 `
 
 func checkoutCommand() Command {
@@ -34,11 +54,35 @@ func checkoutCommand() Command {
 		Usage: checkoutUsage,
 		Read:  true,
 		Niladic: func(ctx context.Context, driver *Driver) error {
-			msg := "Checking out vendor"
-			driver.err.Start(msg)
-			err := Checkout(driver.err, driver.memo.GitDir, driver.next.Modules())
-			driver.err.Stop(msg)
-			return err
+			modules := driver.next.Modules()
+			pruner, err := driver.VendorPruner(ctx, modules)
+			if err != nil {
+				return err
+			}
+			if err := driverCheckout(driver, modules, pruner); err != nil {
+				return err
+			}
+
+			// The digests only spare install a checkout, so failing to
+			// record them is no reason to fail the checkout.
+			digested, vendor, err := DigestVendor(driver.memo.Repository, modules, pruner)
+			if err == nil {
+				err = RecordOwnVendorDigest(digested, vendor)
+			}
+			if err != nil {
+				fmt.Fprintf(driver.err, "Cannot record the vendor digest in glide.lock: %s\n", err)
+			}
+			return nil
 		},
 	}
 }
+
+// driverCheckout checks out the given modules to the vendor directory, without
+// touching glide.lock, as install requires.
+func driverCheckout(driver *Driver, modules Modules, pruner VendorPruner) error {
+	msg := "Checking out vendor"
+	driver.err.Start(msg)
+	err := Checkout(driver.err, driver.memo.GitDir, driver.memo.IndexFile, modules, pruner)
+	driver.err.Stop(msg)
+	return err
+}
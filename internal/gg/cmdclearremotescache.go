@@ -19,15 +19,17 @@ import "context"
 const clearRemotesCacheUsage UsageError = `Usage: gg clear-remotes-cache/crc
 Example: gg read clear-remotes-cache upgrade console
 
-When you run "gg read", "r", "read-only", or "ro", gg reads glide.lock and
-populates a memo of mappings from package name to package remote location,
-based on existing dependencies.  When entries are absent in this cache, gg will
+When you run "gg read" or "r", gg reads the lockfile it finds in the working
+copy, be it go.mod, Gopkg.lock, glide.lock, or another kind, and populates a
+memo of mappings from package name to package remote location, based on
+existing dependencies.  The "read-only" and "ro" commands do the same from
+glide.lock in particular.  When entries are absent in this cache, gg will
 send an HTTP request to the package's domain to look up remote aliases for
 vanity package domains like "gopkg.in".  These mappings are subject to change,
 especially if you are in the process of setting up your own.  Using
 "clear-remotes-cache" will void this cache.
 
-However, when using "gg" offline to reconstruct a glide.lock, it can be handy
+However, when using "gg" offline to reconstruct a lockfile, it can be handy
 to use "gg read" to populate the cache before running "gg new".
 
 See: gg help show-remotes-cache
@@ -14,14 +14,21 @@
 
 package gg
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
 
-const configUsage UsageError = `File: gg.toml
+const configUsage UsageError = `Usage: gg config
+File: gg.toml
 
-gg reads a gg.toml configuration file from the working directory or any parent
-directory.  The configuration file can supply package name patterns to look up
-remote locations, recommend versions for the add-missing modules workflow, and
-exclude directories from the package import graph analysis of the working copy.
+gg reads a gg.toml configuration file from the working directory and every
+parent directory.  The configuration file can supply package name patterns to look up
+remote locations, recommend versions for the add-missing modules workflow,
+exclude directories from the package import graph analysis of the working copy,
+choose the lockfiles to read, and locate the cache.
 
 For example, the following block directs gg to use a mirror for projects on
 Github.  Specifying the */* pattern helps gg infer that a longer package path
@@ -54,6 +61,25 @@ less than "2.0.0".
 	package = "git.apache.org/thrift"
 	version = "0.10"
 
+Policies limit how far the upgrade and add-missing workflows, and so init, may
+move modules with matching names.  The first matching policy applies.  The hold
+mode keeps a module at its current version, and add-missing will not add it.
+The patch mode allows only newer patch versions, minor allows newer versions
+with the same major version, even for version 0, and major allows any newer
+version.  The track-branch mode follows the newest commit of the named branch
+instead of any version.  Upgrade and add-missing report every module that a
+policy holds back.  Upgrade also passes over any upgrade whose lockfile would
+move another module against its hold, patch, or minor policy, and add-missing
+warns when a module it adds does so.
+
+	[[policy]]
+	pattern = "go.uber.org/thriftrw"
+	mode = "hold"
+
+	[[policy]]
+	pattern = "github.com/example/*"
+	mode = "track-branch develop"
+
 gg detects missing packages in the working copy by searching for packages
 imported by go files.  It skips directories like .git, .gg, and vendor.
 You can configure additional directories to exclude with the excludes section.
@@ -61,6 +87,17 @@ You can configure additional directories to exclude with the excludes section.
 	[[excludes]]
 	path = "go-build"
 
+Some versions of a dependency may be known to be broken.  The exclude-version
+section rules them out by version, reference, or commit hash prefix, for
+modules with matching names.  The upgrade and add-missing workflows pass over
+excluded versions, and gg refuses to add them by name.  If a dependency's
+lockfile demands an excluded version, the solver moves on to the next version
+that the excluded version could upgrade to, and reports that it did.
+
+	[[exclude-version]]
+	pattern = "go.uber.org/thriftrw"
+	versions = ["v1.10.0", "d8887717"]
+
 gg collects all of your project's dependencies in a local git repository called
 .gg as a cache, populating its refs/vendor namespace.  These references can be
 pushed to a remote repository.  gg will automatically fetch from this cache
@@ -68,6 +105,73 @@ before fetching from individual repositories if provided a cache repository URL
 in gg.toml.
 
 	cache = "https://example.com/my/cache"
+
+By default, the .gg cache belongs to the working copy.  The repository setting
+can instead direct gg to keep the refs/vendor references and objects in the
+project's own git repository, shared by all of its worktrees, or in a cache in
+the user's cache directory ($XDG_CACHE_HOME/gg or ~/.cache/gg), shared by all
+of the user's projects.  Either avoids fetching the same dependencies once per
+working copy.  Each worktree stages its vendor checkout in its own index file,
+and gg takes a lock on the cache while fetching, so several worktrees may use
+the same cache at once.
+
+	repository = "git"
+
+The read command looks for go.mod, Gopkg.lock, glide.lock, Gopkg.toml, and
+glide.yaml in the working copy, in that order, and the write command writes
+back the same kind.  The lockfiles setting changes the order of precedence, or
+limits read to fewer kinds of file.
+
+	lockfiles = ["glide.lock", "go.mod"]
+
+gg fetches up to 8 modules at once, though it reads one module at a time.  The
+concurrency setting changes how many.  A concurrency of 1 fetches one module at
+a time.
+
+	concurrency = 16
+
+The upgrade command considers only final releases, passing over pre-release
+tags like v1.2.0-rc.1.  The prereleases setting lets upgrades consider
+pre-releases of every module, or of a single package.  Pre-releases order
+before the final release of the same version, per SemVer 2.0.  The add-missing
+modules workflow prefers final releases regardless.
+
+	prereleases = true
+
+	[[packages]]
+	package = "github.com/example/next"
+	prereleases = true
+
+Checkout writes every file of every module to the vendor directory.  The prune
+section leaves out _test.go files and testdata directories with go-tests,
+packages that the commands and tests of the working copy do not import,
+transitively, with unused-packages, and files that the go tool does not build
+with non-go.  Pruning always keeps licences and other legal notices.  The
+prune-module section overrides the settings it sets for modules with matching
+names, and the first matching section applies.  Absent these settings for a
+module, the prune section of Gopkg.toml in the working copy applies, including
+its project sections, as for dep.  This is not to be confused with the prune
+command, which removes modules.
+
+	[prune]
+	go-tests = true
+	unused-packages = true
+	non-go = true
+
+	[[prune-module]]
+	pattern = "github.com/gogo/protobuf"
+	non-go = false
+
+gg merges every gg.toml from the working directory up to the root directory, so
+a team can keep its own settings without losing those of the organization.
+Nearer files take precedence.  Their remote, policy, and prune-module patterns
+match before those of farther files, which remain in effect.  Their recommended
+versions override those for the same package.  Their cache, repository,
+lockfiles, concurrency, prereleases, and prune settings shadow those of farther
+files.  Excludes and excluded versions accumulate from every file.
+
+The config command shows the effective configuration and which gg.toml each
+setting came from.
 `
 
 func configCommand() Command {
@@ -77,7 +181,106 @@ func configCommand() Command {
 		},
 		Usage: configUsage,
 		Niladic: func(ctx context.Context, driver *Driver) error {
-			return driver.ExecuteArguments(ctx, "help", "config")
+			ShowConfig(driver.out, driver.memo.Config)
+			return nil
 		},
 	}
 }
+
+// ShowConfig writes the effective configuration in the form of a gg.toml,
+// commenting each setting with the gg.toml it came from.
+func ShowConfig(out io.Writer, config *Config) {
+	if len(config.Files) == 0 {
+		fmt.Fprintf(out, "# There is no gg.toml in the working directory or any parent directory.\n")
+		return
+	}
+	fmt.Fprintf(out, "# Merged from, nearest first:\n")
+	for _, file := range config.Files {
+		fmt.Fprintf(out, "#   %s\n", file)
+	}
+
+	if config.Cache != "" || config.Repository != "" || len(config.Lockfiles) > 0 || config.Concurrency != 0 || config.Prereleases != nil {
+		fmt.Fprintf(out, "\n")
+	}
+	if config.Cache != "" {
+		fmt.Fprintf(out, "cache = %q # %s\n", config.Cache, config.Sources["cache"])
+	}
+	if config.Repository != "" {
+		fmt.Fprintf(out, "repository = %q # %s\n", config.Repository, config.Sources["repository"])
+	}
+	if len(config.Lockfiles) > 0 {
+		quoted := make([]string, 0, len(config.Lockfiles))
+		for _, lockfile := range config.Lockfiles {
+			quoted = append(quoted, fmt.Sprintf("%q", lockfile))
+		}
+		fmt.Fprintf(out, "lockfiles = [%s] # %s\n", strings.Join(quoted, ", "), config.Sources["lockfiles"])
+	}
+	if config.Concurrency != 0 {
+		fmt.Fprintf(out, "concurrency = %d # %s\n", config.Concurrency, config.Sources["concurrency"])
+	}
+	if config.Prereleases != nil {
+		fmt.Fprintf(out, "prereleases = %t # %s\n", *config.Prereleases, config.Sources["prereleases"])
+	}
+	if config.Prune != (ConfigPrune{}) {
+		fmt.Fprintf(out, "\n[prune]\n")
+	}
+	if config.Prune.GoTests != nil {
+		fmt.Fprintf(out, "go-tests = %t # %s\n", *config.Prune.GoTests, config.Sources["prune.go-tests"])
+	}
+	if config.Prune.UnusedPackages != nil {
+		fmt.Fprintf(out, "unused-packages = %t # %s\n", *config.Prune.UnusedPackages, config.Sources["prune.unused-packages"])
+	}
+	if config.Prune.NonGo != nil {
+		fmt.Fprintf(out, "non-go = %t # %s\n", *config.Prune.NonGo, config.Sources["prune.non-go"])
+	}
+
+	for _, remote := range config.Remotes {
+		fmt.Fprintf(out, "\n[[remotes]] # %s\n", remote.Source)
+		fmt.Fprintf(out, "pattern = %q\n", remote.Pattern)
+		fmt.Fprintf(out, "remote = %q\n", remote.Remote)
+		if remote.GitoliteMirror {
+			fmt.Fprintf(out, "gitoliteMirror = true\n")
+		}
+	}
+	for _, policy := range config.Policies {
+		fmt.Fprintf(out, "\n[[policy]] # %s\n", policy.Source)
+		fmt.Fprintf(out, "pattern = %q\n", policy.Pattern)
+		fmt.Fprintf(out, "mode = %q\n", policy.Mode)
+	}
+	for _, recommend := range config.Packages {
+		fmt.Fprintf(out, "\n[[packages]] # %s\n", recommend.Source)
+		fmt.Fprintf(out, "package = %q\n", recommend.Package)
+		if recommend.Version != "" {
+			fmt.Fprintf(out, "version = %q\n", recommend.Version)
+		}
+		if recommend.Prereleases {
+			fmt.Fprintf(out, "prereleases = true\n")
+		}
+	}
+	for _, exclude := range config.Excludes {
+		fmt.Fprintf(out, "\n[[excludes]] # %s\n", exclude.Source)
+		fmt.Fprintf(out, "path = %q\n", exclude.Path)
+	}
+	for _, exclude := range config.ExcludeVersions {
+		quoted := make([]string, 0, len(exclude.Versions))
+		for _, version := range exclude.Versions {
+			quoted = append(quoted, fmt.Sprintf("%q", version))
+		}
+		fmt.Fprintf(out, "\n[[exclude-version]] # %s\n", exclude.Source)
+		fmt.Fprintf(out, "pattern = %q\n", exclude.Pattern)
+		fmt.Fprintf(out, "versions = [%s]\n", strings.Join(quoted, ", "))
+	}
+	for _, prune := range config.PruneModules {
+		fmt.Fprintf(out, "\n[[prune-module]] # %s\n", prune.Source)
+		fmt.Fprintf(out, "pattern = %q\n", prune.Pattern)
+		if prune.GoTests != nil {
+			fmt.Fprintf(out, "go-tests = %t\n", *prune.GoTests)
+		}
+		if prune.UnusedPackages != nil {
+			fmt.Fprintf(out, "unused-packages = %t\n", *prune.UnusedPackages)
+		}
+		if prune.NonGo != nil {
+			fmt.Fprintf(out, "non-go = %t\n", *prune.NonGo)
+		}
+	}
+}
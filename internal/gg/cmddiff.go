@@ -38,33 +38,110 @@ func showDiffCommand() Command {
 		},
 		Usage: showDiffUsage,
 		Niladic: func(ctx context.Context, driver *Driver) error {
+			if driver.format != FormatText {
+				return WriteReport(driver.out, driver.format, NewDiffReport(driver.prev.Modules(), driver.next.Modules()))
+			}
 			ShowDiff(driver.out, driver.prev.Modules(), driver.next.Modules())
 			return nil
 		},
 	}
 }
 
-// ShowDiff writes a colorized report of what modules have been added, removed,
-// or changed revisions between two sets of modules.
-func ShowDiff(out io.Writer, before Modules, after Modules) {
-	fmt.Fprintf(out, "Differences:\n")
+// ModuleChange is a module that has been added, removed, or changed between
+// two sets of modules.
+type ModuleChange struct {
+	Name      string
+	Before    Module
+	After     Module
+	HadBefore bool
+	HasAfter  bool
+}
+
+// DiffModules returns the modules that have been added, removed, or changed
+// revisions between two sets of modules, ordered by name.
+func DiffModules(before Modules, after Modules) []ModuleChange {
 	b := before.Index()
 	a := after.Index()
 	names := before.Names()
 	names.Include(after.Names())
-	diff := false
+	var changes []ModuleChange
 	for _, name := range names.Keys() {
 		if !a[name].Equal(b[name]) {
-			diff = true
-			if module, ok := b[name]; ok {
-				fmt.Fprintf(out, "\x1b[31m- %s\x1b[0m\n", module)
-			}
-			if module, ok := a[name]; ok {
-				fmt.Fprintf(out, "\x1b[32m+ %s\x1b[0m\n", module)
-			}
+			prior, hadPrior := b[name]
+			next, hasNext := a[name]
+			changes = append(changes, ModuleChange{
+				Name:      name,
+				Before:    prior,
+				After:     next,
+				HadBefore: hadPrior,
+				HasAfter:  hasNext,
+			})
 		}
 	}
-	if !diff {
+	return changes
+}
+
+// ShowDiff writes a colorized report of what modules have been added, removed,
+// or changed revisions between two sets of modules.
+func ShowDiff(out io.Writer, before Modules, after Modules) {
+	fmt.Fprintf(out, "Differences:\n")
+	changes := DiffModules(before, after)
+	for _, change := range changes {
+		if change.HadBefore {
+			fmt.Fprintf(out, "\x1b[31m- %s\x1b[0m\n", change.Before)
+		}
+		if change.HasAfter {
+			fmt.Fprintf(out, "\x1b[32m+ %s\x1b[0m\n", change.After)
+		}
+	}
+	if len(changes) == 0 {
 		fmt.Fprintf(out, "* No differences.\n")
 	}
 }
+
+// DiffReport is the machine-readable schema for the diff report.
+type DiffReport struct {
+	Changes []ChangeRecord `json:"changes"`
+}
+
+// ChangeRecord is a module that has been added, where Before is null, removed,
+// where After is null, or changed.
+type ChangeRecord struct {
+	Name   string        `json:"name"`
+	Before *ModuleRecord `json:"before"`
+	After  *ModuleRecord `json:"after"`
+}
+
+// NewDiffReport returns the machine-readable report of the differences
+// between two sets of modules.
+func NewDiffReport(before Modules, after Modules) DiffReport {
+	report := DiffReport{
+		Changes: make([]ChangeRecord, 0),
+	}
+	for _, change := range DiffModules(before, after) {
+		report.Changes = append(report.Changes, ChangeRecord{
+			Name:   change.Name,
+			Before: newOptionalModuleRecord(change.Before, change.HadBefore),
+			After:  newOptionalModuleRecord(change.After, change.HasAfter),
+		})
+	}
+	return report
+}
+
+// Header returns the names of the columns of the TSV rendition.
+func (report DiffReport) Header() []string {
+	header := []string{"name"}
+	header = append(header, moduleHeader("before_")...)
+	return append(header, moduleHeader("after_")...)
+}
+
+// Rows returns a TSV row for every module that changed.
+func (report DiffReport) Rows() [][]string {
+	rows := make([][]string, 0, len(report.Changes))
+	for _, change := range report.Changes {
+		row := []string{change.Name}
+		row = append(row, change.Before.Cells()...)
+		rows = append(rows, append(row, change.After.Cells()...))
+	}
+	return rows
+}
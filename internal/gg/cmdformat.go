@@ -0,0 +1,55 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import "context"
+
+const formatUsage UsageError = `Usage: gg format/fmt <text|json|tsv>
+Example: gg format json show-solution
+Example: gg fmt tsv show-versions go.uber.org/fx
+
+Sets the output format of the reports that follow, for the rest of the
+session.  The "text" format is for humans.  The "json" and "tsv" formats are
+stable, machine-readable renditions of the show-solution, show-conflicts,
+show-module, show-versions, show-missing-packages, diff, and metrics reports.
+
+The JSON rendition of a report is a single object.  The TSV rendition has a
+header row that names each column, followed by a row for each record.  Tabs,
+newlines, and backslashes in values are escaped with backslashes.  Modules in
+TSV rows have fewer columns than their JSON counterparts: name, hash, version,
+ref, time, test, and remote.  Times are in RFC 3339 format, in UTC.
+
+A sole command after the format implies the same workflow as it would alone,
+so "gg format json ss" reads the lockfile before showing the solution.
+`
+
+func formatCommand() Command {
+	return Command{
+		Names: []string{
+			"format",
+			"fmt",
+		},
+		Usage:  formatUsage,
+		Prefix: true,
+		Monadic: func(ctx context.Context, driver *Driver, arg string) error {
+			format, err := ParseFormat(arg)
+			if err != nil {
+				return err
+			}
+			driver.format = format
+			return nil
+		},
+	}
+}
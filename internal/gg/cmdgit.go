@@ -40,7 +40,7 @@ func gitCommand() Command {
 				return err
 			}
 			cmd := exec.Command("git", args...)
-			cmd.Env = GitEnv(driver.memo.GitDir)
+			cmd.Env = GitIndexEnv(driver.memo.GitDir, driver.memo.IndexFile)
 			cmd.Stdin = driver.in
 			cmd.Stdout = driver.out
 			cmd.Stderr = driver.err
@@ -36,7 +36,8 @@ const glidelockUsage UsageError = `Usage: gg glidelock/gl <module>
 Example: glidelock go.uber.org/fx@1.0
 
 Shows the glide.lock for the module in the solution, or the glide.lock for the
-specified version, tag, or branch.
+specified version, tag, or branch, or the glide.lock for the module with the
+given abbreviated commit hash.
 
 If the most recently read glide.lock has a different version of the same
 package, shows the differences between the proposed and prior versions'
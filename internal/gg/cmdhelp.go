@@ -38,17 +38,25 @@ Files:
   rgl/read-glide-lock        wgl/write-glide-lock
   rdl/read-dep-lock          wdl/write-dep-lock
   rdt/read-dep-toml          wdt/write-dep-toml
+  rml/read-mod-lock          wml/write-mod-lock
   gl/glidelock <module>      dl/deplock <module>
   cl/changelog <module>      co/checkout
+  log <module>               rn/release-notes
+  api-diff <module>
 Observe:
-  diff
+  diff                       config
   ss/show-solution           sc/show-conflicts
   sm/show-module <module>    si/show-imports <package>
   sv/show-versions <module>  trace <package>
+  outdated                   outdated-by <name|age>
   smp/show-missing-packages  sxm/show-extra-modules
   sop/show-own-packages      sss/show-shallow-solution
+  sg/show-graph              spg/show-package-graph <prefix>
+  why <module>               verify
 Orient:
   new  mark  reset  back  fore  off/offline  on/online  quiet
+  fmt/format <text|json|tsv>
+  save <name>  load <name>
 Cache:
   push  pull  fetch  src/show-remotes-cache  crc/clear-remotes-cache
 Decide: (you are here)
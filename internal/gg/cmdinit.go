@@ -22,10 +22,17 @@ Example: gg init
 Reads all of the packages in the working copy and creates a new glide.lock and
 vendor solution by choosing the newest version or master branch of any module
 that provides a package imported by the working copy.
+If the working copy already has another kind of lockfile, like go.mod, init
+writes that kind instead, as the write command does.
+Like add-missing, init respects the policies in gg.toml and reports the modules
+that they skip.
 
 This does not use a perfect heuristic.  You may need to run tests to verify the
 solution, look for conflicts in the solution, and possibly add the correct
 versions manually.
+
+If init cannot find a module for some packages, or cannot fetch some modules,
+it reports all of them and does not write a lockfile or vendor.
 `
 
 func initCommand() Command {
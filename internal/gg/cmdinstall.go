@@ -14,7 +14,12 @@
 
 package gg
 
-import "context"
+import (
+	"context"
+	"fmt"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
 
 const installUsage UsageError = `Usage: gg install/i
 Checks out the vendor tree as described in glide.lock exactly.
@@ -22,6 +27,13 @@ Checks out the vendor tree as described in glide.lock exactly.
 Install does not ensure that the solution described in the glide.lock is
 internally consistent.  To fix an inconsistent glide.lock, run "gg read
 checkout write", which will run the constraint solver.
+
+If the glide.lock records the digest of its vendor tree, install checks out
+the vendor tree only if the vendor directory diverges from the digest, and
+reports the modules that diverge.  Install refuses a glide.lock that records a
+vendor tree other than the one the locked commits in the cache produce, since
+checkout could not reproduce it.  Unlike checkout, install never changes
+glide.lock.
 `
 
 func installCommand() Command {
@@ -33,7 +45,71 @@ func installCommand() Command {
 		Usage: installUsage,
 		Read:  true,
 		Niladic: func(ctx context.Context, driver *Driver) error {
-			return driver.ExecuteArguments(ctx, "read-only", "checkout")
+			if err := driver.ExecuteArguments(ctx, "read-only"); err != nil {
+				return err
+			}
+			lock, err := ReadOwnGlideLock()
+			if err != nil || lock.Vendor == "" {
+				modules := driver.next.Modules()
+				pruner, err := driver.VendorPruner(ctx, modules)
+				if err != nil {
+					return err
+				}
+				return driverCheckout(driver, modules, pruner)
+			}
+			return driverInstallVendor(ctx, driver, plumbing.NewHash(lock.Vendor))
 		},
 	}
 }
+
+// driverInstallVendor checks out the staged solution if the vendor directory
+// diverges from the given digest of the vendor tree in glide.lock.
+func driverInstallVendor(ctx context.Context, driver *Driver, want plumbing.Hash) error {
+	modules := driver.next.Modules()
+	pruner, err := driver.VendorPruner(ctx, modules)
+	if err != nil {
+		return fmt.Errorf("cannot install glide.lock: %s", err)
+	}
+	digested, digest, err := DigestVendor(driver.memo.Repository, modules, pruner)
+	if err != nil {
+		return fmt.Errorf("cannot install glide.lock: %s", err)
+	}
+	for i, module := range modules {
+		if module.Tree != NoHash && module.Tree != digested[i].Tree {
+			return fmt.Errorf("refusing to install glide.lock, which records tree %s for %s, but its commit has tree %s", module.Tree, module.Summary(), digested[i].Tree)
+		}
+	}
+	if digest != want {
+		return fmt.Errorf("refusing to install glide.lock, which records vendor tree %s, but its commits have vendor tree %s", want, digest)
+	}
+
+	if got, err := ReadVendorDigest("vendor"); err == nil && got == want {
+		fmt.Fprintf(driver.err, "Vendor matches glide.lock.\n")
+		return nil
+	}
+	var diverged []VendorVerification
+	for _, verification := range VerifyVendor(driver.memo.Repository, "vendor", digested, pruner) {
+		if !verification.OK() {
+			diverged = append(diverged, verification)
+		}
+	}
+	if len(diverged) > 0 {
+		fmt.Fprintf(driver.err, "Vendor diverges from glide.lock:\n")
+		ShowVerify(driver.err, diverged)
+	} else {
+		fmt.Fprintf(driver.err, "Vendor has files that belong to no module in glide.lock:\n")
+		ShowVerifyDigest(driver.err, VerifyVendorDigest(driver.memo.Repository, "vendor", digested, pruner))
+	}
+
+	if err := driverCheckout(driver, modules, pruner); err != nil {
+		return err
+	}
+	got, err := ReadVendorDigest("vendor")
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return fmt.Errorf("vendor has tree %s after checkout instead of %s from glide.lock", got, want)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const logUsage UsageError = `Usage: gg log <module>
+Example: gg read upgrade log go.uber.org/fx
+
+Shows the commits between the version of a module in the most recently read or
+marked solution and the version in the staged solution, with the subject line,
+author, and date of each commit, flagging merges and reverts.  This works for
+every module, even those that do not have a CHANGELOG.md, and reads only from
+the cache.
+
+If the new version is older than the old version, as in a downgrade, log shows
+the commits that the downgrade undoes.  If the new version does not descend
+from the old version otherwise, because someone rewrote the branch or the old
+version was a stray commit, log says so and also shows the commits of the old
+version that the new version lacks.
+
+In the place of the module, the log command accepts a version, tag, branch, or
+abbreviated commit hash to compare against the prior version.
+`
+
+func logCommand() Command {
+	return Command{
+		Names: []string{
+			"log",
+		},
+		Usage:         logUsage,
+		SuggestModule: true,
+		Monadic: func(ctx context.Context, driver *Driver, name string) error {
+			module, err := driver.FindSolutionOrExpressModule(ctx, name, false)
+			if err != nil {
+				return err
+			}
+			prior, ok := driver.prev.Solution[module.Name]
+			if !ok {
+				return fmt.Errorf("no prior version of %s to compare, since it is not in the most recently read or marked solution", module.Name)
+			}
+			log, err := driver.memo.ReadGitLog(prior.Module.Hash, module.Hash)
+			if err != nil {
+				return fmt.Errorf("cannot read the log of %s from %s to %s: %s", module.Name, prior.Module.Summary(), module.Summary(), err)
+			}
+			if driver.format != FormatText {
+				return WriteReport(driver.out, driver.format, NewLogReport(prior.Module, module, log))
+			}
+			ShowLog(driver.out, prior.Module, module, log)
+			return nil
+		},
+	}
+}
+
+// ShowLog writes the commits between an old and new version of a module.
+func ShowLog(out io.Writer, old, new Module, log GitLog) {
+	if log.Old == log.New {
+		fmt.Fprintf(out, "No changes to %s.\n", new.Summary())
+		return
+	}
+	fmt.Fprintf(out, "Commits from %s to %s:\n", old.Summary(), new.Summary())
+	if len(log.Commits) == 0 {
+		fmt.Fprintf(out, "* No commits.\n")
+	}
+	showLogCommits(out, log.Commits)
+	if divergence, warning := log.Divergence(old.Summary(), new.Summary()); divergence != "" {
+		fmt.Fprintf(out, "\n")
+		if warning {
+			fmt.Fprintf(out, yellow+"Warning: %s"+clear+"\n", divergence)
+		} else {
+			fmt.Fprintf(out, "%s\n", divergence)
+		}
+		if len(log.Dropped) > 0 {
+			fmt.Fprintf(out, "Commits of %s that %s lacks:\n", old.Summary(), new.Summary())
+			showLogCommits(out, log.Dropped)
+		}
+	}
+}
+
+func showLogCommits(out io.Writer, commits []LogCommit) {
+	for _, commit := range commits {
+		flags := ""
+		if commit.Merge {
+			flags += gray + "(merge) " + clear
+		}
+		if commit.Revert {
+			flags += yellow + "(revert) " + clear
+		}
+		fmt.Fprintf(out, "%s %s %s%s "+gray+"(%s)"+clear+"\n", commit.Hash.String()[:8], commit.Time.Format("2006-01-02"), flags, commit.Subject, commit.Author)
+	}
+}
+
+// LogReport is the machine-readable schema for the log report.
+type LogReport struct {
+	Old        ModuleRecord   `json:"old"`
+	New        ModuleRecord   `json:"new"`
+	Descendant bool           `json:"descendant"`
+	Ancestor   bool           `json:"ancestor"`
+	Commits    []CommitRecord `json:"commits"`
+	Dropped    []CommitRecord `json:"dropped"`
+}
+
+// CommitRecord is the machine-readable schema for a commit in a log.
+type CommitRecord struct {
+	Hash    string `json:"hash"`
+	Author  string `json:"author"`
+	Email   string `json:"email"`
+	Time    string `json:"time"`
+	Subject string `json:"subject"`
+	Merge   bool   `json:"merge"`
+	Revert  bool   `json:"revert"`
+}
+
+// NewLogReport returns the machine-readable report of the commits between an
+// old and new version of a module.
+func NewLogReport(old, new Module, log GitLog) LogReport {
+	return LogReport{
+		Old:        NewModuleRecord(old),
+		New:        NewModuleRecord(new),
+		Descendant: log.Descendant,
+		Ancestor:   log.Ancestor,
+		Commits:    newCommitRecords(log.Commits),
+		Dropped:    newCommitRecords(log.Dropped),
+	}
+}
+
+func newCommitRecords(commits []LogCommit) []CommitRecord {
+	records := make([]CommitRecord, 0, len(commits))
+	for _, commit := range commits {
+		records = append(records, CommitRecord{
+			Hash:    commit.Hash.String(),
+			Author:  commit.Author,
+			Email:   commit.Email,
+			Time:    commit.Time.UTC().Format(time.RFC3339),
+			Subject: commit.Subject,
+			Merge:   commit.Merge,
+			Revert:  commit.Revert,
+		})
+	}
+	return records
+}
+
+// Header returns the names of the columns of the TSV rendition.
+func (report LogReport) Header() []string {
+	return []string{"relation", "hash", "time", "author", "email", "merge", "revert", "subject"}
+}
+
+// Rows returns a TSV row for every commit of the new version, then every
+// commit of the old version that the new version lacks.
+func (report LogReport) Rows() [][]string {
+	rows := make([][]string, 0, len(report.Commits)+len(report.Dropped))
+	for _, relation := range []struct {
+		name    string
+		records []CommitRecord
+	}{
+		{"commit", report.Commits},
+		{"dropped", report.Dropped},
+	} {
+		for _, record := range relation.records {
+			rows = append(rows, []string{
+				relation.name,
+				record.Hash,
+				record.Time,
+				record.Author,
+				record.Email,
+				strconv.FormatBool(record.Merge),
+				strconv.FormatBool(record.Revert),
+				record.Subject,
+			})
+		}
+	}
+	return rows
+}
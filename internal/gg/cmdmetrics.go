@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strconv"
 	"time"
 )
 
@@ -33,6 +34,9 @@ func metricsCommand() Command {
 		},
 		Usage: metricsUsage,
 		Niladic: func(ctx context.Context, driver *Driver) error {
+			if driver.format != FormatText {
+				return WriteReport(driver.out, driver.format, NewMetricsReport(driver.memo))
+			}
 			ShowMetrics(driver.out, driver.memo)
 			return nil
 		},
@@ -79,3 +83,77 @@ func ShowMetrics(out io.Writer, memo *Memo) {
 	fmt.Fprintf(out, "PrimedRemotes: %d\n", memo.PrimedRemotes)
 	fmt.Fprintf(out, "TotalRemotes: %d\n", len(memo.Remotes))
 }
+
+// MetricsReport is the machine-readable schema for the metrics report.
+// Durations are in nanoseconds.
+type MetricsReport struct {
+	GitFetchCalls            int           `json:"gitFetchCalls"`
+	GitFetchDuration         time.Duration `json:"gitFetchDuration"`
+	RemoteForPackageCalls    int           `json:"remoteForPackageCalls"`
+	RemoteForPackageDuration time.Duration `json:"remoteForPackageDuration"`
+	GitCommitMemoHits        int           `json:"gitCommitMemoHits"`
+	GitResolveCommitCalls    int           `json:"gitResolveCommitCalls"`
+	GitResolveCommitDuration time.Duration `json:"gitResolveCommitDuration"`
+	GitDigestRefsCalls       int           `json:"gitDigestRefsCalls"`
+	GitDigestRefsDuration    time.Duration `json:"gitDigestRefsDuration"`
+	ReadOwnModulesCalls      int           `json:"readOwnModulesCalls"`
+	ReadOwnModulesDuration   time.Duration `json:"readOwnModulesDuration"`
+	ReadGitPackagesCalls     int           `json:"readGitPackagesCalls"`
+	ReadGitPackagesDuration  time.Duration `json:"readGitPackagesDuration"`
+	References               int           `json:"references"`
+	PrimedRemotes            int           `json:"primedRemotes"`
+	TotalRemotes             int           `json:"totalRemotes"`
+}
+
+// NewMetricsReport returns the machine-readable report of the metrics
+// gathered by the module loader.
+func NewMetricsReport(memo *Memo) MetricsReport {
+	return MetricsReport{
+		GitFetchCalls:            memo.GitFetchCalls,
+		GitFetchDuration:         memo.GitFetchDuration,
+		RemoteForPackageCalls:    memo.RemoteForPackageCalls,
+		RemoteForPackageDuration: memo.RemoteForPackageDuration,
+		GitCommitMemoHits:        memo.GitCommitMemoHits,
+		GitResolveCommitCalls:    memo.GitResolveCommitCalls,
+		GitResolveCommitDuration: memo.GitResolveCommitDuration,
+		GitDigestRefsCalls:       memo.GitDigestRefsCalls,
+		GitDigestRefsDuration:    memo.GitDigestRefsDuration,
+		ReadOwnModulesCalls:      memo.ReadOwnModulesCalls,
+		ReadOwnModulesDuration:   memo.ReadOwnModulesDuration,
+		ReadGitPackagesCalls:     memo.ReadGitPackagesCalls,
+		ReadGitPackagesDuration:  memo.ReadGitPackagesDuration,
+		References:               len(memo.Refs),
+		PrimedRemotes:            memo.PrimedRemotes,
+		TotalRemotes:             len(memo.Remotes),
+	}
+}
+
+// Header returns the names of the columns of the TSV rendition.
+func (report MetricsReport) Header() []string {
+	return []string{"metric", "value"}
+}
+
+// Rows returns a TSV row for every metric, named as in the JSON rendition.
+func (report MetricsReport) Rows() [][]string {
+	metric := func(name string, value int64) []string {
+		return []string{name, strconv.FormatInt(value, 10)}
+	}
+	return [][]string{
+		metric("gitFetchCalls", int64(report.GitFetchCalls)),
+		metric("gitFetchDuration", int64(report.GitFetchDuration)),
+		metric("remoteForPackageCalls", int64(report.RemoteForPackageCalls)),
+		metric("remoteForPackageDuration", int64(report.RemoteForPackageDuration)),
+		metric("gitCommitMemoHits", int64(report.GitCommitMemoHits)),
+		metric("gitResolveCommitCalls", int64(report.GitResolveCommitCalls)),
+		metric("gitResolveCommitDuration", int64(report.GitResolveCommitDuration)),
+		metric("gitDigestRefsCalls", int64(report.GitDigestRefsCalls)),
+		metric("gitDigestRefsDuration", int64(report.GitDigestRefsDuration)),
+		metric("readOwnModulesCalls", int64(report.ReadOwnModulesCalls)),
+		metric("readOwnModulesDuration", int64(report.ReadOwnModulesDuration)),
+		metric("readGitPackagesCalls", int64(report.ReadGitPackagesCalls)),
+		metric("readGitPackagesDuration", int64(report.ReadGitPackagesDuration)),
+		metric("references", int64(report.References)),
+		metric("primedRemotes", int64(report.PrimedRemotes)),
+		metric("totalRemotes", int64(report.TotalRemotes)),
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const outdatedUsage UsageError = `Usage: gg outdated
+Usage: gg outdated-by <name|age>
+Example: gg read outdated
+Example: gg format tsv outdated-by age
+
+Fetches every module in the staged solution and reports, for each, the current
+version, the newest version within its semantic version range, which the
+upgrade workflow would choose, and the newest version overall, including major
+versions with breaking changes, and versions that a policy in gg.toml holds
+back.  Also reports the age of the current version in
+days, by the time of its commit.
+
+The outdated command orders modules by name.  The outdated-by command orders
+them by name, or by age from the oldest current version to the newest.
+`
+
+func outdatedCommand() Command {
+	return Command{
+		Names: []string{
+			"outdated",
+		},
+		Usage: outdatedUsage,
+		Read:  true,
+		Niladic: func(ctx context.Context, driver *Driver) error {
+			return driverShowOutdated(ctx, driver, OutdatedByName)
+		},
+	}
+}
+
+func outdatedByCommand() Command {
+	return Command{
+		Names: []string{
+			"outdated-by",
+		},
+		Usage: outdatedUsage,
+		Read:  true,
+		Monadic: func(ctx context.Context, driver *Driver, by string) error {
+			return driverShowOutdated(ctx, driver, by)
+		},
+	}
+}
+
+func driverShowOutdated(ctx context.Context, driver *Driver, by string) error {
+	now := time.Now()
+	outdated, err := FindOutdated(ctx, driver.memo, driver.err, driver.next.Modules(), driver.memo.Policies, driver.memo.Prereleases, driver.memo.Exclusions)
+	if err != nil {
+		return err
+	}
+	if err := SortOutdated(outdated, by, now); err != nil {
+		return err
+	}
+	if driver.format != FormatText {
+		return WriteReport(driver.out, driver.format, NewOutdatedReport(outdated, now))
+	}
+	return ShowOutdated(driver.out, outdated, now)
+}
+
+// ShowOutdated writes a table of the current, compatible, and latest versions
+// of modules, with a dash in the place of a version that is not newer.
+func ShowOutdated(out io.Writer, outdated []Outdated, now time.Time) error {
+	if len(outdated) == 0 {
+		fmt.Fprintf(out, "* No modules.\n")
+		return nil
+	}
+	table := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(table, "Module\tCurrent\tCompatible\tLatest\tAge\n")
+	for _, entry := range outdated {
+		compatible := "-"
+		if entry.CanUpgrade() {
+			compatible = moduleLabel(entry.Compatible)
+		}
+		latest := "-"
+		if entry.HasNewer() {
+			latest = moduleLabel(entry.Latest)
+		}
+		age := "?"
+		if days := entry.Age(now); days >= 0 {
+			age = strconv.Itoa(days)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", entry.Current.Name, moduleLabel(entry.Current), compatible, latest, age)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	for _, entry := range outdated {
+		if entry.Error != nil {
+			fmt.Fprintf(out, red+"* %s"+clear+"\n", entry.Error)
+		}
+	}
+	return nil
+}
+
+// OutdatedReport is the machine-readable schema for the outdated report.
+type OutdatedReport struct {
+	Modules []OutdatedRecord `json:"modules"`
+}
+
+// OutdatedRecord is the current version of a module, the newest version
+// within its semantic version range, the newest version overall, and the age
+// of the current version in days, which is -1 if unknown.
+type OutdatedRecord struct {
+	Current      ModuleRecord `json:"current"`
+	Compatible   ModuleRecord `json:"compatible"`
+	Latest       ModuleRecord `json:"latest"`
+	AgeDays      int          `json:"ageDays"`
+	Upgrade      bool         `json:"upgrade"`
+	MajorUpgrade bool         `json:"majorUpgrade"`
+	Error        string       `json:"error"`
+}
+
+// NewOutdatedReport returns the machine-readable outdated report.
+func NewOutdatedReport(outdated []Outdated, now time.Time) OutdatedReport {
+	report := OutdatedReport{
+		Modules: make([]OutdatedRecord, 0, len(outdated)),
+	}
+	for _, entry := range outdated {
+		record := OutdatedRecord{
+			Current:      NewModuleRecord(entry.Current),
+			Compatible:   NewModuleRecord(entry.Compatible),
+			Latest:       NewModuleRecord(entry.Latest),
+			AgeDays:      entry.Age(now),
+			Upgrade:      entry.CanUpgrade(),
+			MajorUpgrade: entry.CanUpgradeMajor(),
+		}
+		if entry.Error != nil {
+			record.Error = entry.Error.Error()
+		}
+		report.Modules = append(report.Modules, record)
+	}
+	return report
+}
+
+// Header returns the names of the columns of the TSV rendition.
+func (report OutdatedReport) Header() []string {
+	header := []string{"age_days", "upgrade", "major_upgrade", "error"}
+	header = append(header, moduleHeader("current_")...)
+	header = append(header, moduleHeader("compatible_")...)
+	return append(header, moduleHeader("latest_")...)
+}
+
+// Rows returns a TSV row for every module.
+func (report OutdatedReport) Rows() [][]string {
+	rows := make([][]string, 0, len(report.Modules))
+	for _, record := range report.Modules {
+		row := []string{
+			strconv.Itoa(record.AgeDays),
+			strconv.FormatBool(record.Upgrade),
+			strconv.FormatBool(record.MajorUpgrade),
+			record.Error,
+		}
+		row = append(row, record.Current.Cells()...)
+		row = append(row, record.Compatible.Cells()...)
+		rows = append(rows, append(row, record.Latest.Cells()...))
+	}
+	return rows
+}
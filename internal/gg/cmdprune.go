@@ -56,17 +56,19 @@ func pruneCommand() Command {
 				return err
 			}
 			extraModules := ExtraModules(packages, modules.Packages(), state.Modules())
+			var errs error
 			for _, module := range extraModules {
 				next, err := state.Remove(ctx, driver.memo, driver.err, module.Name)
-				if err != nil {
-					return err
+				errs = AppendUniqueError(errs, err)
+				if ctx.Err() != nil {
+					return errs
 				}
 				state = next
 			}
 			driver.push(state)
 
 			ShowDiff(driver.out, driver.prev.Modules(), driver.next.Modules())
-			return nil
+			return errs
 		},
 	}
 }
@@ -14,13 +14,25 @@
 
 package gg
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"strings"
+)
 
 const readUsage UsageError = `Usage: gg read/r
 Example: gg read show-solution
 
-Reads a glide.lock onto the stage and solves its dependency graph, collecting
+Reads a lockfile onto the stage and solves its dependency graph, collecting
 unmentioned transitive dependencies and choosing the highest version mentioned.
+
+Read looks for the first of go.mod, Gopkg.lock, glide.lock, Gopkg.toml, and
+glide.yaml in the working copy, and reads it with the corresponding command,
+like read-mod-lock.  The lockfiles setting in gg.toml can change the order of
+precedence.  See "gg help config".
+
+Read records which kind of file it read, so the write command writes back the
+same kind.
 `
 
 func readCommand() Command {
@@ -31,7 +43,15 @@ func readCommand() Command {
 		},
 		Usage: readUsage,
 		Niladic: func(ctx context.Context, driver *Driver) error {
-			return driver.ExecuteArguments(ctx, "read-glide-lock", "solve")
+			lockfile, ok := FindOwnLockfile(driver.memo.Lockfiles)
+			if !ok {
+				names := make([]string, 0, len(driver.memo.Lockfiles))
+				for _, lockfile := range driver.memo.Lockfiles {
+					names = append(names, lockfile.Name)
+				}
+				return fmt.Errorf("cannot find any of %s in the working copy", strings.Join(names, ", "))
+			}
+			return driver.ExecuteArguments(ctx, lockfile.Read, "solve")
 		},
 	}
 }
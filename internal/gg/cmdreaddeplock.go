@@ -67,6 +67,7 @@ func readDepLockCommand() Command {
 
 			driver.prev = state
 			driver.push(state)
+			driver.lockfile = "Gopkg.lock"
 			return nil
 		},
 	}
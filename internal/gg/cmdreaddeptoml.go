@@ -27,9 +27,14 @@ const readDepManifestUsage UsageError = `Usage: gg read-dep-toml/read-gopkg-toml
 Reads the constraints and overrides in a dep Gopkg.toml and adds them to the
 solution.
 
-gg does not distinguish constraints and overrides, since it uses the
-max-of-min-timestamps algorithm to settle conflicts among transitive
-dependencies.
+Constraints settle conflicts among transitive dependencies with the
+max-of-min-timestamps algorithm, like any other module gg adds.
+Overrides apply after all constraints and pin the module to the given version
+even if it is older than the version the solution would otherwise settle upon.
+To honor the pin, gg walks back every module that demands a newer version to
+an older version.
+If that is not possible, gg reports which modules block the override and
+leaves the solution as it was.
 `
 
 func readDepManifestCommand() Command {
@@ -49,17 +54,25 @@ func readDepManifestCommand() Command {
 				return fmt.Errorf("unable to read Gopkg.toml: %s", err)
 			}
 
-			add := func(imp DepManifestConstraint) {
+			add := func(imp DepManifestConstraint, override bool) {
 				if found, ok := findDepManifestConstraint(ctx, driver.err, driver.memo, imp); ok {
-					msg := fmt.Sprintf("Adding %s.", found.Summary())
-					driver.err.Start(msg)
-					next, err := state.Add(ctx, driver.memo, driver.err, found)
-					driver.err.Stop(msg)
-					if err != nil {
-						fmt.Fprintf(driver.err, "Failed to add %s.\n", found.Summary())
+					var next *State
+					var err error
+					if override {
+						msg := fmt.Sprintf("Overriding %s.", found.Summary())
+						driver.err.Start(msg)
+						next, err = state.Override(ctx, driver.memo, driver.err, found)
+						driver.err.Stop(msg)
 					} else {
-						state = next
+						msg := fmt.Sprintf("Adding %s.", found.Summary())
+						driver.err.Start(msg)
+						next, err = state.Add(ctx, driver.memo, driver.err, found)
+						driver.err.Stop(msg)
+					}
+					if err != nil {
+						fmt.Fprintf(driver.err, "Failed to add %s: %s\n", found.Summary(), err)
 					}
+					state = next
 					ShowDiff(driver.err, prev.Modules(), state.Modules())
 					prev = state
 				} else {
@@ -67,14 +80,15 @@ func readDepManifestCommand() Command {
 				}
 			}
 
-			for _, imp := range manifest.Overrides {
-				add(imp)
-			}
 			for _, imp := range manifest.Constraints {
-				add(imp)
+				add(imp, false)
+			}
+			for _, imp := range manifest.Overrides {
+				add(imp, true)
 			}
 
 			driver.next = state
+			driver.lockfile = "Gopkg.toml"
 			return nil
 		},
 	}
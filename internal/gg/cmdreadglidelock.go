@@ -73,6 +73,7 @@ func readOnlyCommand() Command {
 
 			driver.prev = state
 			driver.push(state)
+			driver.lockfile = "glide.lock"
 			return nil
 		},
 	}
@@ -80,10 +80,9 @@ func readGlideManifestCommand() Command {
 					next, err := state.Add(ctx, driver.memo, driver.err, found)
 					driver.err.Stop(msg)
 					if err != nil {
-						fmt.Fprintf(driver.err, "Failed to add %s\n", found.Summary())
-					} else {
-						state = next
+						fmt.Fprintf(driver.err, "Failed to add %s: %s\n", found.Summary(), err)
 					}
+					state = next
 					ShowDiff(driver.err, prev.Modules(), state.Modules())
 					prev = state
 				} else {
@@ -109,6 +108,7 @@ func readGlideManifestCommand() Command {
 			}
 
 			driver.next = state
+			driver.lockfile = "glide.yaml"
 			return nil
 		},
 	}
@@ -0,0 +1,161 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+const readModLockUsage UsageError = `Usage: gg read-mod-lock/read-go-mod/rml
+Example: gg rml w
+
+Reads go.mod and go.sum, replacing the staged solution.
+
+A go.mod does not capture commit hashes, so gg fetches every required module
+and finds the tag for its version, or the commit for the abbreviated hash in its
+pseudo-version.
+Modules that go.sum mentions but go.mod does not are read at the newest version
+in go.sum, since older go.mod files only list direct dependencies.
+
+The replace directive changes the remote and version of a module, but gg cannot
+vendor a module replaced with a local directory and skips it with a warning.
+The exclude directive causes gg to skip to the next newer version that is not
+excluded and has the same major version.
+
+This does not however automatically run the constraint solver.
+Follow-up with a "solve" command to ensure that the dependencies read are
+complete and consistent.
+`
+
+func readModLockCommand() Command {
+	return Command{
+		Names: []string{
+			"read-mod-lock",
+			"read-go-mod",
+			"rml",
+		},
+		Usage: readModLockUsage,
+		Niladic: func(ctx context.Context, driver *Driver) error {
+			memo := driver.memo
+			state := NewState()
+
+			msg := "Reading go.mod"
+			driver.err.Start(msg)
+			lock, err := ReadOwnModLock()
+			if err != nil {
+				driver.err.Stop(msg)
+				return err
+			}
+			sums, err := ReadOwnModSum()
+			driver.err.Stop(msg)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+
+			modules, err := memo.ModulesFromModLock(ctx, driver.err, lock, sums)
+			if err != nil {
+				return err
+			}
+			if err := memo.FinishModules(ctx, driver.err, modules); err != nil {
+				return err
+			}
+
+			next, err := state.Constrain(ctx, memo, driver.err, modules, false)
+			if err != nil {
+				return err
+			}
+			state = next
+
+			driver.prev = state
+			driver.push(state)
+			driver.lockfile = "go.mod"
+			return nil
+		},
+	}
+}
+
+// ModulesFromModLock resolves the requirements of our own go.mod and go.sum
+// into modules, applying the replace and exclude directives.
+// Modules that cannot be resolved are reported and skipped.
+func (memo *Memo) ModulesFromModLock(ctx context.Context, out ProgressWriter, lock *ModLock, sums []ModSum) (Modules, error) {
+	versions := lock.Requirements(sums)
+	modules := make(Modules, 0, len(versions))
+	for _, version := range versions {
+		select {
+		case <-ctx.Done():
+			return modules, ctx.Err()
+		default:
+		}
+
+		source := version
+		if replace, ok := lock.Replacement(version); ok {
+			if replace.New.Version == "" {
+				fmt.Fprintf(out, "Skipping %s because it is replaced with local directory %s.\n", version.Path, replace.New.Path)
+				continue
+			}
+			source = replace.New
+		}
+
+		module := moduleFromModVersion(source)
+		module.Name = version.Path
+		if source.Path != version.Path {
+			remote := Module{Name: source.Path}
+			if err := memo.FinishRemote(ctx, out, &remote); err != nil {
+				return nil, err
+			}
+			memo.Remotes[version.Path] = remote.Remote
+		}
+
+		if err := memo.ResolveModVersion(ctx, out, &module, source); err != nil {
+			fmt.Fprintf(out, "Cannot read go.mod requirement: %s\n", err)
+			continue
+		}
+
+		if lock.Excluded(version) {
+			found, err := memo.nextModVersion(ctx, out, lock, module)
+			if err != nil {
+				fmt.Fprintf(out, "Cannot read go.mod requirement: %s\n", err)
+				continue
+			}
+			fmt.Fprintf(out, "Version %s of %s is excluded. Using %s instead.\n", version.Version, version.Path, found.Summary())
+			module = found
+		}
+
+		modules = append(modules, module)
+	}
+	return modules, nil
+}
+
+// nextModVersion finds the next newer version of a module that has the same
+// major version and is not excluded by the go.mod, just as the go command
+// would.
+func (memo *Memo) nextModVersion(ctx context.Context, out ProgressWriter, lock *ModLock, module Module) (Module, error) {
+	versions, err := memo.ReadVersions(ctx, out, module)
+	if err != nil {
+		return module, err
+	}
+	for _, version := range versions {
+		if !module.Version.Before(version.Version) || module.Version[0] != version.Version[0] {
+			continue
+		}
+		if lock.Excluded(ModVersion{Path: module.Name, Version: "v" + version.Version.String()}) {
+			continue
+		}
+		return version, nil
+	}
+	return module, fmt.Errorf("every version of %s after %s is excluded", module.Name, module.Version)
+}
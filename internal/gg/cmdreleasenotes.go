@@ -0,0 +1,46 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import "context"
+
+const releaseNotesUsage UsageError = `Usage: gg release-notes/rn
+Example: gg read upgrade release-notes
+
+Writes Markdown release notes for every module that changed between the most
+recently read or marked solution and the staged solution, the same modules
+that diff shows, suitable for the description of a pull request.
+
+For each module that changed from one version to another, the release notes
+include every section of the new version's CHANGELOG.md for versions after the
+old version, up to and including the new version.  For modules without a
+changelog or a section for the new versions, the release notes include the
+commit log between the old and new versions instead, as the log command shows.
+`
+
+func releaseNotesCommand() Command {
+	return Command{
+		Names: []string{
+			"release-notes",
+			"rn",
+		},
+		Usage: releaseNotesUsage,
+		Niladic: func(ctx context.Context, driver *Driver) error {
+			changes := DiffModules(driver.prev.Modules(), driver.next.Modules())
+			WriteReleaseNotes(driver.out, ReadReleaseNotes(driver.memo, changes))
+			return nil
+		},
+	}
+}
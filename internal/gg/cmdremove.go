@@ -47,13 +47,13 @@ func removeCommand() Command {
 			driver.err.Start(msg)
 			next, err := state.Remove(ctx, driver.memo, driver.err, name)
 			driver.err.Stop(msg)
-			if err != nil {
-				return fmt.Errorf("unable to remove module %s: %s", name, err)
-			}
 			state = next
 			driver.push(state)
 
 			ShowDiff(driver.out, driver.prev.Modules(), driver.next.Modules())
+			if err != nil {
+				return fmt.Errorf("unable to remove module %s: %s", name, err)
+			}
 			return nil
 		},
 	}
@@ -0,0 +1,74 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import "context"
+
+const saveUsage UsageError = `Usage: gg save <name>
+Example: gg read upgrade save upgrade-investigation
+
+Saves the staged solution, the marked solution that diff compares against, and
+the history and future for back and fore, to .gg/sessions/<name>.json.  The
+load command resumes the session, in this working copy or in a teammate's.
+`
+
+const loadUsage UsageError = `Usage: gg load <name>
+Example: gg load upgrade-investigation diff
+
+Loads a session saved with "gg save <name>" from .gg/sessions/<name>.json,
+replacing the staged solution, the marked solution, and the history and future
+for back and fore.  Loading reads every module of the session from the cache,
+fetching any that the cache lacks.
+`
+
+func saveCommand() Command {
+	return Command{
+		Names: []string{
+			"save",
+		},
+		Usage: saveUsage,
+		Monadic: func(ctx context.Context, driver *Driver, name string) error {
+			session := NewSession(driver.prev, driver.next, driver.history, driver.future)
+			return WriteSession(name, session)
+		},
+	}
+}
+
+func loadCommand() Command {
+	return Command{
+		Names: []string{
+			"load",
+		},
+		Usage: loadUsage,
+		Monadic: func(ctx context.Context, driver *Driver, name string) error {
+			session, err := ReadSession(name)
+			if err != nil {
+				return err
+			}
+			out := driver.err
+			out.Start("Loading session")
+			defer out.Stop("Loading session")
+			prev, next, history, future, err := session.Restore(ctx, driver.memo, out)
+			if err != nil {
+				return err
+			}
+			driver.prev = prev
+			driver.next = next
+			driver.history = history
+			driver.future = future
+			return nil
+		},
+	}
+}
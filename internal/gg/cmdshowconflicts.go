@@ -66,29 +66,38 @@ func showConflictsCommand() Command {
 		Usage: showConflictsUsage,
 		Read:  true,
 		Niladic: func(ctx context.Context, driver *Driver) error {
+			if driver.format != FormatText {
+				return WriteReport(driver.out, driver.format, NewConflictsReport(driver.next.Modules()))
+			}
 			ShowConflicts(driver.out, driver.next.Modules())
 			return nil
 		},
 	}
 }
 
-// ShowConflicts writes a report about semantic version conflicts that have
-// emerged in a dependency solution, where some modules depend on
-// non-overlapping semantic version ranges of the same package.
-func ShowConflicts(out io.Writer, modules Modules) {
-	fmt.Fprintf(out, "Conflicts:\n")
-	conflicts := false
+// Conflict is a dependency of one module on another that is missing from a
+// solution, or on a version that may be incompatible with the version in the
+// solution.
+// The Got module is absent if Missing.
+type Conflict struct {
+	Dependency
+	Missing bool
+}
+
+// FindConflicts returns every missing dependency, followed by every potential
+// version conflict, among the modules of a dependency solution.
+func FindConflicts(modules Modules) []Conflict {
+	var conflicts []Conflict
 	index := Modules(modules).Index()
 	// Missing
 	for _, module := range modules {
 		for _, desired := range module.Modules {
 			_, ok := index[desired.Name]
 			if !ok {
-				fmt.Fprintf(out, "* Found a missing dependency.\n")
-				fmt.Fprintf(out, "  %s depends on %s\n", module.Name, desired.Name)
-				fmt.Fprintf(out, "  FROM %s\n", module)
-				fmt.Fprintf(out, "  LACK %s\n", desired)
-				conflicts = true
+				conflicts = append(conflicts, Conflict{
+					Dependency: Dependency{Module: module, Want: desired},
+					Missing:    true,
+				})
 			}
 		}
 	}
@@ -97,16 +106,93 @@ func ShowConflicts(out io.Writer, modules Modules) {
 		for _, desired := range module.Modules {
 			required, ok := index[desired.Name]
 			if ok && desired.Ref != "" && desired.Version != NoVersion && desired.Hash != required.Hash && !desired.CanUpgradeTo(required) {
-				fmt.Fprintf(out, "* Found a potential version conflict.\n")
-				fmt.Fprintf(out, "  %s is locked to a version of %s that may not be compatible with the completed solution, based on their versions.\n", module.Name, desired.Name)
-				fmt.Fprintf(out, "  from %s\n", module)
-				fmt.Fprintf(out, "  want %s\n", desired)
-				fmt.Fprintf(out, "  got  %s\n", required)
-				conflicts = true
+				conflicts = append(conflicts, Conflict{
+					Dependency: Dependency{Module: module, Want: desired, Got: required},
+				})
 			}
 		}
 	}
-	if !conflicts {
+	return conflicts
+}
+
+// ShowConflicts writes a report about semantic version conflicts that have
+// emerged in a dependency solution, where some modules depend on
+// non-overlapping semantic version ranges of the same package.
+func ShowConflicts(out io.Writer, modules Modules) {
+	fmt.Fprintf(out, "Conflicts:\n")
+	conflicts := FindConflicts(modules)
+	for _, conflict := range conflicts {
+		if conflict.Missing {
+			fmt.Fprintf(out, "* Found a missing dependency.\n")
+			fmt.Fprintf(out, "  %s depends on %s\n", conflict.Module.Name, conflict.Want.Name)
+			fmt.Fprintf(out, "  FROM %s\n", conflict.Module)
+			fmt.Fprintf(out, "  LACK %s\n", conflict.Want)
+		} else {
+			fmt.Fprintf(out, "* Found a potential version conflict.\n")
+			fmt.Fprintf(out, "  %s is locked to a version of %s that may not be compatible with the completed solution, based on their versions.\n", conflict.Module.Name, conflict.Want.Name)
+			fmt.Fprintf(out, "  from %s\n", conflict.Module)
+			fmt.Fprintf(out, "  want %s\n", conflict.Want)
+			fmt.Fprintf(out, "  got  %s\n", conflict.Got)
+		}
+	}
+	if len(conflicts) == 0 {
 		fmt.Fprintf(out, "* No conflicts.\n")
 	}
 }
+
+// ConflictsReport is the machine-readable schema for the show-conflicts
+// report.
+type ConflictsReport struct {
+	Conflicts []ConflictRecord `json:"conflicts"`
+}
+
+// ConflictRecord is a conflict of kind "missing", where the Got module is
+// null, or "version".
+type ConflictRecord struct {
+	Kind   string        `json:"kind"`
+	Module ModuleRecord  `json:"module"`
+	Want   ModuleRecord  `json:"want"`
+	Got    *ModuleRecord `json:"got"`
+}
+
+// NewConflictsReport returns the machine-readable report of conflicts among
+// the modules of a dependency solution.
+func NewConflictsReport(modules Modules) ConflictsReport {
+	conflicts := FindConflicts(modules)
+	report := ConflictsReport{
+		Conflicts: make([]ConflictRecord, 0, len(conflicts)),
+	}
+	for _, conflict := range conflicts {
+		kind := "version"
+		if conflict.Missing {
+			kind = "missing"
+		}
+		report.Conflicts = append(report.Conflicts, ConflictRecord{
+			Kind:   kind,
+			Module: NewModuleRecord(conflict.Module),
+			Want:   NewModuleRecord(conflict.Want),
+			Got:    newOptionalModuleRecord(conflict.Got, !conflict.Missing),
+		})
+	}
+	return report
+}
+
+// Header returns the names of the columns of the TSV rendition.
+func (report ConflictsReport) Header() []string {
+	header := []string{"kind"}
+	header = append(header, moduleHeader("module_")...)
+	header = append(header, moduleHeader("want_")...)
+	return append(header, moduleHeader("got_")...)
+}
+
+// Rows returns a TSV row for every conflict.
+func (report ConflictsReport) Rows() [][]string {
+	rows := make([][]string, 0, len(report.Conflicts))
+	for _, conflict := range report.Conflicts {
+		row := []string{conflict.Kind}
+		row = append(row, conflict.Module.Cells()...)
+		row = append(row, conflict.Want.Cells()...)
+		rows = append(rows, append(row, conflict.Got.Cells()...))
+	}
+	return rows
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import "context"
+
+const showGraphUsage UsageError = `Usage: gg show-graph/sg
+Example: gg show-graph | dot -Tsvg > solution.svg
+
+Writes the graph of the modules in the solution and which modules depend on
+which, in the Graphviz DOT language.  Each node shows the version, reference,
+or abbreviated hash of a module.  Modules needed only for tests are dashed and
+gray.  Dependencies on modules missing from the solution, or on versions that
+may be incompatible with the version in the solution, are red, as reported by
+show-conflicts.
+`
+
+const showPackageGraphUsage UsageError = `Usage: gg show-package-graph/spg <prefix>
+Example: gg read-only show-package-graph go.uber.org/ | dot -Tsvg > fx.svg
+
+Writes the import graph among packages of the working copy and the solution
+whose names begin with the given prefix, in the Graphviz DOT language.
+Commands are ellipses and other packages are boxes.
+`
+
+func showGraphCommand() Command {
+	return Command{
+		Names: []string{
+			"show-graph",
+			"sg",
+		},
+		Usage: showGraphUsage,
+		Read:  true,
+		Niladic: func(ctx context.Context, driver *Driver) error {
+			modules := driver.next.Modules()
+			if err := driver.memo.FinishModules(ctx, driver.err, modules); err != nil {
+				return err
+			}
+			WriteModuleGraph(driver.out, driver.next, modules)
+			return nil
+		},
+	}
+}
+
+func showPackageGraphCommand() Command {
+	return Command{
+		Names: []string{
+			"show-package-graph",
+			"spg",
+		},
+		Usage:          showPackageGraphUsage,
+		Read:           true,
+		SuggestPackage: true,
+		Monadic: func(ctx context.Context, driver *Driver, prefix string) error {
+			WritePackageGraph(driver.out, driver.Packages(ctx), prefix)
+			return nil
+		},
+	}
+}
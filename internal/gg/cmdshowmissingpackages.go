@@ -58,6 +58,9 @@ func showMissingPackagesCommand() Command {
 			if err != nil {
 				return err
 			}
+			if driver.format != FormatText {
+				return WriteReport(driver.out, driver.format, NewMissingPackagesReport(driver.next, packages))
+			}
 			ShowMissingPackages(driver.out, driver.next, packages)
 			return nil
 		},
@@ -88,3 +91,38 @@ func ShowMissingPackages(out io.Writer, state *State, packages Packages) {
 		}
 	}
 }
+
+// MissingPackagesReport is the machine-readable schema for the
+// show-missing-packages report.
+type MissingPackagesReport struct {
+	Imports     []string `json:"imports"`
+	TestImports []string `json:"testImports"`
+}
+
+// NewMissingPackagesReport returns the machine-readable report of packages
+// missing from a solution.
+func NewMissingPackagesReport(state *State, packages Packages) MissingPackagesReport {
+	imports, testImports := MissingPackages(packages, state.Modules().Packages())
+	return MissingPackagesReport{
+		Imports:     imports.Keys(),
+		TestImports: testImports.Keys(),
+	}
+}
+
+// Header returns the names of the columns of the TSV rendition.
+func (report MissingPackagesReport) Header() []string {
+	return []string{"package", "test"}
+}
+
+// Rows returns a TSV row for every missing package, first those missing for
+// builds, then those missing only for tests.
+func (report MissingPackagesReport) Rows() [][]string {
+	rows := make([][]string, 0, len(report.Imports)+len(report.TestImports))
+	for _, pkg := range report.Imports {
+		rows = append(rows, []string{pkg, "false"})
+	}
+	for _, pkg := range report.TestImports {
+		rows = append(rows, []string{pkg, "true"})
+	}
+	return rows
+}
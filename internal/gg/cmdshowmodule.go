@@ -34,12 +34,17 @@ import (
 
 const showModuleUsage UsageError = `Usage: gg show-module/sm <module>
 Example: gg show-module go.uber.org/fx
+Example: gg show-module 3fe1cac1
 
 Shows the dependencies described in the given module's lock file.  Also shows
 the version of that module in the staged dependency solution if it is different
 from the exact version in the lock file.  Many modules do not have a lock file.
 
 Shows any warning encountered when analyzing that module.
+
+In the place of the module, an abbreviated commit hash finds the module with
+that commit in the solution, or else among the versions of every module in the
+cache.  If the hash is ambiguous, gg lists the modules it matches.
 `
 
 func showModuleCommand() Command {
@@ -60,6 +65,9 @@ func showModuleCommand() Command {
 				fmt.Fprintf(driver.err, "Failed to normalize the requirements of %s: %s\n", module.Summary(), err)
 			}
 			state := driver.next
+			if driver.format != FormatText {
+				return WriteReport(driver.out, driver.format, NewModuleReport(state, module))
+			}
 			ShowModule(driver.out, state, module)
 			return nil
 		},
@@ -82,6 +90,9 @@ func ShowModule(out io.Writer, state *State, module Module) {
 	if module.Deplock != NoHash {
 		fmt.Fprintf(out, "Gopkg.lock: %s\n", module.Deplock)
 	}
+	if module.Modlock != NoHash {
+		fmt.Fprintf(out, "go.mod:     %s\n", module.Modlock)
+	}
 	if module.GitoliteMirror {
 		created := ""
 		if module.GitoliteMirrorCreated {
@@ -104,12 +115,11 @@ func ShowModule(out io.Writer, state *State, module Module) {
 	}
 	for _, want := range module.Modules {
 		solution, ok := state.Solution[want.Name]
-		got := solution.Module
 		if !ok {
 			fmt.Fprintf(out, "\n")
 			fmt.Fprintf(out, red+"-   %s "+red+"(missing)"+clear+"\n", want)
 		} else {
-			showDependency(out, module, solution.Module, got)
+			showDependency(out, module, want, solution.Module)
 		}
 	}
 	fmt.Fprintf(out, "\n")
@@ -130,16 +140,119 @@ func showDependency(out io.Writer, module, want, got Module) {
 }
 
 func diffModule(out io.Writer, want, got Module) {
-	if got.Hash != want.Hash {
+	switch DependencyChange(want, got) {
+	case "conflict":
 		fmt.Fprintf(out, gray+"- %s"+clear+"\n", want)
-		if !want.CanUpgradeTo(got) {
-			fmt.Fprintf(out, yellow+"+ %s "+yellow+"(conflict)"+clear+"\n", got)
-		} else if want.Before(got) {
-			fmt.Fprintf(out, green+"+ %s "+green+"(upgrade)"+clear+"\n", got)
-		} else {
-			fmt.Fprintf(out, red+"+ %s "+red+"(downgrade)"+clear+"\n", got)
-		}
-	} else {
+		fmt.Fprintf(out, yellow+"+ %s "+yellow+"(conflict)"+clear+"\n", got)
+	case "upgrade":
+		fmt.Fprintf(out, gray+"- %s"+clear+"\n", want)
+		fmt.Fprintf(out, green+"+ %s "+green+"(upgrade)"+clear+"\n", got)
+	case "downgrade":
+		fmt.Fprintf(out, gray+"- %s"+clear+"\n", want)
+		fmt.Fprintf(out, red+"+ %s "+red+"(downgrade)"+clear+"\n", got)
+	default:
 		fmt.Fprintf(out, "= %s (same)\n", got)
 	}
 }
+
+// DependencyChange describes how the version of a module in a solution
+// differs from the version a dependee wants: "same", "upgrade", "downgrade",
+// or "conflict" if the wanted version cannot upgrade to the solution's.
+func DependencyChange(want, got Module) string {
+	if got.Hash == want.Hash {
+		return "same"
+	}
+	if !want.CanUpgradeTo(got) {
+		return "conflict"
+	}
+	if want.Before(got) {
+		return "upgrade"
+	}
+	return "downgrade"
+}
+
+// ModuleReport is the machine-readable schema for the show-module report.
+type ModuleReport struct {
+	Module       ModuleRecord       `json:"module"`
+	Dependencies []DependencyRecord `json:"dependencies"`
+	Dependees    []DependencyRecord `json:"dependees"`
+}
+
+// DependencyRecord is the version of a module that a dependee wants, the
+// version in the solution, and the change between them, which is "missing"
+// if the solution has no version, and the Got module is null.
+type DependencyRecord struct {
+	Dependee string        `json:"dependee"`
+	Want     ModuleRecord  `json:"want"`
+	Got      *ModuleRecord `json:"got"`
+	Change   string        `json:"change"`
+}
+
+// NewModuleReport returns the machine-readable report of a module, its
+// dependencies, and its dependees in a solution.
+func NewModuleReport(state *State, module Module) ModuleReport {
+	report := ModuleReport{
+		Module:       NewModuleRecord(module),
+		Dependencies: make([]DependencyRecord, 0, len(module.Modules)),
+	}
+	for _, want := range module.Modules {
+		solution, ok := state.Solution[want.Name]
+		change := "missing"
+		if ok {
+			change = DependencyChange(want, solution.Module)
+		}
+		report.Dependencies = append(report.Dependencies, DependencyRecord{
+			Dependee: module.Summary(),
+			Want:     NewModuleRecord(want),
+			Got:      newOptionalModuleRecord(solution.Module, ok),
+			Change:   change,
+		})
+	}
+	report.Dependees = newDependencyRecords(state.Modules().FilterDependencies(module))
+	return report
+}
+
+// newDependencyRecords returns the machine-readable records of what
+// dependees want of a module and what they got.
+func newDependencyRecords(dependencies []Dependency) []DependencyRecord {
+	records := make([]DependencyRecord, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		records = append(records, DependencyRecord{
+			Dependee: dependency.Module.Summary(),
+			Want:     NewModuleRecord(dependency.Want),
+			Got:      newOptionalModuleRecord(dependency.Got, true),
+			Change:   DependencyChange(dependency.Want, dependency.Got),
+		})
+	}
+	return records
+}
+
+// Header returns the names of the columns of the TSV rendition.
+func (report ModuleReport) Header() []string {
+	header := []string{"relation", "dependee", "change"}
+	header = append(header, moduleHeader("want_")...)
+	return append(header, moduleHeader("got_")...)
+}
+
+// Rows returns a TSV row for the module itself, with only the got columns,
+// then a row for every dependency and every dependee.
+func (report ModuleReport) Rows() [][]string {
+	rows := make([][]string, 0, 1+len(report.Dependencies)+len(report.Dependees))
+	row := []string{"module", "", ""}
+	row = append(row, (*ModuleRecord)(nil).Cells()...)
+	rows = append(rows, append(row, report.Module.Cells()...))
+	for _, relation := range []struct {
+		name    string
+		records []DependencyRecord
+	}{
+		{"dependency", report.Dependencies},
+		{"dependee", report.Dependees},
+	} {
+		for _, record := range relation.records {
+			row := []string{relation.name, record.Dependee, record.Change}
+			row = append(row, record.Want.Cells()...)
+			rows = append(rows, append(row, record.Got.Cells()...))
+		}
+	}
+	return rows
+}
@@ -30,6 +30,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strconv"
 )
 
 const showSolutionUsage UsageError = `Usage: gg show-solution/ss
@@ -45,6 +46,7 @@ const solutionLegend = `
   T: needed for tests only.
   G: has a glide.lock.
   D: has a Gopkg.lock (dep)
+  M: has a go.mod
   C: has a CHANGELOG.md.
 `
 
@@ -61,6 +63,9 @@ func showSolutionCommand() Command {
 			if err := driver.memo.FinishModules(ctx, driver.err, modules); err != nil {
 				return err
 			}
+			if driver.format != FormatText {
+				return WriteReport(driver.out, driver.format, NewSolutionReport(driver.next, modules))
+			}
 			showSolution(driver.out, driver.next, modules)
 			return nil
 		},
@@ -94,3 +99,51 @@ func showSolution(out io.Writer, state *State, modules Modules) {
 	fmt.Fprintf(out, "%s", solutionLegend[1:])
 	fmt.Fprintf(out, "%s", recommend)
 }
+
+// SolutionReport is the machine-readable schema for the show-solution report.
+type SolutionReport struct {
+	Locked   []SolutionRecord `json:"locked"`
+	Unlocked []ModuleRecord   `json:"unlocked"`
+}
+
+// SolutionRecord is a module locked in the solution, and whether some module
+// in the solution wants an incompatible version of it.
+type SolutionRecord struct {
+	ModuleRecord
+	Conflict bool `json:"conflict"`
+}
+
+// NewSolutionReport returns the machine-readable report of the modules in a
+// solution.
+func NewSolutionReport(state *State, modules Modules) SolutionReport {
+	report := SolutionReport{
+		Locked:   make([]SolutionRecord, 0, len(state.Solution)),
+		Unlocked: NewModuleRecords(state.Frontier),
+	}
+	for _, name := range state.Solution.Names() {
+		module := state.Solution[name].Module
+		report.Locked = append(report.Locked, SolutionRecord{
+			ModuleRecord: NewModuleRecord(module),
+			Conflict:     modules.Conflicts(module),
+		})
+	}
+	return report
+}
+
+// Header returns the names of the columns of the TSV rendition.
+func (report SolutionReport) Header() []string {
+	return append([]string{"locked", "conflict"}, moduleHeader("")...)
+}
+
+// Rows returns a TSV row for every locked module, then every unlocked module.
+func (report SolutionReport) Rows() [][]string {
+	rows := make([][]string, 0, len(report.Locked)+len(report.Unlocked))
+	for i := range report.Locked {
+		record := report.Locked[i]
+		rows = append(rows, append([]string{"true", strconv.FormatBool(record.Conflict)}, record.Cells()...))
+	}
+	for i := range report.Unlocked {
+		rows = append(rows, append([]string{"false", "false"}, report.Unlocked[i].Cells()...))
+	}
+	return rows
+}
@@ -54,6 +54,9 @@ func showVersionsCommand() Command {
 			if err != nil {
 				return fmt.Errorf("unable to read latest versions of %s: %s", name, err)
 			}
+			if driver.format != FormatText {
+				return WriteReport(driver.out, driver.format, ModulesReport{Modules: NewModuleRecords(modules)})
+			}
 			fmt.Fprintf(driver.out, "Versions:\n")
 			if len(modules) == 0 {
 				fmt.Fprintf(driver.out, "* No versions.\n")
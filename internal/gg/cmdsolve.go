@@ -24,6 +24,9 @@ finds a solution that takes the highest of all specified modules.  This may
 involve discovering and fetching previously unknown dependencies, digesting all
 of their references and building a model of package imports.
 
+Solve does not stop for modules that it cannot fetch or read.
+It stages the partial solution and reports every such module at once.
+
 If executed as the only argument at the command line, this will read, solve,
 then write back.
 `
@@ -44,13 +47,8 @@ func solveCommand() Command {
 			driver.err.Start(msg)
 			next, err := state.Solve(ctx, memo, driver.err)
 			driver.err.Stop(msg)
-			if err != nil {
-				return err
-			}
-			state = next
-
-			driver.push(state)
-			return nil
+			driver.push(next)
+			return err
 		},
 	}
 }
@@ -60,11 +60,19 @@ Otherwise, especially for modules written by glide.lock, we do not know the
 original reference, so we assume that the git reference was "heads/master" and
 upgrade accordingly.
 
+Policies in gg.toml can hold a module at its current version, limit it to
+newer patch versions or versions with the same major version, allow breaking
+changes, or follow a branch.  Upgrade reports every module that a policy holds
+back.  See "gg help config".
+
 gg uses the dependency solver to add any new modules or ugprade transitive
 dependencies, by the rules defined by the solver.  The solver prefers the
 higher semantic version.  In the absence of a semantic version, it uses the
 module with the newer git commit timestamp.
 
+Upgrade does not stop for modules that it cannot fetch or upgrade.
+It stages every upgrade it can make and reports every such module at once.
+
 An upgrade command alone on the command line implies reading glide.lock in
 before, writing glide.lock out after, and checking out the new vendor.
 `
@@ -85,15 +93,11 @@ func upgradeCommand() Command {
 
 			driver.err.Start("Upgrading")
 			defer driver.err.Stop("Upgrading")
-			next, err := Upgrade(ctx, memo, driver.err, state)
-			if err != nil {
-				return err
-			}
-			state = next
-			driver.push(state)
+			next, err := Upgrade(ctx, memo, driver.err, state, memo.Policies, memo.Prereleases, memo.Exclusions)
+			driver.push(next)
 
 			ShowDiff(driver.out, driver.prev.Modules(), driver.next.Modules())
-			return nil
+			return err
 		},
 	}
 }
@@ -0,0 +1,254 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const verifyUsage UsageError = `Usage: gg verify
+Example: gg verify
+Example: gg format json read-only verify
+
+Verifies that the vendor directory holds exactly the files of the locked commit
+of every module in the staged solution, as checkout would write them, less the
+files that the [prune] settings of gg.toml or Gopkg.toml leave out.  Computes
+the git tree hash of each vendor/<module> directory and compares it with the
+tree of the module's commit in the cache, then reports the files of each module
+that were modified, are missing, or are extra.  Verify also compares the digest
+of the whole vendor directory with the digest of the locked commits, as
+glide.lock records it, and reports the files in vendor that belong to no
+module.  Verify fails if any module or the digest does not match, so it can
+guard a build or a continuous integration job.
+
+If the stage is empty, verify first reads the lockfile that write would write
+back, in offline mode.  That must be go.mod, Gopkg.lock, or glide.lock, since
+Gopkg.toml and glide.yaml do not lock the commits to verify.  Verify reads the
+commits of modules and their submodules only from the cache, so it works
+without a network connection.
+`
+
+func verifyCommand() Command {
+	return Command{
+		Names: []string{
+			"verify",
+		},
+		Usage: verifyUsage,
+		Niladic: func(ctx context.Context, driver *Driver) error {
+			if len(driver.next.Modules()) == 0 {
+				lockfile := driver.Lockfile()
+				if !lockfile.Locked {
+					return fmt.Errorf("cannot verify vendor against %s, which does not lock commits, expected one of %s", lockfile.Name, LockedNames())
+				}
+				offline := driver.memo.Offline
+				driver.memo.Offline = true
+				err := driver.ExecuteArguments(ctx, lockfile.Read)
+				driver.memo.Offline = offline
+				if err != nil {
+					return err
+				}
+			}
+
+			modules := driver.next.Modules()
+			pruner, err := driver.VendorPruner(ctx, modules)
+			if err != nil {
+				return err
+			}
+
+			msg := "Verifying vendor"
+			driver.err.Start(msg)
+			verifications := VerifyVendor(driver.memo.Repository, "vendor", modules, pruner)
+			digest := VerifyVendorDigest(driver.memo.Repository, "vendor", modules, pruner)
+			driver.err.Stop(msg)
+
+			if driver.format != FormatText {
+				if err := WriteReport(driver.out, driver.format, NewVerifyReport(verifications, digest)); err != nil {
+					return err
+				}
+			} else {
+				ShowVerify(driver.out, verifications)
+				ShowVerifyDigest(driver.out, digest)
+			}
+			if failed := countUnverified(verifications); failed > 0 {
+				return fmt.Errorf("vendor does not match the locked commits of %d of %d modules", failed, len(verifications))
+			}
+			if !digest.OK() {
+				return fmt.Errorf("vendor does not match the digest of the locked commits")
+			}
+			return nil
+		},
+	}
+}
+
+// ShowVerify writes whether the vendor checkout of each module matches its
+// locked commit, and if not, which files differ.
+func ShowVerify(out io.Writer, verifications []VendorVerification) {
+	if len(verifications) == 0 {
+		fmt.Fprintf(out, "* No modules.\n")
+	}
+	for _, verification := range verifications {
+		if verification.Error != nil {
+			fmt.Fprintf(out, red+"* %s: %s"+clear+"\n", verification.Module.Summary(), verification.Error)
+			continue
+		}
+		if verification.OK() {
+			fmt.Fprintf(out, "* %s matches tree %s\n", verification.Module.Summary(), verification.Locked)
+			continue
+		}
+		fmt.Fprintf(out, red+"* %s has tree %s instead of %s"+clear+"\n", verification.Module.Summary(), verification.Vendor, verification.Locked)
+		for _, name := range verification.Modified {
+			fmt.Fprintf(out, yellow+"  modified: %s"+clear+"\n", name)
+		}
+		for _, name := range verification.Missing {
+			fmt.Fprintf(out, red+"  missing:  %s"+clear+"\n", name)
+		}
+		for _, name := range verification.Extra {
+			fmt.Fprintf(out, green+"  extra:    %s"+clear+"\n", name)
+		}
+	}
+}
+
+// ShowVerifyDigest writes whether the whole vendor directory matches the
+// digest of the locked commits, and if not, which files belong to no module.
+func ShowVerifyDigest(out io.Writer, verification VendorDigestVerification) {
+	if verification.Error != nil {
+		fmt.Fprintf(out, red+"* vendor: %s"+clear+"\n", verification.Error)
+		return
+	}
+	if verification.OK() {
+		fmt.Fprintf(out, "* vendor matches digest %s\n", verification.Locked)
+		return
+	}
+	fmt.Fprintf(out, red+"* vendor has digest %s instead of %s"+clear+"\n", verification.Vendor, verification.Locked)
+	for _, name := range verification.Stray {
+		fmt.Fprintf(out, green+"  stray:    %s"+clear+"\n", name)
+	}
+}
+
+func countUnverified(verifications []VendorVerification) int {
+	count := 0
+	for _, verification := range verifications {
+		if !verification.OK() {
+			count++
+		}
+	}
+	return count
+}
+
+// VerifyReport is the machine-readable schema for the verify report.
+type VerifyReport struct {
+	Modules []VerifyRecord     `json:"modules"`
+	Digest  VerifyDigestRecord `json:"digest"`
+}
+
+// VerifyRecord is the result of verifying the vendor checkout of a module:
+// the hashes of the trees of its locked commit and its checkout, the files
+// that differ, and an error if the checkout could not be verified.
+type VerifyRecord struct {
+	Module   ModuleRecord `json:"module"`
+	Locked   string       `json:"locked"`
+	Vendor   string       `json:"vendor"`
+	OK       bool         `json:"ok"`
+	Modified []string     `json:"modified"`
+	Missing  []string     `json:"missing"`
+	Extra    []string     `json:"extra"`
+	Error    string       `json:"error"`
+}
+
+// VerifyDigestRecord is the result of verifying the whole vendor directory:
+// the digests of the locked commits and of the vendor directory, the files
+// that belong to no module, and an error if the digests could not be
+// computed.
+type VerifyDigestRecord struct {
+	Locked string   `json:"locked"`
+	Vendor string   `json:"vendor"`
+	OK     bool     `json:"ok"`
+	Stray  []string `json:"stray"`
+	Error  string   `json:"error"`
+}
+
+// NewVerifyReport returns the machine-readable verify report.
+func NewVerifyReport(verifications []VendorVerification, digest VendorDigestVerification) VerifyReport {
+	report := VerifyReport{
+		Modules: make([]VerifyRecord, 0, len(verifications)),
+	}
+	for _, verification := range verifications {
+		record := VerifyRecord{
+			Module:   NewModuleRecord(verification.Module),
+			Locked:   HashString(verification.Locked),
+			Vendor:   HashString(verification.Vendor),
+			OK:       verification.OK(),
+			Modified: append([]string{}, verification.Modified...),
+			Missing:  append([]string{}, verification.Missing...),
+			Extra:    append([]string{}, verification.Extra...),
+		}
+		if verification.Error != nil {
+			record.Error = verification.Error.Error()
+		}
+		report.Modules = append(report.Modules, record)
+	}
+	report.Digest = VerifyDigestRecord{
+		Locked: HashString(digest.Locked),
+		Vendor: HashString(digest.Vendor),
+		OK:     digest.OK(),
+		Stray:  append([]string{}, digest.Stray...),
+	}
+	if digest.Error != nil {
+		report.Digest.Error = digest.Error.Error()
+	}
+	return report
+}
+
+// Header returns the names of the columns of the TSV rendition.
+func (report VerifyReport) Header() []string {
+	return []string{"module", "ok", "locked", "vendor", "change", "path", "error"}
+}
+
+// Rows returns a TSV row for every file that differs, or a single row for a
+// module with no differing files, then a row for every file that belongs to
+// no module, with an empty module name.
+func (report VerifyReport) Rows() [][]string {
+	var rows [][]string
+	for _, record := range report.Modules {
+		row := []string{record.Module.Name, strconv.FormatBool(record.OK), record.Locked, record.Vendor}
+		count := len(rows)
+		for _, change := range []struct {
+			name  string
+			paths []string
+		}{
+			{"modified", record.Modified},
+			{"missing", record.Missing},
+			{"extra", record.Extra},
+		} {
+			for _, name := range change.paths {
+				rows = append(rows, append(append([]string{}, row...), change.name, name, record.Error))
+			}
+		}
+		if len(rows) == count {
+			rows = append(rows, append(row, "", "", record.Error))
+		}
+	}
+	digest := report.Digest
+	for _, name := range digest.Stray {
+		rows = append(rows, []string{"", strconv.FormatBool(digest.OK), digest.Locked, digest.Vendor, "stray", name, digest.Error})
+	}
+	if digest.Error != "" {
+		rows = append(rows, []string{"", strconv.FormatBool(digest.OK), digest.Locked, digest.Vendor, "", "", digest.Error})
+	}
+	return rows
+}
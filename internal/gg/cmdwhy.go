@@ -0,0 +1,187 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const whyUsage UsageError = `Usage: gg why <module>
+Example: gg read why go.uber.org/zap
+
+Explains how the version of a module in the staged solution got there.  Shows
+which dependees' lockfiles demanded that version, and which older versions it
+beat because other dependees wanted a newer one.  Then shows every path from a
+module that the working copy depends on directly, through the modules that
+depend on each other, to the given module, with the version each module's
+lockfile wants of the next and how the solution differs.
+
+In the place of the module, an abbreviated commit hash finds the module with
+that commit in the solution.
+`
+
+func whyCommand() Command {
+	return Command{
+		Names: []string{
+			"why",
+		},
+		Usage:         whyUsage,
+		Read:          true,
+		SuggestModule: true,
+		Monadic: func(ctx context.Context, driver *Driver, spec string) error {
+			module, err := driver.FindSolutionOrExpressModule(ctx, spec, false)
+			if err != nil {
+				return err
+			}
+			state := driver.next
+			for name := range state.Dependees.Transitive(StringSet{module.Name: {}}) {
+				if partial, ok := state.Solution[name]; ok {
+					if err := driver.memo.FinishModules(ctx, driver.err, partial.Module.Modules); err != nil {
+						fmt.Fprintf(driver.err, "Failed to normalize the requirements of %s: %s\n", partial.Module.Summary(), err)
+					}
+				}
+			}
+			why, err := Explain(state, module.Name)
+			if err != nil {
+				return err
+			}
+			if driver.format != FormatText {
+				return WriteReport(driver.out, driver.format, NewWhyReport(why))
+			}
+			ShowWhy(driver.out, why)
+			return nil
+		},
+	}
+}
+
+// ShowWhy writes an explanation of how a module entered the solution.
+func ShowWhy(out io.Writer, why Why) {
+	summary := why.Module.Summary()
+	if why.Shallow {
+		fmt.Fprintf(out, "The working copy depends on %s directly.\n", summary)
+	}
+	demanders := why.Demanders()
+	for _, demander := range demanders {
+		fmt.Fprintf(out, "The lockfile of %s demands %s.\n", demander.Module.Summary(), summary)
+	}
+	if len(demanders) == 0 && !why.Shallow {
+		fmt.Fprintf(out, "No lockfile demands %s, so an upgrade or add chose it.\n", summary)
+	}
+	if beaten := why.Beaten(); len(beaten) > 0 {
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "It beat older requests:\n")
+		for _, request := range beaten {
+			fmt.Fprintf(out, "  %s wants ", request.Module.Summary())
+			showWant(out, request)
+			fmt.Fprintf(out, "\n")
+		}
+	}
+
+	fmt.Fprintf(out, "\n")
+	fmt.Fprintf(out, "Paths:\n")
+	if len(why.Paths) == 0 {
+		fmt.Fprintf(out, "* No paths from other modules\n")
+	}
+	for _, path := range why.Paths {
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "  %s\n", path.Root.Summary())
+		for _, hop := range path.Hops {
+			fmt.Fprintf(out, "  -> ")
+			showWant(out, hop)
+			fmt.Fprintf(out, "\n")
+		}
+	}
+	if why.Truncated {
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "Showing only the first %d paths.\n", WhyMaxPaths)
+	}
+}
+
+func showWant(out io.Writer, dependency Dependency) {
+	switch change := DependencyChange(dependency.Want, dependency.Got); change {
+	case "conflict":
+		fmt.Fprintf(out, "%s "+yellow+"(conflict, got %s)"+clear, dependency.Want.Summary(), dependency.Got.Summary())
+	case "upgrade":
+		fmt.Fprintf(out, "%s "+green+"(upgrade to %s)"+clear, dependency.Want.Summary(), dependency.Got.Summary())
+	case "downgrade":
+		fmt.Fprintf(out, "%s "+red+"(downgrade to %s)"+clear, dependency.Want.Summary(), dependency.Got.Summary())
+	default:
+		fmt.Fprintf(out, "%s (%s)", dependency.Want.Summary(), change)
+	}
+}
+
+// WhyReport is the machine-readable schema for the why report.
+type WhyReport struct {
+	Module    ModuleRecord       `json:"module"`
+	Shallow   bool               `json:"shallow"`
+	Requests  []DependencyRecord `json:"requests"`
+	Paths     []WhyPathRecord    `json:"paths"`
+	Truncated bool               `json:"truncated"`
+}
+
+// WhyPathRecord is a path from a shallow dependency of the working copy to
+// the module, with the version each module on the path wants of the next.
+type WhyPathRecord struct {
+	Root string             `json:"root"`
+	Hops []DependencyRecord `json:"hops"`
+}
+
+// NewWhyReport returns the machine-readable report of how a module entered
+// the solution.
+func NewWhyReport(why Why) WhyReport {
+	report := WhyReport{
+		Module:    NewModuleRecord(why.Module),
+		Shallow:   why.Shallow,
+		Requests:  newDependencyRecords(why.Requests),
+		Paths:     make([]WhyPathRecord, 0, len(why.Paths)),
+		Truncated: why.Truncated,
+	}
+	for _, path := range why.Paths {
+		report.Paths = append(report.Paths, WhyPathRecord{
+			Root: path.Root.Summary(),
+			Hops: newDependencyRecords(path.Hops),
+		})
+	}
+	return report
+}
+
+// Header returns the names of the columns of the TSV rendition.
+func (report WhyReport) Header() []string {
+	header := []string{"relation", "path", "root", "dependee", "change"}
+	header = append(header, moduleHeader("want_")...)
+	return append(header, moduleHeader("got_")...)
+}
+
+// Rows returns a TSV row for every request for the module, then a row for
+// every hop of every path, numbered from 1.
+func (report WhyReport) Rows() [][]string {
+	var rows [][]string
+	for _, record := range report.Requests {
+		row := []string{"request", "", "", record.Dependee, record.Change}
+		row = append(row, record.Want.Cells()...)
+		rows = append(rows, append(row, record.Got.Cells()...))
+	}
+	for i, path := range report.Paths {
+		for _, record := range path.Hops {
+			row := []string{"hop", strconv.Itoa(i + 1), path.Root, record.Dependee, record.Change}
+			row = append(row, record.Want.Cells()...)
+			rows = append(rows, append(row, record.Got.Cells()...))
+		}
+	}
+	return rows
+}
@@ -20,8 +20,13 @@ const writeUsage UsageError = `Usage: gg write/w
 Example: gg read upgrade write
 
 Checks out the staged dependency solution into the vendor directory, replacing
-whatever was previously there and writes a new glide.lock.  This is equivalent
-to "gg checkout write-only" or "gg co wo".
+whatever was previously there and writes a new lockfile.  This is equivalent
+to "gg checkout write-only" or "gg co wo" for a glide.lock.
+
+Write regenerates the same kind of file that was read, for example
+"gg checkout write-mod-lock" after reading a go.mod.  If nothing was read,
+write regenerates the first lockfile in the working copy in the order of
+precedence for read, or glide.lock if there is none.
 `
 
 func writeCommand() Command {
@@ -33,7 +38,7 @@ func writeCommand() Command {
 		Usage: writeUsage,
 		Read:  true,
 		Niladic: func(ctx context.Context, driver *Driver) error {
-			return driver.ExecuteArguments(ctx, "checkout", "write-glide-lock")
+			return driver.ExecuteArguments(ctx, "checkout", driver.Lockfile().Write)
 		},
 	}
 }
@@ -14,7 +14,10 @@
 
 package gg
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 const writeOnlyUsage UsageError = `Usage: gg write-only/wo
 Usage: gg write-glide-lock/wgl
@@ -25,6 +28,14 @@ for "gg write" or "gg w" if you have already previously checked out the
 solution's vendor tree with "gg checkout" or "gg co".  Checking out the vendor
 tree, running tests, and checking for conflicts are good practices before
 writing a new glide.lock.
+
+The glide.lock records the hash of the git tree that checkout writes for each
+module, and for the whole vendor directory, which install uses to tell whether
+the vendor directory diverges from the glide.lock.  These hashes derive from
+the commits in the cache, so write-only need not follow a checkout, though
+checkout updates them in an existing glide.lock.  If some modules cannot be
+read from the cache, write-only still writes glide.lock, with the hashes of the
+others but without the hash of the whole vendor directory.
 `
 
 func writeOnlyCommand() Command {
@@ -43,10 +54,26 @@ func writeOnlyCommand() Command {
 				return err
 			}
 
+			// Without the vendor digest, install checks out the vendor
+			// directory anyway, so failing to digest is no reason to fail to
+			// write glide.lock.
+			digested, vendor := modules, NoHash
+			pruner, err := driver.VendorPruner(ctx, modules)
+			if err == nil {
+				digested, vendor, err = DigestVendor(driver.memo.Repository, modules, pruner)
+			} else {
+				for i := range digested {
+					digested[i].Tree = NoHash
+				}
+			}
+			if err != nil {
+				fmt.Fprintf(driver.err, "Cannot digest the vendor tree for glide.lock: %s\n", err)
+			}
+
 			msg := "Writing glide.lock"
 			driver.err.Start(msg)
 			driver.prev = driver.next
-			err := WriteOwnModules(modules)
+			err = WriteOwnModules(digested, vendor)
 			driver.err.Stop(msg)
 			return err
 		},
@@ -0,0 +1,135 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+const writeModLockUsage UsageError = `Usage: gg write-mod-lock/write-go-mod/wml
+Example: gg r wml
+
+Writes go.mod and go.sum from the staged solution.
+
+Every module in the solution becomes a requirement, using its version if it
+has a tag like v1.2.3, or a pseudo-version from its commit timestamp and hash
+otherwise.
+Modules that the working copy imports directly are direct requirements.
+All others are marked "// indirect".
+
+The module path comes from the existing go.mod, if any, or the location of the
+working copy in the GOPATH.
+The go, replace, and exclude directives of an existing go.mod carry over.
+
+The go.sum checksums come from the git trees in the .gg cache, so this command
+works offline.
+`
+
+func writeModLockCommand() Command {
+	return Command{
+		Names: []string{
+			"write-mod-lock",
+			"write-go-mod",
+			"wml",
+		},
+		Usage: writeModLockUsage,
+		Read:  true,
+		Niladic: func(ctx context.Context, driver *Driver) error {
+			memo := driver.memo
+			name, packages, err := memo.ReadOwnPackages(ctx, driver.err)
+			if err != nil {
+				return err
+			}
+
+			// Carry the module path, go version, and replace and exclude
+			// directives over from the former go.mod, refusing to write over
+			// a go.mod we cannot read entirely.
+			former, err := ReadOwnModLock()
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("cannot read go.mod: %s", err)
+			}
+
+			modules := driver.next.Modules()
+			if err := memo.FinishPackages(ctx, driver.err, modules); err != nil {
+				return err
+			}
+
+			shallow := ShallowSolution(packages, modules)
+
+			lock := ModLockFromModules(name, modules, shallow)
+			if former.Module != "" {
+				lock.Module = former.Module
+			}
+			lock.Go = former.Go
+			if lock.Go == "" {
+				lock.Go = ModGoVersion
+			}
+			lock.Replaces = former.Replaces
+			for _, exclude := range former.Excludes {
+				if !lock.Required(exclude) {
+					lock.Excludes = append(lock.Excludes, exclude)
+				}
+			}
+
+			sums, err := memo.ModSums(ctx, driver.err, modules)
+			if err != nil {
+				return err
+			}
+
+			msg := "Writing go.mod"
+			driver.err.Start(msg)
+			err = WriteOwnModLock(lock)
+			if err == nil {
+				err = WriteOwnModSum(sums)
+			}
+			driver.err.Stop(msg)
+			return err
+		},
+	}
+}
+
+// ModSums computes the go.sum entries for each of the given modules from
+// their git trees, in the order the go command writes them.
+func (memo *Memo) ModSums(ctx context.Context, out ProgressWriter, modules Modules) ([]ModSum, error) {
+	sums := make([]ModSum, 0, 2*len(modules))
+	start := time.Now()
+	out.Start("Computing checksums")
+	defer out.Stop("Computing checksums")
+	for i, module := range modules {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		commit, err := memo.Commit(ctx, out, module.Hash)
+		if err != nil {
+			return nil, err
+		}
+		tree, err := memo.Repository.TreeObject(commit.TreeHash)
+		if err != nil {
+			return nil, fmt.Errorf("error attempting to get a Git tree to compute checksums for %s from commit %s: %s", module.Summary(), module.Hash, err)
+		}
+		moduleSums, err := ReadGitModSums(memo.Repository, tree, modVersionFromModule(module))
+		if err != nil {
+			return nil, err
+		}
+		sums = append(sums, moduleSums...)
+		out.Progress("Computing checksums", i+1, len(modules), start, time.Now())
+	}
+	return sums, nil
+}
@@ -52,6 +52,10 @@ type Command struct {
 	// Write means that, if this command is executed alone at the command line,
 	// we must implicitly read and solve before and write and checkout afterward.
 	Write bool
+	// Prefix means that this command sets a mode for the commands that follow
+	// it on the command line, and does not prevent a sole following command
+	// from implying a read or write.
+	Prefix bool
 }
 
 // UsageError is a usage string that can be used as an error.
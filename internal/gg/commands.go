@@ -24,6 +24,7 @@ func commands() []Command {
 		// Sorted
 		addCommand(),
 		addMissingCommand(),
+		apiDiffCommand(),
 		backCommand(),
 		cpuProfileCommand(),
 		changelogCommand(),
@@ -35,16 +36,21 @@ func commands() []Command {
 		execCommand(),
 		fetchCommand(),
 		foreCommand(),
+		formatCommand(),
 		gitCommand(),
 		glidelockCommand(),
 		helpCommand(),
 		initCommand(),
 		installCommand(),
+		loadCommand(),
+		logCommand(),
 		markCommand(),
 		metricsCommand(),
 		newCommand(),
 		offlineCommand(),
 		onlineCommand(),
+		outdatedCommand(),
+		outdatedByCommand(),
 		pruneCommand(),
 		pullCommand(),
 		pushCommand(),
@@ -52,18 +58,23 @@ func commands() []Command {
 		readCommand(),
 		readDepLockCommand(),
 		readDepManifestCommand(),
+		readModLockCommand(),
 		readGlideManifestCommand(),
 		readOnlyCommand(),
+		releaseNotesCommand(),
 		removeCommand(),
 		resetCommand(),
+		saveCommand(),
 		shellCommand(),
 		showConflictsCommand(),
 		showDiffCommand(),
 		showExtraModulesCommand(),
+		showGraphCommand(),
 		showImportsCommand(),
 		showMissingPackagesCommand(),
 		showModuleCommand(),
 		showOwnPackagesCommand(),
+		showPackageGraphCommand(),
 		showPackagesCommand(),
 		showRemotesCacheCommand(),
 		showShallowSolutionCommand(),
@@ -72,11 +83,14 @@ func commands() []Command {
 		solveCommand(),
 		traceCommand(),
 		upgradeCommand(),
+		verifyCommand(),
 		versionCommand(),
+		whyCommand(),
 		writeCommand(),
 		writeDepLockCommand(),
 		writeDepManifestCommand(),
 		writeGlideManifestCommand(),
+		writeModLockCommand(),
 		writeOnlyCommand(),
 	}
 }
@@ -27,29 +27,66 @@
 package gg
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/BurntSushi/toml"
 )
 
 // Config is the schema for the gg.toml file, which configures gg when running
 // in a child directory.
+// The Config for the working copy merges every gg.toml from the working
+// directory up to the root directory.
 type Config struct {
 	// Cache is the git URL of a git repository that serves as a refs/vendor
 	// cache.
 	Cache string `toml:"cache"`
+	// Repository is the location of the git repository that gg uses as a
+	// cache: "gg" for .gg in the working directory, "git" for the project's
+	// own git repository, or "user" for a cache shared by all of the user's
+	// projects.
+	Repository string `toml:"repository"`
 	// Remotes override the default behavior for finding the remote repository
 	// for modules that have matching name patterns.
 	Remotes []ConfigRemote `toml:"remotes"`
 	// Packages overrides the default version that gg will give in the
 	// add-missing modules workflow.
 	Packages []ConfigPackage `toml:"packages"`
+	// Policies govern how far the upgrade and add-missing workflows may move
+	// modules that have matching name patterns.
+	Policies []ConfigPolicy `toml:"policy"`
+	// ExcludeVersions rule out versions of modules that have matching name
+	// patterns, for example releases that are known to be broken.
+	ExcludeVersions []ConfigExcludeVersion `toml:"exclude-version"`
 	// Excludes adds paths to the list of directories to ignore in the working
 	// copy directory tree, to discover the project's package import graph.
 	Excludes []ConfigExclude `toml:"excludes"`
+	// Lockfiles are the names of the files that the read command looks for in
+	// the working copy, in order of precedence.
+	Lockfiles []string `toml:"lockfiles"`
+	// Concurrency is the number of modules to fetch at once.
+	Concurrency int `toml:"concurrency"`
+	// Prereleases allows the upgrade workflow to consider pre-release
+	// versions of every module, like 1.2.0-rc.1.
+	// Absent a setting in any gg.toml, upgrades consider only final releases,
+	// except for packages that opt in individually.
+	Prereleases *bool `toml:"prereleases"`
+	// Prune leaves tests, unused packages, or non-Go files out of the vendor
+	// checkout of every module.
+	Prune ConfigPrune `toml:"prune"`
+	// PruneModules override the pruning of the vendor checkout of modules
+	// that have matching name patterns.
+	PruneModules []ConfigPruneModule `toml:"prune-module"`
+
+	// Files are the gg.toml files merged into this configuration, nearest
+	// first.
+	Files []string `toml:"-"`
+	// Sources are the gg.toml files that settings like cache, repository,
+	// lockfiles, concurrency, prereleases, and prune.go-tests came from, by
+	// name.
+	Sources map[string]string `toml:"-"`
 }
 
 // ConfigRemote specifies the remote repository location pattern to use for
@@ -66,23 +103,84 @@ type ConfigRemote struct {
 	// GitoliteMirror indicates that the remote is a gitolite mirror and may
 	// need to be created with an ssh create command.
 	GitoliteMirror bool `toml:"gitoliteMirror"`
+	// Source is the gg.toml this rule came from.
+	Source string `toml:"-"`
 }
 
 // ConfigPackage specifies the version of a module to add to a solution in the
-// add-missing workflow.
+// add-missing workflow, and whether to upgrade the module to pre-releases.
 // The default behavior is to get the newest version, or failing that, the
 // master branch.
 type ConfigPackage struct {
 	// Package is a module name.
 	Package string `toml:"package"`
-	// Version is a version number like "1" or "v1.2.3".
+	// Version is a version number like "1" or "v1.2.3", or empty to let the
+	// add-missing workflow choose.
 	Version string `toml:"version"`
+	// Prereleases allows the upgrade workflow to consider pre-release
+	// versions of this module.
+	Prereleases bool `toml:"prereleases"`
+	// Source is the gg.toml this recommendation came from.
+	Source string `toml:"-"`
+}
+
+// ConfigPolicy specifies the upgrade policy for modules with names that match
+// a pattern.
+type ConfigPolicy struct {
+	// Pattern is a glob-like pattern that matches a module name, and may
+	// include * for wild path components, or ... for any suffix.
+	Pattern string `toml:"pattern"`
+	// Mode is "hold", "patch", "minor", "major", or "track-branch" followed by
+	// a branch name.
+	Mode string `toml:"mode"`
+	// Source is the gg.toml this policy came from.
+	Source string `toml:"-"`
+}
+
+// ConfigExcludeVersion specifies versions of modules with names that match a
+// pattern that gg must not choose.
+type ConfigExcludeVersion struct {
+	// Pattern is a glob-like pattern that matches a module name, and may
+	// include * for wild path components, or ... for any suffix.
+	Pattern string `toml:"pattern"`
+	// Versions are version numbers like "v1.2.3", references like
+	// "heads/broken", or commit hash prefixes.
+	Versions []string `toml:"versions"`
+	// Source is the gg.toml this exclusion came from.
+	Source string `toml:"-"`
+}
+
+// ConfigPrune specifies what to leave out of the vendor checkout of every
+// module, leaving unset settings to farther files.
+type ConfigPrune struct {
+	// GoTests leaves out _test.go files and testdata directories.
+	GoTests *bool `toml:"go-tests"`
+	// UnusedPackages leaves out packages that the working copy does not need.
+	UnusedPackages *bool `toml:"unused-packages"`
+	// NonGo leaves out files that the go tool does not build, except for
+	// licences.
+	NonGo *bool `toml:"non-go"`
+}
+
+// ConfigPruneModule specifies what to leave out of the vendor checkout of
+// modules with names that match a pattern, overriding the settings it sets.
+type ConfigPruneModule struct {
+	// Pattern is a glob-like pattern that matches a module name, and may
+	// include * for wild path components, or ... for any suffix.
+	Pattern        string `toml:"pattern"`
+	GoTests        *bool  `toml:"go-tests"`
+	UnusedPackages *bool  `toml:"unused-packages"`
+	NonGo          *bool  `toml:"non-go"`
+	// Source is the gg.toml this rule came from.
+	Source string `toml:"-"`
 }
 
 // ConfigExclude specifies a directory name to exclude when searching for go
 // files in the working copy tree.
 type ConfigExclude struct {
 	Path string `toml:"path"`
+	// Source is the gg.toml this exclusion came from.
+	Source string `toml:"-"`
 }
 
 // ReadConfig reads gg.toml from bytes.
@@ -92,26 +190,116 @@ func ReadConfig(bytes []byte) (*Config, error) {
 	return &config, err
 }
 
-// ReadOwnConfig reads gg.toml from the working directory or any ancestor
-// directory.
-// The youngest ancestor directory shadows all others.
+// ReadOwnConfig reads and merges every gg.toml from the working directory up
+// to the root directory.
+// Nearer files take precedence.
+// Their remote, policy, and prune-module patterns match before those of
+// farther files, their recommended versions override those for the same
+// package, and their cache, repository, lockfiles, concurrency, prereleases,
+// and prune settings shadow those of farther files.
+// Excludes and excluded versions accumulate from every file.
 func ReadOwnConfig(workDir string) (*Config, error) {
+	merged := &Config{Sources: make(map[string]string)}
 	for {
-		var file *os.File
-		file, err := os.Open(filepath.Join(workDir, "gg.toml"))
-		if err != nil {
-			workDir, _ = filepath.Split(strings.TrimSuffix(workDir, string(os.PathSeparator)))
-			if workDir == "" {
-				return &Config{}, nil
+		path := filepath.Join(workDir, "gg.toml")
+		bytes, err := ioutil.ReadFile(path)
+		if err == nil {
+			config, err := ReadConfig(bytes)
+			if err != nil {
+				return merged, fmt.Errorf("cannot read %s: %s", path, err)
 			}
-			continue
+			merged.merge(config, path)
+		} else if !os.IsNotExist(err) {
+			return merged, err
 		}
 
-		bytes, err := ioutil.ReadAll(file)
-		if err != nil {
-			return &Config{}, err
+		parent := filepath.Dir(workDir)
+		if parent == workDir {
+			return merged, nil
+		}
+		workDir = parent
+	}
+}
+
+// merge incorporates the settings of a gg.toml that is farther from the
+// working directory than all of those merged before, noting the source of
+// each setting.
+func (config *Config) merge(farther *Config, path string) {
+	config.Files = append(config.Files, path)
+
+	if config.Cache == "" && farther.Cache != "" {
+		config.Cache = farther.Cache
+		config.Sources["cache"] = path
+	}
+	if config.Repository == "" && farther.Repository != "" {
+		config.Repository = farther.Repository
+		config.Sources["repository"] = path
+	}
+	if len(config.Lockfiles) == 0 && len(farther.Lockfiles) != 0 {
+		config.Lockfiles = farther.Lockfiles
+		config.Sources["lockfiles"] = path
+	}
+	if config.Concurrency == 0 && farther.Concurrency != 0 {
+		config.Concurrency = farther.Concurrency
+		config.Sources["concurrency"] = path
+	}
+	if config.Prereleases == nil && farther.Prereleases != nil {
+		config.Prereleases = farther.Prereleases
+		config.Sources["prereleases"] = path
+	}
+	if config.Prune.GoTests == nil && farther.Prune.GoTests != nil {
+		config.Prune.GoTests = farther.Prune.GoTests
+		config.Sources["prune.go-tests"] = path
+	}
+	if config.Prune.UnusedPackages == nil && farther.Prune.UnusedPackages != nil {
+		config.Prune.UnusedPackages = farther.Prune.UnusedPackages
+		config.Sources["prune.unused-packages"] = path
+	}
+	if config.Prune.NonGo == nil && farther.Prune.NonGo != nil {
+		config.Prune.NonGo = farther.Prune.NonGo
+		config.Sources["prune.non-go"] = path
+	}
+
+	for _, remote := range farther.Remotes {
+		remote.Source = path
+		config.Remotes = append(config.Remotes, remote)
+	}
+
+	for _, policy := range farther.Policies {
+		policy.Source = path
+		config.Policies = append(config.Policies, policy)
+	}
+
+	packages := make(StringSet, len(config.Packages))
+	for _, recommend := range config.Packages {
+		packages.Add(recommend.Package)
+	}
+	for _, recommend := range farther.Packages {
+		if !packages.Has(recommend.Package) {
+			recommend.Source = path
+			config.Packages = append(config.Packages, recommend)
+		}
+	}
+
+	for _, exclude := range farther.ExcludeVersions {
+		exclude.Source = path
+		config.ExcludeVersions = append(config.ExcludeVersions, exclude)
+	}
+
+	for _, prune := range farther.PruneModules {
+		prune.Source = path
+		config.PruneModules = append(config.PruneModules, prune)
+	}
+
+	excludes := make(StringSet, len(config.Excludes))
+	for _, exclude := range config.Excludes {
+		excludes.Add(exclude.Path)
+	}
+	for _, exclude := range farther.Excludes {
+		if !excludes.Has(exclude.Path) {
+			exclude.Source = path
+			config.Excludes = append(config.Excludes, exclude)
 		}
-		return ReadConfig(bytes)
 	}
 }
 
@@ -149,12 +337,112 @@ func (config *Config) ReadExcludes() StringSet {
 	return excludes
 }
 
+// ReadLockfiles collects the kinds of lockfile for the read command to look
+// for in the working copy, in order of precedence, or the default order if
+// unspecified.
+func (config *Config) ReadLockfiles() ([]Lockfile, error) {
+	if len(config.Lockfiles) == 0 {
+		return Lockfiles, nil
+	}
+	lockfiles := make([]Lockfile, 0, len(config.Lockfiles))
+	for _, name := range config.Lockfiles {
+		lockfile, err := FindLockfile(name)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read lockfiles from gg.toml: %s", err)
+		}
+		lockfiles = append(lockfiles, lockfile)
+	}
+	return lockfiles, nil
+}
+
 // ReadRecommended collects the recommended versions for new versions of a
 // known module.
 func (config *Config) ReadRecommended() map[string]Version {
 	recs := make(map[string]Version)
 	for _, recommend := range config.Packages {
-		recs[recommend.Package] = ParseVersion(recommend.Version)
+		if recommend.Version != "" {
+			recs[recommend.Package] = ParseVersion(recommend.Version)
+		}
 	}
 	return recs
 }
+
+// ReadPrereleases collects which modules the upgrade workflow may upgrade to
+// pre-release versions.
+func (config *Config) ReadPrereleases() Prereleases {
+	prereleases := Prereleases{
+		All:     config.Prereleases != nil && *config.Prereleases,
+		Modules: make(StringSet),
+	}
+	for _, recommend := range config.Packages {
+		if recommend.Prereleases {
+			prereleases.Modules.Add(recommend.Package)
+		}
+	}
+	return prereleases
+}
+
+// ReadPolicies converts the policies of gg.toml to policy objects, in order of
+// precedence.
+func (config *Config) ReadPolicies() (Policies, error) {
+	policies := make(Policies, 0, len(config.Policies))
+	for _, rule := range config.Policies {
+		policy, err := ParsePolicy(rule.Pattern, rule.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read policy from %s: %s", rule.Source, err)
+		}
+		policy.Source = rule.Source
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// ReadExclusions converts the excluded versions of gg.toml to exclusion
+// objects.
+func (config *Config) ReadExclusions() Exclusions {
+	exclusions := make(Exclusions, 0, len(config.ExcludeVersions))
+	for _, exclude := range config.ExcludeVersions {
+		exclusions = append(exclusions, Exclusion{
+			Pattern:  exclude.Pattern,
+			Match:    PatternSplit(exclude.Pattern),
+			Versions: exclude.Versions,
+			Source:   exclude.Source,
+		})
+	}
+	return exclusions
+}
+
+// ReadVendorPruning converts the prune and prune-module settings of gg.toml to
+// vendor pruning rules, the rules for modules before the rule for every
+// module.
+func (config *Config) ReadVendorPruning() VendorPruning {
+	pruning := make(VendorPruning, 0, len(config.PruneModules)+1)
+	for _, prune := range config.PruneModules {
+		pruning = append(pruning, VendorPruneRule{
+			Pattern:        prune.Pattern,
+			Match:          PatternSplit(prune.Pattern),
+			GoTests:        prune.GoTests,
+			UnusedPackages: prune.UnusedPackages,
+			NonGo:          prune.NonGo,
+		})
+	}
+	return append(pruning, VendorPruneRule{
+		Pattern:        "...",
+		Match:          PatternSplit("..."),
+		GoTests:        config.Prune.GoTests,
+		UnusedPackages: config.Prune.UnusedPackages,
+		NonGo:          config.Prune.NonGo,
+	})
+}
+
+// ReadConcurrency returns the number of modules to fetch and read at once, or
+// the default if unspecified.
+func (config *Config) ReadConcurrency() (int, error) {
+	if config.Concurrency == 0 {
+		return DefaultConcurrency, nil
+	}
+	if config.Concurrency < 0 {
+		return 0, fmt.Errorf("cannot read concurrency from gg.toml: expected a positive number, got %d", config.Concurrency)
+	}
+	return config.Concurrency, nil
+}
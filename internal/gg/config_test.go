@@ -0,0 +1,240 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOwnConfig(t *testing.T) {
+	root, err := ioutil.TempDir("", "gg-config")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	org := filepath.Join(root, "org")
+	team := filepath.Join(org, "team")
+	work := filepath.Join(team, "project", "cmd")
+	require.NoError(t, os.MkdirAll(work, 0755))
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(org, "gg.toml"), []byte(`
+cache = "https://example.com/cache"
+lockfiles = ["glide.lock"]
+concurrency = 4
+prereleases = true
+
+[prune]
+go-tests = true
+non-go = false
+
+[[remotes]]
+pattern = "github.com/*/*"
+remote = "https://mirror.example.com/github/*/*"
+gitoliteMirror = true
+
+[[policy]]
+pattern = "go.uber.org/*"
+mode = "minor"
+
+[[packages]]
+package = "git.apache.org/thrift"
+version = "0.9"
+
+[[packages]]
+package = "go.uber.org/zap"
+version = "1"
+
+[[excludes]]
+path = "go-build"
+
+[[exclude-version]]
+pattern = "go.uber.org/thriftrw"
+versions = ["v1.10.0", "d8887717"]
+`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(team, "gg.toml"), []byte(`
+lockfiles = ["go.mod", "glide.lock"]
+prereleases = false
+
+[prune]
+non-go = true
+
+[[remotes]]
+pattern = "github.com/team/*"
+remote = "https://team.example.com/*"
+
+[[policy]]
+pattern = "go.uber.org/thriftrw"
+mode = "hold"
+
+[[packages]]
+package = "git.apache.org/thrift"
+version = "0.10"
+
+[[packages]]
+package = "go.uber.org/yarpc"
+prereleases = true
+
+[[excludes]]
+path = "go-build"
+
+[[excludes]]
+path = "node_modules"
+
+[[prune-module]]
+pattern = "github.com/gogo/protobuf"
+non-go = false
+`), 0644))
+
+	config, err := ReadOwnConfig(work)
+	require.NoError(t, err)
+
+	orgFile := filepath.Join(org, "gg.toml")
+	teamFile := filepath.Join(team, "gg.toml")
+	assert.Equal(t, []string{teamFile, orgFile}, config.Files)
+	assert.Equal(t, "https://example.com/cache", config.Cache)
+	assert.Equal(t, []string{"go.mod", "glide.lock"}, config.Lockfiles)
+	assert.Equal(t, map[string]string{
+		"cache":          orgFile,
+		"lockfiles":      teamFile,
+		"concurrency":    orgFile,
+		"prereleases":    teamFile,
+		"prune.go-tests": orgFile,
+		"prune.non-go":   teamFile,
+	}, config.Sources)
+	concurrency, err := config.ReadConcurrency()
+	require.NoError(t, err)
+	assert.Equal(t, 4, concurrency)
+
+	name, remote, rule := config.ReadPatterns().Replace("github.com/team/project")
+	assert.Equal(t, "github.com/team/project", name)
+	assert.Equal(t, "https://team.example.com/project", remote)
+	assert.Equal(t, 0, rule)
+	name, remote, rule = config.ReadPatterns().Replace("github.com/other/project")
+	assert.Equal(t, "github.com/other/project", name)
+	assert.Equal(t, "https://mirror.example.com/github/other/project", remote)
+	assert.Equal(t, map[int]struct{}{rule: {}}, config.ReadGitoliteMirrors())
+
+	assert.Equal(t, map[string]Version{
+		"git.apache.org/thrift": {0, 10, 0},
+		"go.uber.org/zap":       {1, 0, 0},
+	}, config.ReadRecommended())
+	policies, err := config.ReadPolicies()
+	require.NoError(t, err)
+	assert.Equal(t, Policies{
+		{Pattern: "go.uber.org/thriftrw", Match: PatternSplit("go.uber.org/thriftrw"), Mode: PolicyHold, Source: teamFile},
+		{Pattern: "go.uber.org/*", Match: PatternSplit("go.uber.org/*"), Mode: PolicyMinor, Source: orgFile},
+	}, policies)
+	assert.Equal(t, Exclusions{
+		{
+			Pattern:  "go.uber.org/thriftrw",
+			Match:    PatternSplit("go.uber.org/thriftrw"),
+			Versions: []string{"v1.10.0", "d8887717"},
+			Source:   orgFile,
+		},
+	}, config.ReadExclusions())
+	prereleases := config.ReadPrereleases()
+	assert.False(t, prereleases.Allow("go.uber.org/zap"))
+	assert.True(t, prereleases.Allow("go.uber.org/yarpc"))
+	assert.Equal(t, []ConfigExclude{
+		{Path: "go-build", Source: teamFile},
+		{Path: "node_modules", Source: teamFile},
+	}, config.Excludes)
+
+	pruning := config.ReadVendorPruning()
+	assert.Equal(t, VendorPrune{GoTests: true, NonGo: true}, pruning.Find("go.uber.org/zap"))
+	assert.Equal(t, VendorPrune{GoTests: true}, pruning.Find("github.com/gogo/protobuf"))
+
+	var out bytes.Buffer
+	ShowConfig(&out, config)
+	assert.Equal(t, `# Merged from, nearest first:
+#   `+teamFile+`
+#   `+orgFile+`
+
+cache = "https://example.com/cache" # `+orgFile+`
+lockfiles = ["go.mod", "glide.lock"] # `+teamFile+`
+concurrency = 4 # `+orgFile+`
+prereleases = false # `+teamFile+`
+
+[prune]
+go-tests = true # `+orgFile+`
+non-go = true # `+teamFile+`
+
+[[remotes]] # `+teamFile+`
+pattern = "github.com/team/*"
+remote = "https://team.example.com/*"
+
+[[remotes]] # `+orgFile+`
+pattern = "github.com/*/*"
+remote = "https://mirror.example.com/github/*/*"
+gitoliteMirror = true
+
+[[policy]] # `+teamFile+`
+pattern = "go.uber.org/thriftrw"
+mode = "hold"
+
+[[policy]] # `+orgFile+`
+pattern = "go.uber.org/*"
+mode = "minor"
+
+[[packages]] # `+teamFile+`
+package = "git.apache.org/thrift"
+version = "0.10"
+
+[[packages]] # `+teamFile+`
+package = "go.uber.org/yarpc"
+prereleases = true
+
+[[packages]] # `+orgFile+`
+package = "go.uber.org/zap"
+version = "1"
+
+[[excludes]] # `+teamFile+`
+path = "go-build"
+
+[[excludes]] # `+teamFile+`
+path = "node_modules"
+
+[[exclude-version]] # `+orgFile+`
+pattern = "go.uber.org/thriftrw"
+versions = ["v1.10.0", "d8887717"]
+
+[[prune-module]] # `+teamFile+`
+pattern = "github.com/gogo/protobuf"
+non-go = false
+`, out.String())
+}
+
+func TestReadConcurrency(t *testing.T) {
+	concurrency, err := (&Config{}).ReadConcurrency()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConcurrency, concurrency)
+
+	_, err = (&Config{Concurrency: -1}).ReadConcurrency()
+	assert.EqualError(t, err, "cannot read concurrency from gg.toml: expected a positive number, got -1")
+}
+
+func TestReadPoliciesError(t *testing.T) {
+	config := &Config{Policies: []ConfigPolicy{
+		{Pattern: "go.uber.org/*", Mode: "newest", Source: "gg.toml"},
+	}}
+	_, err := config.ReadPolicies()
+	assert.EqualError(t, err, `cannot read policy from gg.toml: unrecognized policy mode "newest" for go.uber.org/*, expected hold, patch, minor, major, or track-branch <branch>`)
+}
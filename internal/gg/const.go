@@ -40,4 +40,7 @@ const (
 	// FetchMaxAttemptWait is the maximum time to wait between attempts to
 	// fetch a package, after exponential back-off has run its course.
 	FetchMaxAttemptWait = time.Minute
+	// ModGoVersion is the Go language version that gg writes in a new go.mod,
+	// the first version of Go to support modules.
+	ModGoVersion = "1.11"
 )
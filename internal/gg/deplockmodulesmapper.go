@@ -40,12 +40,14 @@ func moduleFromDepProject(project DepProject) (Module, error) {
 	} else if project.Branch != "" {
 		ref = "heads/" + project.Branch
 	}
+	version, prerelease := ParseSemver(project.Version)
 	return Module{
-		Name:    project.Name,
-		Version: ParseVersion(project.Version),
-		Ref:     ref,
-		Hash:    plumbing.NewHash(project.Revision),
-		Remote:  project.Source,
+		Name:       project.Name,
+		Version:    version,
+		Prerelease: prerelease,
+		Ref:        ref,
+		Hash:       plumbing.NewHash(project.Revision),
+		Remote:     project.Source,
 	}, nil
 }
 
@@ -70,7 +72,7 @@ func depProjectFromModule(module Module) DepProject {
 	} else if strings.HasPrefix(module.Ref, "tags/") {
 		version = strings.TrimPrefix(module.Ref, "tags/")
 	} else if module.Version != NoVersion {
-		version = "v" + module.Version.String()
+		version = "v" + module.VersionString()
 	}
 	return DepProject{
 		Name:     module.Name,
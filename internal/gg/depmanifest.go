@@ -48,8 +48,41 @@ type DepManifestConstraint struct {
 
 // DepManifestPrune models the prune block in a Gopkg.toml.
 type DepManifestPrune struct {
-	GoTests        bool `toml:"go-tests,omitempty"`
-	UnusedPackages bool `toml:"unused-packages,omitempty"`
+	GoTests        bool                      `toml:"go-tests,omitempty"`
+	UnusedPackages bool                      `toml:"unused-packages,omitempty"`
+	NonGo          bool                      `toml:"non-go,omitempty"`
+	Projects       []DepManifestPruneProject `toml:"project,omitempty"`
+}
+
+// DepManifestPruneProject models the pruning of a particular project in the
+// prune block of a Gopkg.toml, overriding the settings it sets.
+type DepManifestPruneProject struct {
+	Name           string `toml:"name"`
+	GoTests        *bool  `toml:"go-tests,omitempty"`
+	UnusedPackages *bool  `toml:"unused-packages,omitempty"`
+	NonGo          *bool  `toml:"non-go,omitempty"`
+}
+
+// VendorPruning converts the prune block of a Gopkg.toml to vendor pruning
+// rules, the rules for projects before the rule for every project.
+func (prune DepManifestPrune) VendorPruning() VendorPruning {
+	pruning := make(VendorPruning, 0, len(prune.Projects)+1)
+	for _, project := range prune.Projects {
+		pruning = append(pruning, VendorPruneRule{
+			Pattern:        project.Name,
+			Match:          PatternSplit(project.Name),
+			GoTests:        project.GoTests,
+			UnusedPackages: project.UnusedPackages,
+			NonGo:          project.NonGo,
+		})
+	}
+	return append(pruning, VendorPruneRule{
+		Pattern:        "...",
+		Match:          PatternSplit("..."),
+		GoTests:        &prune.GoTests,
+		UnusedPackages: &prune.UnusedPackages,
+		NonGo:          &prune.NonGo,
+	})
 }
 
 // ReadDepManifest reads a Gopkg.toml into a DepManifest model.
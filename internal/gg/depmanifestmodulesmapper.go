@@ -37,9 +37,9 @@ func DepManifestFromModules(manifest *DepManifest, modules Modules, ownPackages
 
 		if module.Version != NoVersion {
 			if module.Version[0] == 0 {
-				constraint.Version = "~" + module.Version.String()
+				constraint.Version = "~" + module.VersionString()
 			} else {
-				constraint.Version = "^" + module.Version.String()
+				constraint.Version = "^" + module.VersionString()
 			}
 		} else if strings.HasPrefix(module.Ref, "heads/") {
 			constraint.Branch = strings.TrimPrefix(module.Ref, "heads/")
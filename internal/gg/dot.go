@@ -0,0 +1,147 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// file dot.go renders the module graph of a solution and the import graph of
+// its packages in the Graphviz DOT language, for plotting with "dot -Tsvg".
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteModuleGraph writes the graph of which modules depend on which in a
+// solution, from the given finished modules of the solution, their locked
+// dependencies, and the dependees of the solution state.
+// Modules needed only for tests are dashed and gray.
+// Edges to missing dependencies, or to versions that may be incompatible with
+// the version a module wants, are red and labeled with the version it wants.
+func WriteModuleGraph(out io.Writer, state *State, modules Modules) {
+	index := modules.Index()
+	edges := make(StringGraph)
+	for _, module := range modules {
+		for _, want := range module.Modules {
+			if _, ok := index[want.Name]; ok {
+				edges.Add(module.Name, want.Name)
+			}
+		}
+	}
+	for name, dependees := range state.Dependees {
+		if _, ok := index[name]; !ok {
+			continue
+		}
+		for dependee := range dependees {
+			if _, ok := index[dependee]; ok {
+				edges.Add(dependee, name)
+			}
+		}
+	}
+
+	missing := make(StringSet)
+	conflicts := make(map[[2]string]Conflict)
+	for _, conflict := range FindConflicts(modules) {
+		if conflict.Missing {
+			missing.Add(conflict.Want.Name)
+		}
+		edges.Add(conflict.Module.Name, conflict.Want.Name)
+		conflicts[[2]string{conflict.Module.Name, conflict.Want.Name}] = conflict
+	}
+
+	fmt.Fprintf(out, "digraph solution {\n")
+	fmt.Fprintf(out, "\trankdir=LR;\n")
+	fmt.Fprintf(out, "\tnode [shape=box];\n")
+	names := modules.Names().Keys()
+	for _, name := range names {
+		module := index[name]
+		attrs := []string{"label=" + dotQuote(name+"\n"+moduleLabel(module))}
+		if module.Test {
+			attrs = append(attrs, "style=dashed", "color=gray50", "fontcolor=gray50")
+		}
+		fmt.Fprintf(out, "\t%s [%s];\n", dotQuote(name), strings.Join(attrs, ", "))
+	}
+	for _, name := range missing.Keys() {
+		fmt.Fprintf(out, "\t%s [label=%s, style=dashed, color=red, fontcolor=red];\n", dotQuote(name), dotQuote(name+"\n(missing)"))
+	}
+	for _, src := range edges.Sources() {
+		for _, tgt := range edges[src].Keys() {
+			conflict, ok := conflicts[[2]string{src, tgt}]
+			if !ok {
+				fmt.Fprintf(out, "\t%s -> %s;\n", dotQuote(src), dotQuote(tgt))
+				continue
+			}
+			label := moduleLabel(conflict.Want)
+			if !conflict.Missing {
+				label = "wants " + label
+			}
+			fmt.Fprintf(out, "\t%s -> %s [color=red, fontcolor=red, penwidth=2, label=%s];\n", dotQuote(src), dotQuote(tgt), dotQuote(label))
+		}
+	}
+	fmt.Fprintf(out, "}\n")
+}
+
+// WritePackageGraph writes the import graph among the packages with the given
+// prefix.
+// Commands are drawn as ellipses and other packages as boxes.
+func WritePackageGraph(out io.Writer, packages Packages, prefix string) {
+	var names []string
+	for _, pkg := range packages.All.Keys() {
+		if strings.HasPrefix(pkg, prefix) {
+			names = append(names, pkg)
+		}
+	}
+
+	fmt.Fprintf(out, "digraph packages {\n")
+	fmt.Fprintf(out, "\trankdir=LR;\n")
+	fmt.Fprintf(out, "\tnode [shape=box];\n")
+	for _, pkg := range names {
+		if packages.Commands.Has(pkg) {
+			fmt.Fprintf(out, "\t%s [shape=ellipse];\n", dotQuote(pkg))
+		} else {
+			fmt.Fprintf(out, "\t%s;\n", dotQuote(pkg))
+		}
+	}
+	for _, pkg := range names {
+		for _, imp := range packages.Imports[pkg].Keys() {
+			if strings.HasPrefix(imp, prefix) {
+				fmt.Fprintf(out, "\t%s -> %s;\n", dotQuote(pkg), dotQuote(imp))
+			}
+		}
+	}
+	fmt.Fprintf(out, "}\n")
+}
+
+// moduleLabel returns the version of a module for a label, or the reference,
+// or an abbreviated hash.
+func moduleLabel(module Module) string {
+	if module.Version != NoVersion {
+		return module.VersionString()
+	}
+	if module.Ref != "" {
+		return module.Ref
+	}
+	if module.Hash != NoHash {
+		return module.Hash.String()[:8]
+	}
+	return ""
+}
+
+// dotEscaper escapes the characters that are special in a DOT quoted string,
+// rendering newlines as centered line breaks.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func dotQuote(str string) string {
+	return `"` + dotEscaper.Replace(str) + `"`
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteModuleGraph(t *testing.T) {
+	alpha := Module{Name: "example.com/alpha", Hash: averyHash, Version: Version{1, 0, 0}, Ref: "tags/v1.0.0"}
+	beta := Module{Name: "example.com/beta", Hash: blakeHash, Ref: "heads/master", Test: true}
+	wantAlpha := Module{Name: "example.com/alpha", Hash: drewHash, Version: Version{2, 0, 0}, Ref: "tags/v2.0.0"}
+	gamma := Module{
+		Name:    "example.com/gamma",
+		Hash:    careyHash,
+		Modules: Modules{wantAlpha, {Name: "example.com/delta", Ref: "heads/master"}},
+	}
+
+	state := NewState()
+	state.Solution["example.com/alpha"] = Partial{Module: alpha}
+	state.Solution["example.com/beta"] = Partial{Module: beta}
+	state.Solution["example.com/gamma"] = Partial{Module: gamma}
+	state.Dependees.Add("example.com/beta", "example.com/gamma")
+
+	var out bytes.Buffer
+	WriteModuleGraph(&out, state, state.Modules())
+	assert.Equal(t, `digraph solution {
+	rankdir=LR;
+	node [shape=box];
+	"example.com/alpha" [label="example.com/alpha\n1.0.0"];
+	"example.com/beta" [label="example.com/beta\nheads/master", style=dashed, color=gray50, fontcolor=gray50];
+	"example.com/gamma" [label="example.com/gamma\na28ced3c"];
+	"example.com/delta" [label="example.com/delta\n(missing)", style=dashed, color=red, fontcolor=red];
+	"example.com/gamma" -> "example.com/alpha" [color=red, fontcolor=red, penwidth=2, label="wants 2.0.0"];
+	"example.com/gamma" -> "example.com/beta";
+	"example.com/gamma" -> "example.com/delta" [color=red, fontcolor=red, penwidth=2, label="heads/master"];
+}
+`, out.String())
+}
+
+func TestWritePackageGraph(t *testing.T) {
+	packages := NewPackages()
+	packages.Command("example.com/alpha/cmd/alpha")
+	packages.Import("example.com/alpha/cmd/alpha", "example.com/alpha")
+	packages.Import("example.com/alpha", "example.com/beta")
+	packages.Import("example.com/alpha", "example.com/alpha/internal/\"quoted\"")
+
+	var out bytes.Buffer
+	WritePackageGraph(&out, packages, "example.com/alpha")
+	assert.Equal(t, `digraph packages {
+	rankdir=LR;
+	node [shape=box];
+	"example.com/alpha";
+	"example.com/alpha/cmd/alpha" [shape=ellipse];
+	"example.com/alpha/internal/\"quoted\"";
+	"example.com/alpha" -> "example.com/alpha/internal/\"quoted\"";
+	"example.com/alpha/cmd/alpha" -> "example.com/alpha";
+}
+`, out.String())
+}
@@ -40,6 +40,14 @@ type Driver struct {
 	out     io.Writer
 	err     *Progress
 
+	// lockfile is the name of the kind of lockfile most recently read, which
+	// the write command regenerates.
+	lockfile string
+
+	// format is the output format for reports, one of FormatText, FormatJSON,
+	// or FormatTSV.
+	format string
+
 	commands  map[string]Command
 	help      map[string]UsageError
 	completer readline.AutoCompleter
@@ -49,12 +57,13 @@ type Driver struct {
 func NewDriver(memo *Memo, in io.Reader, out, errout io.Writer) (*Driver, error) {
 	pout, perr := NewProgress(out, errout)
 	driver := &Driver{
-		memo: memo,
-		prev: NewState(),
-		next: NewState(),
-		in:   in,
-		out:  pout,
-		err:  perr,
+		memo:   memo,
+		prev:   NewState(),
+		next:   NewState(),
+		in:     in,
+		out:    pout,
+		err:    perr,
+		format: FormatText,
 	}
 
 	commands, help, completer := AssembleCommands(driver, commands())
@@ -99,18 +108,35 @@ func (driver *Driver) ExecuteArguments(ctx context.Context, args ...string) erro
 		return nil
 	}
 
+	// Modes like "format json" may precede a sole command without changing
+	// the workflow it implies.
+	prefix := 0
+	for prefix < len(args) && driver.commands[args[prefix]].Prefix {
+		if driver.commands[args[prefix]].Monadic != nil {
+			prefix++
+		}
+		prefix++
+	}
+	if prefix > len(args) {
+		prefix = len(args)
+	}
+	modes, rest := args[:prefix], args[prefix:]
+
 	// Imply a read and write for some commands.
 	// For example, "up", as a sole argument, is an alias for the read, update,
 	// and rewrite workflow.  "up" as a part of a workflow, only upgrades the
 	// state.
-	command := driver.commands[args[0]]
-	if (len(args) == 1 && command.Niladic != nil) || (len(args) == 2 && command.Monadic != nil) {
-		if command.Write {
-			args = append([]string{"read"}, append(args, "write")...)
-		} else if command.Read {
-			args = append([]string{"read-only"}, args...)
+	if len(rest) > 0 {
+		command := driver.commands[rest[0]]
+		if (len(rest) == 1 && command.Niladic != nil) || (len(rest) == 2 && command.Monadic != nil) {
+			if command.Write {
+				rest = append([]string{"read"}, append(rest, "write")...)
+			} else if command.Read {
+				rest = append([]string{"read-only"}, rest...)
+			}
 		}
 	}
+	args = append(append([]string{}, modes...), rest...)
 
 	// Execute command line flags in order, capturing the next flag as an
 	// argument if the flagged command takes an argument.
@@ -197,12 +223,18 @@ func (driver *Driver) SuggestPackages(_ string) []string {
 	return driver.Packages(context.Background()).All.Keys()
 }
 
-// SuggestModules auto-completes module names from known modules in the memo.
+// SuggestModules auto-completes module names from known modules in the memo,
+// and abbreviated commit hashes from modules in the solution.
 func (driver *Driver) SuggestModules(_ string) []string {
-	packages := make([]string, 0, len(driver.memo.Remotes))
+	packages := make([]string, 0, len(driver.memo.Remotes)+len(driver.next.Solution))
 	for name := range driver.memo.Remotes {
 		packages = append(packages, name)
 	}
+	for _, solution := range driver.next.Solution {
+		if solution.Module.Hash != NoHash {
+			packages = append(packages, solution.Module.Hash.String()[:8])
+		}
+	}
 	return packages
 }
 
@@ -216,16 +248,57 @@ func (driver *Driver) Packages(ctx context.Context) Packages {
 	return packages
 }
 
+// VendorPruner returns the pruner for the vendor checkout of the given
+// modules, following the pruning in gg.toml, then the prune block of the
+// working copy's Gopkg.toml, if any.
+// The pruner only reads the package graph if some module prunes unused
+// packages.
+func (driver *Driver) VendorPruner(ctx context.Context, modules Modules) (VendorPruner, error) {
+	pruning := driver.memo.VendorPruning
+	manifest, err := ReadOwnDepManifest()
+	if err == nil {
+		pruning = pruning.Merge(manifest.Prune.VendorPruning())
+	} else if !os.IsNotExist(err) {
+		return VendorPruner{}, fmt.Errorf("cannot read Gopkg.toml: %s", err)
+	}
+
+	pruner := VendorPruner{Pruning: pruning}
+	if !pruning.PrunesUnusedPackages(modules) {
+		return pruner, nil
+	}
+
+	_, ownPackages, err := driver.memo.ReadOwnPackages(ctx, driver.err)
+	if err != nil {
+		return VendorPruner{}, err
+	}
+	if err := driver.memo.FinishPackages(ctx, driver.err, modules); err != nil {
+		return VendorPruner{}, err
+	}
+	packages := modules.Packages()
+	packages.Include(ownPackages)
+	imports, testImports := NecessaryPackages(ownPackages, packages)
+	imports.Include(testImports)
+	pruner.Packages = imports
+	return pruner, nil
+}
+
 // FindSolutionOrExpressModule is a utility function for workflows that need to
 // infer the version of a module the user expressed or implied, either by the
 // versioned expressed after the "@" symbol in a package name, or implied by
-// the version present in the next solution.
+// the version present in the next solution, or by an abbreviated commit hash
+// in the place of the package name.
 func (driver *Driver) FindSolutionOrExpressModule(ctx context.Context, name string, test bool) (Module, error) {
 	memo := driver.memo
 	state := driver.next
 
 	var module Module
-	if strings.Index(name, "@") >= 0 {
+	if IsHashPrefix(name) {
+		found, err := driver.FindHashPrefixModule(ctx, name, test)
+		if err != nil {
+			return module, err
+		}
+		module = found
+	} else if strings.Index(name, "@") >= 0 {
 		found, err := memo.FindModule(ctx, driver.err, name, false)
 		if err != nil {
 			return module, err
@@ -242,6 +315,55 @@ func (driver *Driver) FindSolutionOrExpressModule(ctx context.Context, name stri
 	return module, nil
 }
 
+// FindHashPrefixModule finds the module with a commit hash that begins with
+// the given prefix, as one might copy from a build log.
+// The module may be in the next solution, or otherwise among the versions of
+// any module in the memo.
+// An abbreviated hash that matches more than one module is an error.
+func (driver *Driver) FindHashPrefixModule(ctx context.Context, prefix string, test bool) (Module, error) {
+	min, max := ParseHashPrefix(prefix)
+
+	modules := driver.next.Modules().FilterHash(min, max)
+	if len(modules) == 0 {
+		modules = driver.memo.FindCachedHash(min, max, test)
+	}
+
+	switch len(modules) {
+	case 0:
+		return Module{}, fmt.Errorf("cannot find a module with a commit hash beginning with %s in the solution or the cache", prefix)
+	case 1:
+	default:
+		summaries := make([]string, 0, len(modules))
+		for _, module := range modules {
+			summaries = append(summaries, module.Summary())
+		}
+		return Module{}, fmt.Errorf("commit hash prefix %s is ambiguous, matching modules %s", prefix, strings.Join(summaries, ", "))
+	}
+
+	module := modules[0]
+	if !module.Finished {
+		if err := driver.memo.FinishModule(ctx, driver.err, &module); err != nil {
+			return module, fmt.Errorf("cannot read module %s: %s", module.Summary(), err)
+		}
+	}
+	module.Test = test
+	return module, nil
+}
+
+// Lockfile returns the kind of lockfile for the write command to regenerate:
+// the kind most recently read, or the first that exists in the working copy
+// in order of precedence, or glide.lock.
+func (driver *Driver) Lockfile() Lockfile {
+	if lockfile, err := FindLockfile(driver.lockfile); err == nil {
+		return lockfile
+	}
+	if lockfile, ok := FindOwnLockfile(driver.memo.Lockfiles); ok {
+		return lockfile
+	}
+	lockfile, _ := FindLockfile(DefaultLockfile)
+	return lockfile
+}
+
 func (driver *Driver) push(state *State) {
 	driver.next = state
 	driver.history = append(driver.history, state)
@@ -0,0 +1,73 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+func TestFindHashPrefixModule(t *testing.T) {
+	careyHash := plumbing.NewHash("abc1000000000000000000000000000000000000")
+	emeryHash := plumbing.NewHash("abc2000000000000000000000000000000000000")
+	carey := Module{
+		Name:     "carey",
+		Root:     "carey",
+		Version:  Version{1, 0, 0},
+		Hash:     careyHash,
+		Finished: true,
+	}
+
+	driver := &Driver{
+		next: NewState(),
+		memo: &Memo{
+			Versions: map[string][]plumbing.Hash{
+				// A tag and a branch point to the same commit.
+				"carey": {careyHash, careyHash},
+				"emery": {emeryHash},
+			},
+			FinishedVersions: map[string]Modules{
+				"carey": {carey},
+			},
+			Finished: map[plumbing.Hash]ModuleResult{
+				careyHash: {Module: carey},
+			},
+		},
+	}
+	ctx := context.Background()
+
+	t.Run("none", func(t *testing.T) {
+		_, err := driver.FindHashPrefixModule(ctx, "0123", false)
+		assert.EqualError(t, err, "cannot find a module with a commit hash beginning with 0123 in the solution or the cache")
+	})
+
+	t.Run("one", func(t *testing.T) {
+		module, err := driver.FindHashPrefixModule(ctx, "abc1", true)
+		require.NoError(t, err)
+		want := carey
+		want.Test = true
+		assert.Equal(t, want, module)
+	})
+
+	t.Run("ambiguous", func(t *testing.T) {
+		_, err := driver.FindHashPrefixModule(ctx, "abc", false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "commit hash prefix abc is ambiguous")
+	})
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// file exclusions.go keeps versions of modules that are known to be broken out
+// of consideration, as configured with [[exclude-version]] in gg.toml.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Exclusion rules out versions of the modules with names that match a
+// pattern.
+type Exclusion struct {
+	// Pattern is the module name pattern, as written in gg.toml.
+	Pattern string
+	// Match is the pattern split into components.
+	Match []string
+	// Versions are the excluded versions, references, or commit hash
+	// prefixes, like "v1.10.0", "heads/broken", or "d8887717".
+	Versions []string
+	// Source is the gg.toml this exclusion came from.
+	Source string
+}
+
+// Exclusions is a list of version exclusions.
+type Exclusions []Exclusion
+
+// Reason explains which exclusion rules out a version, for reports.
+func (exclusion Exclusion) Reason() string {
+	return fmt.Sprintf("exclude-version for %s in %s", exclusion.Pattern, exclusion.Source)
+}
+
+// Find returns the first exclusion that rules out a module, and whether there
+// is one.
+func (exclusions Exclusions) Find(module Module) (Exclusion, bool) {
+	for _, exclusion := range exclusions {
+		if !(Pattern{Match: exclusion.Match}).Matches(module.Name) {
+			continue
+		}
+		for _, version := range exclusion.Versions {
+			if excludesVersion(version, module) {
+				return exclusion, true
+			}
+		}
+	}
+	return Exclusion{}, false
+}
+
+// Excludes returns whether any exclusion rules out a module.
+func (exclusions Exclusions) Excludes(module Module) bool {
+	_, ok := exclusions.Find(module)
+	return ok
+}
+
+// ExcludesSpec returns whether any exclusion names exactly the version,
+// reference, or hash prefix that the user asked for, as in name@spec, and the
+// exclusion, since asking for an excluded version is surely a mistake.
+func (exclusions Exclusions) ExcludesSpec(name, spec string) (Exclusion, bool) {
+	version, prerelease := ParseSemver(spec)
+	for _, exclusion := range exclusions {
+		if !(Pattern{Match: exclusion.Match}).Matches(name) {
+			continue
+		}
+		for _, excluded := range exclusion.Versions {
+			if excluded == spec {
+				return exclusion, true
+			}
+			if v, p := ParseSemver(excluded); version != NoVersion && v == version && p == prerelease {
+				return exclusion, true
+			}
+		}
+	}
+	return Exclusion{}, false
+}
+
+// Filter returns the modules that no exclusion rules out.
+func (exclusions Exclusions) Filter(modules Modules) Modules {
+	if len(exclusions) == 0 {
+		return modules
+	}
+	filtered := make(Modules, 0, len(modules))
+	for _, module := range modules {
+		if !exclusions.Excludes(module) {
+			filtered = append(filtered, module)
+		}
+	}
+	return filtered
+}
+
+// Next returns the oldest version of a module among the given versions that
+// the module can upgrade to and no exclusion rules out, and whether there is
+// one.
+// Next passes over pre-releases unless the module is itself a pre-release.
+func (exclusions Exclusions) Next(versions Modules, module Module) (Module, bool) {
+	next, ok := module, false
+	for _, version := range versions {
+		if version.Prerelease != NoPrerelease && module.Prerelease == NoPrerelease {
+			continue
+		}
+		if module.CanUpgradeTo(version) && !exclusions.Excludes(version) && (!ok || version.Before(next)) {
+			next, ok = version, true
+		}
+	}
+	return next, ok
+}
+
+// excludesVersion returns whether a version, reference, or hash prefix from
+// an exclusion matches a module.
+// A string that parses as a version, like "1", names that version, not a hash
+// prefix.
+func excludesVersion(excluded string, module Module) bool {
+	if version, prerelease := ParseSemver(excluded); version != NoVersion {
+		return module.Version == version && module.Prerelease == prerelease
+	}
+	if min, max := ParseHashPrefix(excluded); excluded != "" && max != NoHash && HashBetween(min, module.Hash, max) {
+		return true
+	}
+	if module.Ref == excluded || strings.HasSuffix(module.Ref, "/"+excluded) {
+		return true
+	}
+	for _, ref := range module.Refs {
+		if ref == excluded || strings.HasSuffix(ref, "/"+excluded) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExclusionLoader reads the versions of modules, to find replacements for
+// excluded versions.
+type ExclusionLoader interface {
+	ReadVersions(context.Context, ProgressWriter, Module) (Modules, error)
+}
+
+// ReplaceExcluded returns the given modules, with every version that an
+// exclusion rules out replaced by the next acceptable version, reporting each
+// replacement.
+// The given modules are not modified, since they are typically the
+// dependencies of a module that other states share.
+// If there is no acceptable version, the excluded version remains, and
+// ReplaceExcluded reports that instead.
+func ReplaceExcluded(ctx context.Context, loader ExclusionLoader, out ProgressWriter, modules Modules, exclusions Exclusions) Modules {
+	var replaced Modules
+	for i, module := range modules {
+		exclusion, ok := exclusions.Find(module)
+		if !ok {
+			continue
+		}
+		versions, err := loader.ReadVersions(ctx, out, module)
+		if err != nil {
+			fmt.Fprintf(out, "Cannot replace %s, excluded by the %s: %s\n", module.Summary(), exclusion.Reason(), err)
+			continue
+		}
+		next, ok := exclusions.Next(versions, module)
+		if !ok {
+			fmt.Fprintf(out, "Keeping %s, excluded by the %s, since there is no acceptable version after it.\n", module.Summary(), exclusion.Reason())
+			continue
+		}
+		next.Test = module.Test
+		fmt.Fprintf(out, "Replacing %s, excluded by the %s, with %s.\n", module.Summary(), exclusion.Reason(), next.Summary())
+		if replaced == nil {
+			replaced = append(Modules(nil), modules...)
+		}
+		replaced[i] = next
+	}
+	if replaced == nil {
+		return modules
+	}
+	return replaced
+}
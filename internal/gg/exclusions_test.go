@@ -0,0 +1,175 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+func TestExclusionsFind(t *testing.T) {
+	exclusions := Exclusions{
+		{
+			Pattern:  "go.uber.org/thriftrw",
+			Match:    PatternSplit("go.uber.org/thriftrw"),
+			Versions: []string{"v1.10.0", "d8887717", "heads/broken", "v1.11.0-rc.1"},
+			Source:   "gg.toml",
+		},
+	}
+	tests := []struct {
+		msg    string
+		module Module
+		want   bool
+	}{
+		{
+			msg:    "excluded version",
+			module: Module{Name: "go.uber.org/thriftrw", Version: Version{1, 10, 0}},
+			want:   true,
+		},
+		{
+			msg:    "other version",
+			module: Module{Name: "go.uber.org/thriftrw", Version: Version{1, 10, 1}},
+			want:   false,
+		},
+		{
+			msg:    "excluded pre-release",
+			module: Module{Name: "go.uber.org/thriftrw", Version: Version{1, 11, 0}, Prerelease: "rc.1"},
+			want:   true,
+		},
+		{
+			msg:    "final release of excluded pre-release",
+			module: Module{Name: "go.uber.org/thriftrw", Version: Version{1, 11, 0}},
+			want:   false,
+		},
+		{
+			msg:    "excluded hash prefix",
+			module: Module{Name: "go.uber.org/thriftrw", Hash: plumbing.NewHash("d888771761500000000000000000000000000000")},
+			want:   true,
+		},
+		{
+			msg:    "excluded reference",
+			module: Module{Name: "go.uber.org/thriftrw", Ref: "tags/v0", Refs: []string{"heads/broken", "tags/v0"}},
+			want:   true,
+		},
+		{
+			msg:    "other module",
+			module: Module{Name: "go.uber.org/zap", Version: Version{1, 10, 0}},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			assert.Equal(t, tt.want, exclusions.Excludes(tt.module))
+		})
+	}
+}
+
+func TestExclusionsExcludesSpec(t *testing.T) {
+	exclusions := Exclusions{
+		{Pattern: "example.com/*", Match: PatternSplit("example.com/*"), Versions: []string{"v1.10.0", "d8887717"}},
+	}
+	_, ok := exclusions.ExcludesSpec("example.com/x", "1.10.0")
+	assert.True(t, ok)
+	_, ok = exclusions.ExcludesSpec("example.com/x", "d8887717")
+	assert.True(t, ok)
+	_, ok = exclusions.ExcludesSpec("example.com/x", "v1.10")
+	assert.True(t, ok)
+	_, ok = exclusions.ExcludesSpec("example.com/x", "v1.10.1")
+	assert.False(t, ok)
+	_, ok = exclusions.ExcludesSpec("other.com/x", "v1.10.0")
+	assert.False(t, ok)
+}
+
+func TestReplaceExcluded(t *testing.T) {
+	ctx := context.Background()
+	loader := NewFakeLoader(Modules{
+		{Name: "avery", Version: Version{1, 0, 0}},
+		{Name: "avery", Version: Version{1, 0, 1}},
+		{Name: "avery", Version: Version{1, 1, 0}, Prerelease: "rc.1"},
+		{Name: "avery", Version: Version{1, 1, 0}},
+		{Name: "avery", Version: Version{2, 0, 0}},
+		{Name: "blake", Version: Version{1, 0, 0}},
+		{Name: "blake", Version: Version{2, 0, 0}},
+		{Name: "casey", Ref: "heads/master", Time: time.Unix(1, 0)},
+	})
+	exclusions := Exclusions{
+		{Pattern: "avery", Match: PatternSplit("avery"), Versions: []string{"v1.0.0", "v1.0.1"}},
+		{Pattern: "blake", Match: PatternSplit("blake"), Versions: []string{"v1"}},
+	}
+
+	modules := Modules{
+		loader.MustGetVersion("avery", Version{1, 0, 0}),
+		loader.MustGetVersion("blake", Version{1, 0, 0}),
+		loader.MustGetTime("casey", time.Unix(1, 0)),
+	}
+	modules[0].Test = true
+	replaced := ReplaceExcluded(ctx, loader, &LogSolverProgress{}, modules, exclusions)
+
+	// There is no version of blake after 1.0.0 in its range, so it remains.
+	var summaries []string
+	for _, module := range replaced {
+		summaries = append(summaries, module.Summary())
+	}
+	assert.Equal(t, []string{"avery@1.1.0#test", "blake@1.0.0", "casey@heads/master"}, summaries)
+	// The given modules are left as they were.
+	assert.Equal(t, Version{1, 0, 0}, modules[0].Version)
+}
+
+type excludingLoader struct {
+	FakeLoader
+	exclusions Exclusions
+}
+
+func (l excludingLoader) ReplaceExcluded(ctx context.Context, out ProgressWriter, modules Modules) Modules {
+	return ReplaceExcluded(ctx, l.FakeLoader, out, modules, l.exclusions)
+}
+
+func TestSolveReplacesExcluded(t *testing.T) {
+	ctx := context.Background()
+	loader := excludingLoader{
+		FakeLoader: NewFakeLoader(Modules{
+			{
+				Name:    "avery",
+				Version: Version{1, 0, 0},
+				Modules: Modules{
+					{Name: "blake", Version: Version{1, 2, 0}},
+				},
+			},
+			{Name: "blake", Version: Version{1, 2, 0}},
+			{Name: "blake", Version: Version{1, 2, 1}},
+			{Name: "blake", Version: Version{1, 3, 0}},
+		}),
+		exclusions: Exclusions{
+			{Pattern: "blake", Match: PatternSplit("blake"), Versions: []string{"1.2.0"}},
+		},
+	}
+	progress := &LogSolverProgress{}
+
+	state, err := NewState().Constrain(ctx, loader, progress, Modules{{Name: "avery", Version: Version{1, 0, 0}}}, false)
+	require.NoError(t, err)
+	state, err = state.Solve(ctx, loader, progress)
+	require.NoError(t, err)
+
+	assert.True(t, Modules{
+		loader.MustGetVersion("avery", Version{1, 0, 0}),
+		loader.MustGetVersion("blake", Version{1, 2, 1}),
+	}.Equal(state.Modules()))
+}
@@ -44,6 +44,15 @@ func (l FakeLoader) MustGetVersion(name string, version Version) Module {
 	return module
 }
 
+func (l FakeLoader) MustGetPrerelease(name string, version Version, prerelease Prerelease) Module {
+	module, ok := l[Module{Name: name, Version: version, Prerelease: prerelease}.LoaderHash()]
+	if !ok {
+		return Module{}
+	}
+	module.Finished = true
+	return module
+}
+
 func (l FakeLoader) MustGetTestVersion(name string, version Version) Module {
 	module, ok := l[Module{Name: name, Version: version}.LoaderHash()]
 	if !ok {
@@ -75,7 +84,7 @@ func (l FakeLoader) FinishModules(ctx context.Context, out ProgressWriter, modul
 			continue
 		}
 		if err := l.FinishModule(ctx, out, &modules[i]); err != nil {
-			return err
+			modules[i].FinishError = err
 		}
 	}
 	return nil
@@ -94,6 +103,10 @@ func (l FakeLoader) FinishModule(ctx context.Context, out ProgressWriter, module
 	return l.FinishModules(ctx, out, module.Modules)
 }
 
+func (l FakeLoader) ReplaceExcluded(_ context.Context, _ ProgressWriter, modules Modules) Modules {
+	return modules
+}
+
 func (l FakeLoader) FinishPackages(context.Context, ProgressWriter, Modules) error { return nil }
 
 func (l FakeLoader) Fetch(context.Context, ProgressWriter, *Module, int) error { return nil }
@@ -113,6 +126,8 @@ func (l FakeLoader) ReadVersions(_ context.Context, _ ProgressWriter, module Mod
 	return versions, nil
 }
 
+func (l FakeLoader) PrefetchVersions(context.Context, ProgressWriter, Modules) {}
+
 type LogSolverProgress struct{}
 
 func (p *LogSolverProgress) Write(b []byte) (int, error) {
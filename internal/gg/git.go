@@ -27,22 +27,39 @@
 package gg
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"sort"
+	"strings"
 	"time"
+
+	"go.uber.org/multierr"
+	"gopkg.in/src-d/go-git.v4/plumbing"
 )
 
 // GitFetchRootRemote fetches all of the tags and branches corresponding to a
 // dependency with the given remote URL and "root" (remote repository cache
 // key).
-func GitFetchRootRemote(out io.Writer, gitDir string, root, remoteURL string) error {
+// We follow submodules ourselves, and must not fetch the submodules of the
+// project if the cache is the project's own git repository.
+func GitFetchRootRemote(out io.Writer, gitDir string, root, remoteURL string) (err error) {
+	unlock, err := LockGitDir(gitDir, false)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Append(err, unlock())
+	}()
+
 	cmd := exec.Command(
 		"git", "fetch", remoteURL,
 		"+refs/heads/*:refs/vendor/"+root+"/heads/*",
 		"+refs/tags/*:refs/vendor/"+root+"/tags/*",
 		"-f", "--no-tags",
-		"--recurse-submodules",
+		"--no-recurse-submodules",
 	)
 	cmd.Env = GitEnv(gitDir)
 	cmd.Stdin = os.Stdin
@@ -53,12 +70,20 @@ func GitFetchRootRemote(out io.Writer, gitDir string, root, remoteURL string) er
 
 // GitPullVendorCache fetches all of the vendor references from the remote refs
 // cache.
-func GitPullVendorCache(out io.Writer, gitDir string, remoteURL string) error {
+func GitPullVendorCache(out io.Writer, gitDir string, remoteURL string) (err error) {
+	unlock, err := LockGitDir(gitDir, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Append(err, unlock())
+	}()
+
 	cmd := exec.Command(
 		"git", "fetch", remoteURL,
 		"+refs/vendor/*:refs/vendor/*",
 		"-f", "--no-tags",
-		"--recurse-submodules",
+		"--no-recurse-submodules",
 	)
 	cmd.Env = GitEnv(gitDir)
 	cmd.Stdin = os.Stdin
@@ -78,22 +103,85 @@ func GitPushVendorCache(out io.Writer, gitDir string, remoteURL string) error {
 	return cmd.Run()
 }
 
+// GitResolveHashPrefix finds the commit for an abbreviated commit hash in the
+// cache, as appears in a go.mod pseudo-version.
+// The go-git library does not resolve abbreviated hashes, so we ask git.
+func GitResolveHashPrefix(out io.Writer, gitDir string, prefix string) (plumbing.Hash, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", prefix+"^{commit}")
+	cmd.Env = GitEnv(gitDir)
+	cmd.Stdout = &stdout
+	cmd.Stderr = out
+	if err := cmd.Run(); err != nil {
+		return NoHash, fmt.Errorf("cannot find a unique commit for hash prefix %s: %s", prefix, err)
+	}
+	return plumbing.NewHash(strings.TrimSpace(stdout.String())), nil
+}
+
 // Checkout builds a vendor directory from the given modules in the git commit
-// stage and then checks it out.
-func Checkout(out ProgressWriter, gitDir string, modules Modules) error {
-	env := GitEnv(gitDir)
+// stage of the given index file and then checks it out.
+//
+// Checkout stages the new solution in a scratch index and writes its tree,
+// then moves the stage of the working copy from the previously staged
+// solution to the new tree, the way git moves between commits.
+// Git writes only the files that the new solution adds or changes, or that
+// differ on disk from the previous stage, and removes the files of modules
+// that the new solution drops or changes, so the unchanged files of unchanged
+// modules keep their modification times.
+// Checkout then removes any file in vendor that belongs to no module.
+// The pruner decides which files of each module to leave out.
+func Checkout(out ProgressWriter, gitDir, indexFile string, modules Modules, pruner VendorPruner) error {
+	tree, err := stageVendorTree(out, gitDir, indexFile+".next", modules, pruner)
+	if err != nil {
+		return err
+	}
 
-	cmd := exec.Command("git", "read-tree", "--empty")
+	env := GitIndexEnv(gitDir, indexFile)
+
+	out.Start("Writing staged vendor")
+	cmd := exec.Command("git", "read-tree", "--reset", "-u", tree)
 	cmd.Env = env
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = out
 	cmd.Stderr = out
+	err = cmd.Run()
+	out.Stop("Writing staged vendor")
+	if err != nil {
+		return err
+	}
+
+	out.Start("Removing stale vendor")
+	cmd = exec.Command("git", "clean", "-ffdxq", "--", "vendor")
 	cmd.Env = env
-	err := cmd.Run()
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err = cmd.Run()
+	out.Stop("Removing stale vendor")
 	if err != nil {
 		return err
 	}
 
+	return nil
+}
+
+// stageVendorTree stages the vendor directory of the given modules in the
+// given scratch index file, less the files that the pruner leaves out, writes
+// the tree of the stage to the cache, and returns the hash of the tree.
+func stageVendorTree(out ProgressWriter, gitDir, indexFile string, modules Modules, pruner VendorPruner) (string, error) {
+	env := GitIndexEnv(gitDir, indexFile)
+	defer os.Remove(indexFile)
+
+	cmd := exec.Command("git", "read-tree", "--empty")
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err := cmd.Run()
+	if err != nil {
+		return "", err
+	}
+
 	start := time.Now()
 	for i, mod := range modules {
 		cmd := exec.Command(
@@ -108,45 +196,146 @@ func Checkout(out ProgressWriter, gitDir string, modules Modules) error {
 		cmd.Stderr = out
 		err = cmd.Run()
 		if err != nil {
-			return err
+			return "", err
 		}
 		out.Progress("Staging modules", i+1, len(modules), start, time.Now())
 	}
 
-	// TODO look into using checkout-index flags to preserve vendor/.git and
-	// avoid nuking it outright.
-	out.Start("Removing stale vendor")
-	cmd = exec.Command("rm", "-rf", "vendor")
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = out
-	cmd.Stderr = out
-	err = cmd.Run()
-	out.Stop("Removing stale vendor")
+	out.Start("Staging submodules")
+	err = stageGitSubmodules(out, env)
+	out.Stop("Staging submodules")
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	out.Start("Writing staged vendor")
-	cmd = exec.Command("git", "checkout-index", "-af")
+	out.Start("Pruning staged vendor")
+	err = pruneStagedVendor(out, env, modules, pruner)
+	out.Stop("Pruning staged vendor")
+	if err != nil {
+		return "", err
+	}
+
+	var stdout bytes.Buffer
+	cmd = exec.Command("git", "write-tree")
 	cmd.Env = env
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = out
+	cmd.Stdout = &stdout
 	cmd.Stderr = out
-	err = cmd.Run()
-	out.Stop("Writing staged vendor")
-	if err != nil {
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// pruneStagedVendor removes the files that the pruner leaves out from the
+// stage.
+func pruneStagedVendor(out ProgressWriter, env []string, modules Modules, pruner VendorPruner) error {
+	var stdout bytes.Buffer
+	cmd := exec.Command("git", "ls-files", "-z")
+	cmd.Env = env
+	cmd.Stdout = &stdout
+	cmd.Stderr = out
+	if err := cmd.Run(); err != nil {
 		return err
 	}
 
-	return nil
+	// Every staged file belongs to the module with the longest name that
+	// prefixes its path.
+	names := make([]string, 0, len(modules))
+	for _, module := range modules {
+		names = append(names, module.Name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return len(names[i]) > len(names[j])
+	})
+
+	var remove bytes.Buffer
+	for _, path := range strings.Split(stdout.String(), "\x00") {
+		rest := strings.TrimPrefix(path, "vendor/")
+		for _, name := range names {
+			if strings.HasPrefix(rest, name+"/") {
+				if !pruner.Keep(name, strings.TrimPrefix(rest, name+"/")) {
+					remove.WriteString(path)
+					remove.WriteByte(0)
+				}
+				break
+			}
+		}
+	}
+	if remove.Len() == 0 {
+		return nil
+	}
+
+	cmd = exec.Command("git", "update-index", "-z", "--force-remove", "--stdin")
+	cmd.Env = env
+	cmd.Stdin = &remove
+	cmd.Stdout = out
+	cmd.Stderr = out
+	return cmd.Run()
 }
 
-// GitEnv creates an os environment for git commands that manipulate the .gg
-// bare repository cache of dependency repositories.
+// stageGitSubmodules replaces every submodule in the stage with the tree of
+// its commit, until none remain, so that checkout writes the files of
+// submodules, and their submodules, under the vendor prefix of their module.
+// The submodule commits must be in the cache, as Memo.FetchSubmodules ensures.
+func stageGitSubmodules(out ProgressWriter, env []string) error {
+	for {
+		var stdout bytes.Buffer
+		cmd := exec.Command("git", "ls-files", "--stage", "-z")
+		cmd.Env = env
+		cmd.Stdout = &stdout
+		cmd.Stderr = out
+		if err := cmd.Run(); err != nil {
+			return err
+		}
+
+		var found bool
+		for _, line := range strings.Split(stdout.String(), "\x00") {
+			// Each line has the form "<mode> <hash> <stage>\t<path>".
+			if !strings.HasPrefix(line, "160000 ") {
+				continue
+			}
+			parts := strings.SplitN(line, "\t", 2)
+			fields := strings.Fields(parts[0])
+			if len(parts) != 2 || len(fields) != 3 {
+				continue
+			}
+			hash, path := fields[1], parts[1]
+			found = true
+
+			cmd := exec.Command("git", "update-index", "--force-remove", path)
+			cmd.Env = env
+			cmd.Stdout = out
+			cmd.Stderr = out
+			if err := cmd.Run(); err != nil {
+				return err
+			}
+
+			cmd = exec.Command("git", "read-tree", "--prefix", path+"/", hash)
+			cmd.Env = env
+			cmd.Stdout = out
+			cmd.Stderr = out
+			if err := cmd.Run(); err != nil {
+				fmt.Fprintf(out, "Cannot stage submodule %s at %s: %s\n", path, hash, err)
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+}
+
+// GitEnv creates an os environment for git commands that manipulate the
+// repository cache of dependency repositories.
 func GitEnv(path string) []string {
 	env := os.Environ()
 	env = append(env, "GIT_DIR="+path)
 	env = append(env, "GIT_WORK_TREE=.")
-	env = append(env, "GIT_INDEX_FILE="+path+"/INDEX")
 	return env
 }
+
+// GitIndexEnv creates an os environment for git commands that manipulate the
+// repository cache of dependency repositories and the vendor stage of the
+// working copy in the given index file.
+func GitIndexEnv(path, indexFile string) []string {
+	return append(GitEnv(path), "GIT_INDEX_FILE="+indexFile)
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gogit "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+func TestCheckoutIncremental(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root, err := ioutil.TempDir("", "gg-checkout")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	src := filepath.Join(root, "src")
+	work := filepath.Join(root, "work")
+	require.NoError(t, os.MkdirAll(src, 0755))
+	require.NoError(t, os.MkdirAll(work, 0755))
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = src
+		cmd.Env = append(gitEnvWithout("GIT_DIR", "GIT_WORK_TREE", "GIT_INDEX_FILE"),
+			"GIT_AUTHOR_NAME=gg", "GIT_AUTHOR_EMAIL=gg@example.com",
+			"GIT_COMMITTER_NAME=gg", "GIT_COMMITTER_EMAIL=gg@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "%s", out)
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(files map[string]string) plumbing.Hash {
+		for name, content := range files {
+			require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(src, name)), 0755))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(src, name), []byte(content), 0644))
+		}
+		git("add", "-A")
+		git("commit", "-q", "-m", "Commit")
+		return plumbing.NewHash(git("rev-parse", "HEAD"))
+	}
+	git("init", "-q")
+	first := commit(map[string]string{"a.go": "package a\n", "sub/b.go": "package sub\n"})
+	second := commit(map[string]string{"a.go": "package a // changed\n"})
+	gitDir := filepath.Join(src, ".git")
+	indexFile := filepath.Join(root, "INDEX")
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(work))
+	defer os.Chdir(wd)
+
+	read := func(name string) string {
+		data, err := ioutil.ReadFile(filepath.FromSlash(name))
+		require.NoError(t, err)
+		return string(data)
+	}
+	exists := func(name string) bool {
+		_, err := os.Lstat(filepath.FromSlash(name))
+		return err == nil
+	}
+	progress := &LogSolverProgress{}
+
+	require.NoError(t, Checkout(progress, gitDir, indexFile, Modules{
+		{Name: "example.com/one", Hash: first},
+		{Name: "example.com/two", Hash: first},
+	}, VendorPruner{}))
+	assert.Equal(t, "package a\n", read("vendor/example.com/one/a.go"))
+	assert.Equal(t, "package sub\n", read("vendor/example.com/two/sub/b.go"))
+
+	// Files of unchanged modules keep their modification times, but changed
+	// modules, modified files, and stray files are written over or removed.
+	mtime := func(name string) time.Time {
+		info, err := os.Stat(filepath.FromSlash(name))
+		require.NoError(t, err)
+		return info.ModTime()
+	}
+	unchanged := mtime("vendor/example.com/one/a.go")
+	unchangedSub := mtime("vendor/example.com/two/sub/b.go")
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, ioutil.WriteFile("vendor/example.com/one/sub/b.go", []byte("package edited\n"), 0644))
+	require.NoError(t, ioutil.WriteFile("vendor/stray.go", []byte("package stray\n"), 0644))
+	require.NoError(t, Checkout(progress, gitDir, indexFile, Modules{
+		{Name: "example.com/one", Hash: first},
+		{Name: "example.com/two", Hash: second},
+	}, VendorPruner{}))
+	assert.Equal(t, unchanged, mtime("vendor/example.com/one/a.go"))
+	assert.Equal(t, unchangedSub, mtime("vendor/example.com/two/sub/b.go"))
+	assert.Equal(t, "package a // changed\n", read("vendor/example.com/two/a.go"))
+	assert.Equal(t, "package sub\n", read("vendor/example.com/one/sub/b.go"))
+	assert.False(t, exists("vendor/stray.go"))
+
+	// Removed modules leave nothing behind.
+	require.NoError(t, Checkout(progress, gitDir, indexFile, Modules{
+		{Name: "example.com/one", Hash: first},
+	}, VendorPruner{}))
+	assert.True(t, exists("vendor/example.com/one/a.go"))
+	assert.False(t, exists("vendor/example.com/two"))
+	_, err = os.Stat(indexFile + ".next")
+	assert.True(t, os.IsNotExist(err))
+
+	// Pruned files are left out, and the vendor digest of the pruned checkout
+	// matches the digest of the locked commits.
+	yes := true
+	pruner := VendorPruner{
+		Pruning: VendorPruning{
+			{Pattern: "...", Match: PatternSplit("..."), UnusedPackages: &yes},
+		},
+		Packages: NewStringSet([]string{"example.com/one"}),
+	}
+	modules := Modules{{Name: "example.com/one", Hash: first}}
+	require.NoError(t, Checkout(progress, gitDir, indexFile, modules, pruner))
+	assert.True(t, exists("vendor/example.com/one/a.go"))
+	assert.False(t, exists("vendor/example.com/one/sub"))
+	repo, err := gogit.PlainOpen(src)
+	require.NoError(t, err)
+	_, want, err := DigestVendor(repo, modules, pruner)
+	require.NoError(t, err)
+	got, err := ReadVendorDigest("vendor")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+// +build !windows
+
+package gg
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"go.uber.org/multierr"
+)
+
+// LockGitDir takes a lock on the repository cache, waiting for any other gg
+// process to release a conflicting lock, so that processes in several
+// worktrees or projects can safely update a shared cache.
+// Fetches of distinct modules update distinct references, so they share the
+// lock, and may run concurrently, even within a process.
+// Updating every vendor reference at once requires an exclusive lock.
+// The returned function releases the lock.
+func LockGitDir(gitDir string, exclusive bool) (func() error, error) {
+	file, err := os.OpenFile(filepath.Join(gitDir, "gg.lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	if err := syscall.Flock(int(file.Fd()), how); err != nil {
+		return nil, multierr.Append(err, file.Close())
+	}
+	return func() error {
+		return multierr.Append(syscall.Flock(int(file.Fd()), syscall.LOCK_UN), file.Close())
+	}, nil
+}
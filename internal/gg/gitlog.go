@@ -0,0 +1,174 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// file gitlog.go walks the commit graph between two versions of a module in
+// the cache, as an alternative to a changelog for modules that have none.
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// GitLog is the commits between an old and new commit of the same repository.
+// Commits are the commits reachable from the new commit but not the old one.
+// If the new commit does not descend from the old one, as after a branch
+// rewrite, Dropped are the commits reachable from the old commit but not the
+// new one.
+// Ancestor is whether the old commit descends from the new one instead, as in
+// a downgrade, in which case Dropped are the commits the downgrade undoes.
+type GitLog struct {
+	Old        plumbing.Hash
+	New        plumbing.Hash
+	Descendant bool
+	Ancestor   bool
+	Commits    []LogCommit
+	Dropped    []LogCommit
+}
+
+// LogCommit is a commit in a GitLog, with flags for merges and reverts.
+type LogCommit struct {
+	Hash    plumbing.Hash
+	Author  string
+	Email   string
+	Time    time.Time
+	Subject string
+	Merge   bool
+	Revert  bool
+}
+
+// NewLogCommit summarizes a commit for a log.
+func NewLogCommit(commit *object.Commit) LogCommit {
+	subject := strings.SplitN(strings.TrimSpace(commit.Message), "\n", 2)[0]
+	return LogCommit{
+		Hash:    commit.Hash,
+		Author:  commit.Author.Name,
+		Email:   commit.Author.Email,
+		Time:    commit.Author.When,
+		Subject: subject,
+		Merge:   len(commit.ParentHashes) > 1,
+		Revert:  strings.HasPrefix(subject, "Revert ") || strings.Contains(commit.Message, "This reverts commit "),
+	}
+}
+
+// ReadGitLog walks the commit graph of a repository between an old and new
+// commit.
+func ReadGitLog(repo *git.Repository, old, new plumbing.Hash) (GitLog, error) {
+	log := GitLog{Old: old, New: new}
+	if old == new {
+		log.Descendant = true
+		return log, nil
+	}
+
+	oldAncestors, err := gitAncestors(repo, old, nil)
+	if err != nil {
+		return log, err
+	}
+	commits, err := gitAncestors(repo, new, oldAncestors)
+	if err != nil {
+		return log, err
+	}
+	log.Commits = logCommits(commits)
+
+	// The old commit descends from the new one if walking back from the old
+	// commit arrives at the new one.
+	if _, ok := oldAncestors[new]; ok {
+		log.Ancestor = true
+	}
+
+	// The new commit descends from the old one if walking back from the new
+	// commit arrives at the old one.
+	for _, commit := range commits {
+		for _, parent := range commit.ParentHashes {
+			if parent == old {
+				log.Descendant = true
+			}
+		}
+	}
+
+	if !log.Descendant {
+		newAncestors, err := gitAncestors(repo, new, nil)
+		if err != nil {
+			return log, err
+		}
+		dropped, err := gitAncestors(repo, old, newAncestors)
+		if err != nil {
+			return log, err
+		}
+		log.Dropped = logCommits(dropped)
+	}
+	return log, nil
+}
+
+// Divergence returns a sentence that says how the new commit relates to the
+// old one if it does not descend from it, naming each version with the given
+// labels, and whether that merits a warning, for the log and release notes
+// alike.
+// A downgrade is no cause for alarm, but any other divergence suggests a
+// rewritten branch.
+func (log GitLog) Divergence(old, new string) (string, bool) {
+	if log.Descendant {
+		return "", false
+	}
+	if log.Ancestor {
+		return fmt.Sprintf("%s is older than %s, so this is a downgrade.", new, old), false
+	}
+	return fmt.Sprintf("%s does not descend from %s, which suggests a rewritten branch or a stray commit.", new, old), true
+}
+
+// gitAncestors returns the commit with the given hash and all of its
+// ancestors, except those in a set to stop at, and their ancestors.
+func gitAncestors(repo *git.Repository, hash plumbing.Hash, stop map[plumbing.Hash]*object.Commit) (map[plumbing.Hash]*object.Commit, error) {
+	ancestors := make(map[plumbing.Hash]*object.Commit)
+	frontier := []plumbing.Hash{hash}
+	for len(frontier) > 0 {
+		hash := frontier[len(frontier)-1]
+		frontier = frontier[:len(frontier)-1]
+		if _, ok := ancestors[hash]; ok {
+			continue
+		}
+		if _, ok := stop[hash]; ok {
+			continue
+		}
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read commit %s from the cache: %s", hash, err)
+		}
+		ancestors[hash] = commit
+		frontier = append(frontier, commit.ParentHashes...)
+	}
+	return ancestors, nil
+}
+
+// logCommits orders commits from newest to oldest, like git log.
+func logCommits(commits map[plumbing.Hash]*object.Commit) []LogCommit {
+	log := make([]LogCommit, 0, len(commits))
+	for _, commit := range commits {
+		log = append(log, NewLogCommit(commit))
+	}
+	sort.Slice(log, func(i, j int) bool {
+		if !log[i].Time.Equal(log[j].Time) {
+			return log[i].Time.After(log[j].Time)
+		}
+		return HashBefore(log[i].Hash, log[j].Hash)
+	})
+	return log
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+func TestReadGitLog(t *testing.T) {
+	repo, err := git.Init(memory.NewStorage(), nil)
+	require.NoError(t, err)
+	tree := testGitTree(t, repo, nil)
+	day := 0
+	commit := func(message string, parents ...plumbing.Hash) plumbing.Hash {
+		day++
+		signature := object.Signature{
+			Name: "Robotto Botdroid",
+			When: time.Date(2018, 1, day, 0, 0, 0, 0, time.UTC),
+		}
+		obj := repo.Storer.NewEncodedObject()
+		require.NoError(t, (&object.Commit{
+			Author:       signature,
+			Committer:    signature,
+			Message:      message,
+			TreeHash:     tree,
+			ParentHashes: parents,
+		}).Encode(obj))
+		hash, err := repo.Storer.SetEncodedObject(obj)
+		require.NoError(t, err)
+		return hash
+	}
+
+	a := commit("Initial commit\n")
+	b := commit("Add feature\n", a)
+	c := commit("Fix feature\n\nWith a longer description.\n", b)
+	d := commit("Branch off\n", a)
+	m := commit("Merge branch 'd'\n", c, d)
+	r := commit("Revert \"Add feature\"\n\nThis reverts commit "+b.String()+".\n", m)
+
+	subjects := func(commits []LogCommit) []string {
+		var subjects []string
+		for _, commit := range commits {
+			subjects = append(subjects, commit.Subject)
+		}
+		return subjects
+	}
+
+	log, err := ReadGitLog(repo, b, r)
+	require.NoError(t, err)
+	assert.True(t, log.Descendant)
+	assert.False(t, log.Ancestor)
+	assert.Equal(t, []string{
+		"Revert \"Add feature\"",
+		"Merge branch 'd'",
+		"Branch off",
+		"Fix feature",
+	}, subjects(log.Commits))
+	assert.Empty(t, log.Dropped)
+	divergence, warning := log.Divergence("old", "new")
+	assert.Empty(t, divergence)
+	assert.False(t, warning)
+	assert.True(t, log.Commits[0].Revert)
+	assert.False(t, log.Commits[0].Merge)
+	assert.True(t, log.Commits[1].Merge)
+	assert.False(t, log.Commits[1].Revert)
+	assert.Equal(t, "Robotto Botdroid", log.Commits[3].Author)
+
+	log, err = ReadGitLog(repo, c, d)
+	require.NoError(t, err)
+	assert.False(t, log.Descendant)
+	assert.Equal(t, []string{"Branch off"}, subjects(log.Commits))
+	assert.False(t, log.Ancestor)
+	assert.Equal(t, []string{"Fix feature", "Add feature"}, subjects(log.Dropped))
+	divergence, warning = log.Divergence("old", "new")
+	assert.Equal(t, "new does not descend from old, which suggests a rewritten branch or a stray commit.", divergence)
+	assert.True(t, warning)
+
+	log, err = ReadGitLog(repo, c, a)
+	require.NoError(t, err)
+	assert.False(t, log.Descendant)
+	assert.True(t, log.Ancestor)
+	assert.Empty(t, log.Commits)
+	assert.Equal(t, []string{"Fix feature", "Add feature"}, subjects(log.Dropped))
+	divergence, warning = log.Divergence("old", "new")
+	assert.Equal(t, "new is older than old, so this is a downgrade.", divergence)
+	assert.False(t, warning)
+
+	log, err = ReadGitLog(repo, c, c)
+	require.NoError(t, err)
+	assert.True(t, log.Descendant)
+	assert.Empty(t, log.Commits)
+}
@@ -18,6 +18,7 @@ import (
 	"io/ioutil"
 	"time"
 
+	"gopkg.in/src-d/go-git.v4/plumbing"
 	yaml "gopkg.in/yaml.v2"
 )
 
@@ -29,7 +30,11 @@ import (
 type GlideLock struct {
 	// Updated timestamp deliberately omitted since it would invalidate the
 	// deterministic mapping from glide.lock content hash to vendor tree hash.
-	Generator   string            `yaml:"generator"`
+	Generator string `yaml:"generator"`
+	// Vendor is specific to gg and records the digest of the vendor tree
+	// itself: the hash of the git tree that checkout writes to the vendor
+	// directory, so install can tell whether the vendor directory diverges.
+	Vendor      string            `yaml:"vendor,omitempty"`
 	Imports     []GlideLockImport `yaml:"imports,omitempty"`
 	TestImports []GlideLockImport `yaml:"testImports,omitempty"`
 }
@@ -46,6 +51,9 @@ type GlideLockImport struct {
 	// Glidelock is the hash of a glide.lock file in the git repository, if
 	// present.
 	Glidelock string `yaml:"glidelock,omitempty"`
+	// Tree is specific to gg and records the hash of the git tree that
+	// checkout writes to the vendor directory of the module.
+	Tree string `yaml:"tree,omitempty"`
 	// Commands is specific to gg and lists all of the "main" packages in the
 	// module.
 
@@ -102,6 +110,45 @@ type GlideLockRequirement struct {
 	VCS string `yaml:"vcs,omitempty"`
 }
 
+// RecordDigest records the trees of the given digested modules in the imports
+// locked at the same commits, and the given digest of the whole vendor
+// checkout if the glide.lock locks exactly those modules, and returns whether
+// anything changed.
+// If the glide.lock locks some other solution, it loses its vendor digest,
+// since the vendor directory no longer matches it, but the other imports keep
+// their trees, which depend only on their commits.
+func (lock *GlideLock) RecordDigest(modules Modules, vendor plumbing.Hash) bool {
+	index := modules.Index()
+	matched := 0
+	changed := false
+	record := func(imps []GlideLockImport, test bool) {
+		for i := range imps {
+			imp := &imps[i]
+			module, ok := index[imp.Name]
+			if !ok || module.Test != test || HashString(module.Hash) != imp.Version {
+				continue
+			}
+			matched++
+			if tree := HashString(module.Tree); tree != "" && tree != imp.Tree {
+				imp.Tree = tree
+				changed = true
+			}
+		}
+	}
+	record(lock.Imports, false)
+	record(lock.TestImports, true)
+
+	digest := ""
+	if matched == len(modules) && matched == len(lock.Imports)+len(lock.TestImports) {
+		digest = HashString(vendor)
+	}
+	if digest != lock.Vendor {
+		lock.Vendor = digest
+		changed = true
+	}
+	return changed
+}
+
 // ReadOwnGlideLock reads the glide.lock in the working directory.
 func ReadOwnGlideLock() (*GlideLock, error) {
 	bytes, err := ioutil.ReadFile("glide.lock")
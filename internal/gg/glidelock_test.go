@@ -0,0 +1,81 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+func TestGlideLockRecordDigest(t *testing.T) {
+	avery := plumbing.NewHash("a28ced3c00000000000000000000000000000000")
+	blake := plumbing.NewHash("b1a4e00000000000000000000000000000000000")
+	averyTree := plumbing.NewHash("a7ee000000000000000000000000000000000000")
+	blakeTree := plumbing.NewHash("b7ee000000000000000000000000000000000000")
+	vendor := plumbing.NewHash("7e4d000000000000000000000000000000000000")
+	modules := Modules{
+		{Name: "avery", Hash: avery, Tree: averyTree},
+		{Name: "blake", Hash: blake, Tree: blakeTree, Test: true},
+	}
+	imp := func(name string, hash plumbing.Hash, tree string) GlideLockImport {
+		return GlideLockImport{
+			GlideLockRequirement: GlideLockRequirement{Name: name, Version: hash.String()},
+			Tree:                 tree,
+		}
+	}
+
+	t.Run("same solution", func(t *testing.T) {
+		lock := &GlideLock{
+			Imports:     []GlideLockImport{imp("avery", avery, "")},
+			TestImports: []GlideLockImport{imp("blake", blake, "")},
+		}
+		assert.True(t, lock.RecordDigest(modules, vendor))
+		assert.Equal(t, vendor.String(), lock.Vendor)
+		assert.Equal(t, averyTree.String(), lock.Imports[0].Tree)
+		assert.Equal(t, blakeTree.String(), lock.TestImports[0].Tree)
+		assert.False(t, lock.RecordDigest(modules, vendor))
+	})
+
+	t.Run("other solution", func(t *testing.T) {
+		lock := &GlideLock{
+			Vendor: vendor.String(),
+			Imports: []GlideLockImport{
+				imp("avery", avery, ""),
+				imp("carey", avery, "c7ee000000000000000000000000000000000000"),
+			},
+			TestImports: []GlideLockImport{imp("blake", avery, "")},
+		}
+		assert.True(t, lock.RecordDigest(modules, vendor))
+		assert.Empty(t, lock.Vendor)
+		assert.Equal(t, averyTree.String(), lock.Imports[0].Tree)
+		assert.Equal(t, "c7ee000000000000000000000000000000000000", lock.Imports[1].Tree)
+		assert.Empty(t, lock.TestImports[0].Tree)
+	})
+
+	t.Run("undigested", func(t *testing.T) {
+		lock := &GlideLock{
+			Imports:     []GlideLockImport{imp("avery", avery, averyTree.String())},
+			TestImports: []GlideLockImport{imp("blake", blake, "")},
+		}
+		assert.False(t, lock.RecordDigest(Modules{
+			{Name: "avery", Hash: avery},
+			{Name: "blake", Hash: blake, Test: true},
+		}, NoHash))
+		assert.Equal(t, averyTree.String(), lock.Imports[0].Tree)
+		assert.Empty(t, lock.Vendor)
+	})
+}
@@ -85,9 +85,11 @@ func moduleFromGlideLockImport(imp GlideLockImport, test bool) (Module, error) {
 	testImports.SourcesIntoStringSet(all)
 	coTestImports.SourcesIntoStringSet(all)
 
+	version, prerelease := ParseSemver(imp.Revision)
 	return Module{
 		Name:                  imp.Name,
-		Version:               ParseVersion(imp.Revision),
+		Version:               version,
+		Prerelease:            prerelease,
 		Hash:                  plumbing.NewHash(imp.Version),
 		Time:                  imp.Time,
 		Remote:                imp.Repo,
@@ -99,6 +101,7 @@ func moduleFromGlideLockImport(imp GlideLockImport, test bool) (Module, error) {
 		Warnings:              imp.Warnings,
 		Changelog:             plumbing.NewHash(imp.Changelog),
 		Glidelock:             plumbing.NewHash(imp.Glidelock),
+		Tree:                  plumbing.NewHash(imp.Tree),
 		GitoliteMirror:        imp.GitoliteMirror,
 		GitoliteMirrorCreated: imp.GitoliteMirrorCreated,
 		Packages: Packages{
@@ -119,6 +122,7 @@ func glideLockImportFromModule(module Module) GlideLockImport {
 		Warnings:              module.Warnings,
 		Changelog:             HashString(module.Changelog),
 		Glidelock:             HashString(module.Glidelock),
+		Tree:                  HashString(module.Tree),
 		GitoliteMirror:        module.GitoliteMirror,
 		GitoliteMirrorCreated: module.GitoliteMirrorCreated,
 		Requirements:          glideLockRequirementsFromModules(module.Modules),
@@ -131,21 +135,23 @@ func glideLockImportFromModule(module Module) GlideLockImport {
 }
 
 func moduleFromGlideLockRequirement(requirement GlideLockRequirement) Module {
+	version, prerelease := ParseSemver(requirement.Revision)
 	return Module{
-		Name:    requirement.Name,
-		Version: ParseVersion(requirement.Revision),
-		Hash:    plumbing.NewHash(requirement.Version),
-		Time:    requirement.Time,
-		Remote:  requirement.Repo,
-		Root:    requirement.Root,
-		Ref:     requirement.Ref,
+		Name:       requirement.Name,
+		Version:    version,
+		Prerelease: prerelease,
+		Hash:       plumbing.NewHash(requirement.Version),
+		Time:       requirement.Time,
+		Remote:     requirement.Repo,
+		Root:       requirement.Root,
+		Ref:        requirement.Ref,
 	}
 }
 
 func glideLockRequirementFromModule(module Module) GlideLockRequirement {
 	return GlideLockRequirement{
 		Name:     module.Name,
-		Revision: module.Version.String(),
+		Revision: module.VersionString(),
 		Version:  HashString(module.Hash),
 		Time:     module.Time,
 		Repo:     module.Remote,
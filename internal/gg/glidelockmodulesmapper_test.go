@@ -19,6 +19,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-git.v4/plumbing"
 )
 
 func TestGlideLockModulesMapper(t *testing.T) {
@@ -43,6 +44,7 @@ func TestGlideLockModulesMapper(t *testing.T) {
 			Name:     "carey",
 			Hash:     careyHash,
 			Packages: careyPackages(),
+			Tree:     plumbing.NewHash("4b825dc642cb6eb9a060e54bf8d69288fbee4904"),
 		},
 		{
 			Name:                  "drew",
@@ -26,9 +26,9 @@ func GlideManifestFromModules(modules Modules) *GlideManifest {
 		version := ""
 		if module.Version != NoVersion {
 			if module.Version[0] == 0 {
-				version = "~" + module.Version.String()
+				version = "~" + module.VersionString()
 			} else {
-				version = "^" + module.Version.String()
+				version = "^" + module.VersionString()
 			}
 		} else if strings.HasPrefix(module.Ref, "heads/") {
 			version = strings.TrimPrefix(module.Ref, "heads/")
@@ -141,3 +141,17 @@ func ParseHashPrefix(str string) (min, max plumbing.Hash) {
 	}
 	return
 }
+
+// MinHashPrefix is the fewest hexadecimal digits that we accept as an
+// abbreviated commit hash in the place of a module name, like git.
+const MinHashPrefix = 4
+
+// IsHashPrefix returns whether a string is an abbreviated commit hash, long
+// enough to stand in for a module name.
+func IsHashPrefix(str string) bool {
+	if len(str) < MinHashPrefix {
+		return false
+	}
+	_, max := ParseHashPrefix(str)
+	return max != NoHash
+}
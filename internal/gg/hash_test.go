@@ -87,6 +87,27 @@ func TestParseHashPrefixPrefixOdd(t *testing.T) {
 	assert.Equal(t, max.String(), maxHash)
 }
 
+func TestIsHashPrefix(t *testing.T) {
+	tests := []struct {
+		str  string
+		want bool
+	}{
+		{"3fe1cac1", true},
+		{"a28c", true},
+		{"a28", false},
+		{averyHashString, true},
+		{averyHashString + "f", false},
+		{"go.uber.org/fx", false},
+		{"3FE1CAC1", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsHashPrefix(tt.str))
+		})
+	}
+}
+
 func TestHashBefore(t *testing.T) {
 	tests := []struct {
 		msg            string
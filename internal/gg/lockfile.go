@@ -0,0 +1,81 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Lockfile is a kind of lock or manifest file in the working copy, with the
+// commands that read a solution from it and write a solution to it.
+// Locked is whether the file records the commit of every module, as a lock
+// does and a manifest does not.
+type Lockfile struct {
+	Name   string
+	Read   string
+	Write  string
+	Locked bool
+}
+
+// Lockfiles are all the kinds of file that the read command recognizes, in
+// their default order of precedence.
+var Lockfiles = []Lockfile{
+	{Name: "go.mod", Read: "read-mod-lock", Write: "write-mod-lock", Locked: true},
+	{Name: "Gopkg.lock", Read: "read-dep-lock", Write: "write-dep-lock", Locked: true},
+	{Name: "glide.lock", Read: "read-glide-lock", Write: "write-glide-lock", Locked: true},
+	{Name: "Gopkg.toml", Read: "read-dep-toml", Write: "write-dep-toml"},
+	{Name: "glide.yaml", Read: "read-glide-yaml", Write: "write-glide-yaml"},
+}
+
+// DefaultLockfile is the kind of file that the write command writes if the
+// working copy has none of the recognized files.
+const DefaultLockfile = "glide.lock"
+
+// FindLockfile returns the kind of lockfile with the given file name.
+func FindLockfile(name string) (Lockfile, error) {
+	names := make([]string, 0, len(Lockfiles))
+	for _, lockfile := range Lockfiles {
+		if lockfile.Name == name {
+			return lockfile, nil
+		}
+		names = append(names, lockfile.Name)
+	}
+	return Lockfile{}, fmt.Errorf("unrecognized lockfile %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// LockedNames returns the names of the kinds of lockfile that record the
+// commit of every module, joined for a message.
+func LockedNames() string {
+	var names []string
+	for _, lockfile := range Lockfiles {
+		if lockfile.Locked {
+			names = append(names, lockfile.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// FindOwnLockfile returns the first of the given kinds of lockfile that
+// exists in the working copy, and whether there is one.
+func FindOwnLockfile(lockfiles []Lockfile) (Lockfile, bool) {
+	for _, lockfile := range lockfiles {
+		if _, err := os.Stat(lockfile.Name); err == nil {
+			return lockfile, true
+		}
+	}
+	return Lockfile{}, false
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLockfiles(t *testing.T) {
+	config, err := ReadConfig([]byte(""))
+	require.NoError(t, err)
+	lockfiles, err := config.ReadLockfiles()
+	require.NoError(t, err)
+	assert.Equal(t, Lockfiles, lockfiles)
+
+	config, err = ReadConfig([]byte(`lockfiles = ["glide.lock", "go.mod"]`))
+	require.NoError(t, err)
+	lockfiles, err = config.ReadLockfiles()
+	require.NoError(t, err)
+	assert.Equal(t, []Lockfile{
+		{Name: "glide.lock", Read: "read-glide-lock", Write: "write-glide-lock", Locked: true},
+		{Name: "go.mod", Read: "read-mod-lock", Write: "write-mod-lock", Locked: true},
+	}, lockfiles)
+
+	config, err = ReadConfig([]byte(`lockfiles = ["Godeps.json"]`))
+	require.NoError(t, err)
+	_, err = config.ReadLockfiles()
+	assert.EqualError(t, err, `cannot read lockfiles from gg.toml: unrecognized lockfile "Godeps.json", expected one of go.mod, Gopkg.lock, glide.lock, Gopkg.toml, glide.yaml`)
+}
+
+func TestLockedNames(t *testing.T) {
+	assert.Equal(t, "go.mod, Gopkg.lock, glide.lock", LockedNames())
+}
+
+func TestLockfileCommands(t *testing.T) {
+	names := make(StringSet)
+	for _, command := range commands() {
+		names.Include(NewStringSet(command.Names))
+	}
+	for _, lockfile := range Lockfiles {
+		assert.True(t, names.Has(lockfile.Read), "read command for %s", lockfile.Name)
+		assert.True(t, names.Has(lockfile.Write), "write command for %s", lockfile.Name)
+	}
+}
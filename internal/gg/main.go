@@ -19,7 +19,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path"
 	"strings"
 
 	billy "gopkg.in/src-d/go-billy.v4"
@@ -58,13 +57,21 @@ type Environment struct {
 func (env Environment) Run(ctx context.Context) error {
 	// Build a memo.
 	goPath := strings.Split(env.Getenv("GOPATH"), ":")
-	gitDir := path.Join(env.WorkDir, GGCachePath)
+	config, err := ReadOwnConfig(env.WorkDir)
+	if err != nil {
+		return err
+	}
+	gitDir, indexFile, err := CacheLocation(env.WorkDir, config.Repository, env.Getenv)
+	if err != nil {
+		return err
+	}
 	memo, err := NewMemo(gitDir, env.WorkDir, goPath)
 	if err != nil {
 		return err
 	}
+	memo.IndexFile = indexFile
 
-	if err := memo.ReadConfig(); err != nil {
+	if err := memo.UseConfig(config); err != nil {
 		return err
 	}
 
@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/multierr"
@@ -35,6 +36,7 @@ import (
 // expensive operations occur only once during a session.
 type Memo struct {
 	GitDir            string
+	IndexFile         string
 	WorkDir           string
 	GoPath            []string
 	Repository        *git.Repository
@@ -50,11 +52,25 @@ type Memo struct {
 	OwnPackages       Packages                   // Imports and exports of working copy
 	Excludes          StringSet                  // directory names to exclude from the working copy
 	Recommended       map[string]Version         // config recommended versions for add missing workflow
+	Prereleases       Prereleases                // config modules that may upgrade to pre-releases
+	Policies          Policies                   // config upgrade policies by module name pattern
+	Exclusions        Exclusions                 // config versions to exclude from consideration
+	VendorPruning     VendorPruning              // config pruning of the vendor checkout by module name pattern
+	Lockfiles         []Lockfile                 // config lockfiles for read in order of precedence
+	Config            *Config                    // merged gg.toml files
 	Finished          map[plumbing.Hash]ModuleResult
 	Commits           map[plumbing.Hash]*object.Commit
 	VendorCache       string
 	PulledVendorCache bool
 	Offline           bool
+	Concurrency       int // modules to fetch and read at once
+
+	// mu guards the repository and every map of the memo while a pool of
+	// workers reads modules.
+	mu sync.Mutex
+	// fetching has a channel for every remote that a worker is fetching,
+	// which closes when the fetch is done.
+	fetching map[string]chan struct{}
 
 	// Metrics
 	GitFetchCalls            int
@@ -88,6 +104,7 @@ func NewMemo(gitDir, workDir string, goPath []string) (*Memo, error) {
 	}
 	return &Memo{
 		GitDir:           gitDir,
+		IndexFile:        filepath.Join(gitDir, "INDEX"),
 		WorkDir:          workDir,
 		GoPath:           goPath,
 		Repository:       repo,
@@ -99,25 +116,43 @@ func NewMemo(gitDir, workDir string, goPath []string) (*Memo, error) {
 		Packages:         make(map[string]Packages),
 		OwnPackages:      NewPackages(),
 		Recommended:      make(map[string]Version),
+		Lockfiles:        Lockfiles,
+		Config:           &Config{},
 		Commits:          make(map[plumbing.Hash]*object.Commit),
 		Finished:         make(map[plumbing.Hash]ModuleResult),
+		Concurrency:      DefaultConcurrency,
+		fetching:         make(map[string]chan struct{}),
 	}, nil
 }
 
-// ReadConfig reads the gg.toml in the working directory or any parent
-// thereof.
-func (memo *Memo) ReadConfig() error {
-	// Read configuration in working copy.
-	config, err := ReadOwnConfig(memo.WorkDir)
+// UseConfig applies the merged gg.toml of the working directory and every
+// parent thereof, as read by ReadOwnConfig.
+func (memo *Memo) UseConfig(config *Config) error {
+	lockfiles, err := config.ReadLockfiles()
+	if err != nil {
+		return err
+	}
+	concurrency, err := config.ReadConcurrency()
+	if err != nil {
+		return err
+	}
+	policies, err := config.ReadPolicies()
 	if err != nil {
 		return err
 	}
+	memo.Config = config
 	memo.VendorCache = config.Cache
 	memo.Patterns = config.ReadPatterns()
 	memo.Mirrors = config.ReadGitoliteMirrors()
 	memo.Excludes = config.ReadExcludes()
 	memo.Recommended = config.ReadRecommended()
-	return err
+	memo.Prereleases = config.ReadPrereleases()
+	memo.Policies = policies
+	memo.Exclusions = config.ReadExclusions()
+	memo.VendorPruning = config.ReadVendorPruning()
+	memo.Lockfiles = lockfiles
+	memo.Concurrency = concurrency
+	return nil
 }
 
 // Repository gets or creates a bare git repository at the given path.
@@ -127,11 +162,13 @@ func Repository(path string) (*git.Repository, error) {
 	repo, err = git.PlainInit(path, true)
 	if err == git.ErrRepositoryAlreadyExists {
 		repo, err = git.PlainOpen(path)
+	} else if err == nil {
+		// Hide a new cache from the working copy, if it is within.
+		_ = ioutil.WriteFile(filepath.Join(path, ".gitignore"), []byte("*\n"), 0644)
 	}
 	if err != nil {
 		return nil, err
 	}
-	_ = ioutil.WriteFile(filepath.Join(path, ".gitignore"), []byte("*\n"), 0644)
 	return repo, nil
 }
 
@@ -169,20 +206,21 @@ func (memo *Memo) ReadOwnModules(ctx context.Context, out ProgressWriter) (Modul
 }
 
 // FinishModules normalizes, fetches, and caches all the given modules,
-// providing a progress indicator.
+// fetching several at a time, providing a progress indicator.
 func (memo *Memo) FinishModules(ctx context.Context, out ProgressWriter, modules Modules) error {
 	start := time.Now()
 	out.Start("Reading modules")
-	for i := range modules {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
+	done := 0
+	memo.parallel(ctx, len(modules), func(ctx context.Context, i int) {
 		if err := memo.FinishModule(ctx, out, &modules[i]); err != nil {
 			fmt.Fprintf(out, "Failed to finish reading module %s: %s\n", modules[i].Summary(), err)
+			modules[i].FinishError = err
 		}
-		out.Progress("Reading modules", i+1, len(modules), start, time.Now())
+		done++
+		out.Progress("Reading modules", done, len(modules), start, time.Now())
+	})
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 	out.Stop("Reading modules")
 	return nil
@@ -237,6 +275,7 @@ func (memo *Memo) memoFinishModule(ctx context.Context, out ProgressWriter, modu
 			fmt.Fprintf(out, "Unable to fetch module %s: %s\n", module.Summary(), err)
 		} else if commit, err = memo.Commit(ctx, out, module.Hash); err != nil {
 			module.Warnings = append(module.Warnings, fmt.Sprintf("Dependency %s no longer exists locally nor at %s: %s\n", module.Summary(), module.Remote, err))
+			module.FinishError = fmt.Errorf("commit no longer exists locally nor at %s", module.Remote)
 		} else {
 			module.Fetched = true
 		}
@@ -247,9 +286,13 @@ func (memo *Memo) memoFinishModule(ctx context.Context, out ProgressWriter, modu
 	if module.Fetched {
 		// Normalize the package hash to the commit hash.
 		module.Hash = commit.Hash
+
+		if err := memo.FetchSubmodules(ctx, out, *module, commit); err != nil {
+			module.Warnings = append(module.Warnings, fmt.Sprintf("Cannot fetch submodules: %s", err))
+		}
 	}
 
-	// Stage 2: Fetched, Hash, Time, Glidelock, Deplock, Modules
+	// Stage 2: Fetched, Hash, Time, Glidelock, Deplock, Modlock, Modules
 
 	if module.Ref == "" {
 		if err := memo.DigestRefs(ctx, out, *module); err != nil {
@@ -258,10 +301,10 @@ func (memo *Memo) memoFinishModule(ctx context.Context, out ProgressWriter, modu
 		memo.finishModuleRef(module)
 		if strings.HasPrefix(module.Ref, "tags/") {
 			ref := strings.TrimPrefix(module.Ref, "tags/")
-			module.Version = ParseVersion(ref)
+			module.Version, module.Prerelease = ParseSemver(ref)
 		} else if strings.HasPrefix(module.Ref, "heads/") {
 			ref := strings.TrimPrefix(module.Ref, "heads/")
-			module.Version = ParseVersion(ref)
+			module.Version, module.Prerelease = ParseSemver(ref)
 		}
 	}
 
@@ -305,31 +348,61 @@ func (memo *Memo) memoFinishModule(ctx context.Context, out ProgressWriter, modu
 					module.Modules = modules
 				}
 			}
+		} else if file, err := commit.File("go.mod"); err == nil {
+			module.Modlock = file.Hash
+			lock, err := memo.readModLock(module)
+			if err != nil {
+				module.Warnings = append(module.Warnings, fmt.Sprintf("Cannot read go.mod: %s", err))
+			} else {
+				// Like the go command, we disregard the replace and exclude
+				// directives of dependencies.
+				// A requirement we cannot resolve to a commit remains a
+				// constraint, failing, so that the solver accounts for it.
+				modules := make(Modules, 0, len(lock.Requires))
+				for _, require := range lock.Requires {
+					dependency := moduleFromModVersion(require.ModVersion)
+					if err := memo.ResolveModVersion(ctx, out, &dependency, require.ModVersion); err != nil {
+						module.Warnings = append(module.Warnings, fmt.Sprintf("Cannot interpret go.mod: %s", err))
+						dependency.FinishError = err
+					}
+					modules = append(modules, dependency)
+				}
+				module.Modules = modules
+			}
 		} else {
 			module.NoLock = true
 		}
 	}
 
-	// Stage 4: Glidelock, Deplock, Modules
+	// Stage 4: Glidelock, Deplock, Modlock, Modules
 	// Can now solve dependency graph.
 	return nil
 }
 
 // FinishPackages populates the Packages property of each module by analyzing
-// the imports of the files in the git repository.
+// the imports of the files in the git repository, in a pool of workers,
+// though the analysis itself runs one module at a time.
 func (memo *Memo) FinishPackages(ctx context.Context, out ProgressWriter, modules Modules) error {
 	start := time.Now()
 	out.Start("Reading packages")
-	for i := range modules {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
+	done := 0
+	var failure error
+	memo.parallel(ctx, len(modules), func(ctx context.Context, i int) {
+		if failure != nil {
+			return
 		}
 		if err := memo.finishPackages(ctx, out, &modules[i]); err != nil {
-			return err
+			failure = err
+			return
 		}
-		out.Progress("Reading packages", i+1, len(modules), start, time.Now())
+		done++
+		out.Progress("Reading packages", done, len(modules), start, time.Now())
+	})
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if failure != nil {
+		return failure
 	}
 	out.Stop("Reading packages")
 	return nil
@@ -446,7 +519,10 @@ func (memo *Memo) finishRemote(ctx context.Context, out ProgressWriter, module *
 	status := fmt.Sprintf("Looking up remote for package %s", module.Name)
 	out.Start(status)
 	start := time.Now()
-	rem, name := RemoteForPackage(module.Name)
+	var rem, name string
+	memo.unlocked(ctx, func() {
+		rem, name = RemoteForPackage(module.Name)
+	})
 	end := time.Now()
 	module.Remote = rem
 	module.Name = name
@@ -465,17 +541,21 @@ func (memo *Memo) finishRemote(ctx context.Context, out ProgressWriter, module *
 func (memo *Memo) finishModuleRef(module *Module) {
 	var bestRef string
 	var bestVersion Version
+	var bestPrerelease Prerelease
+	var refs []string
 
 	prefix := "refs/vendor/" + module.Root + "/"
 
 	for _, ref := range memo.Refs[module.Hash.String()].Keys() {
 		if strings.HasPrefix(ref, prefix) {
 			ref = strings.TrimPrefix(ref, prefix)
+			refs = append(refs, ref)
 			if strings.HasPrefix(ref, "tags/") {
 				verRef := strings.TrimPrefix(ref, "tags/")
-				version := ParseVersion(verRef)
-				if bestVersion.Before(version) {
+				version, prerelease := ParseSemver(verRef)
+				if bestVersion.Before(version) || bestVersion == version && bestPrerelease.Before(prerelease) {
 					bestVersion = version
+					bestPrerelease = prerelease
 					bestRef = ref
 				}
 			} else if bestVersion == NoVersion && (ref == "heads/master" || ref > bestRef) {
@@ -494,7 +574,9 @@ func (memo *Memo) finishModuleRef(module *Module) {
 	// }
 
 	module.Ref = bestRef
+	module.Refs = refs
 	module.Version = bestVersion
+	module.Prerelease = bestPrerelease
 }
 
 // Commit looks up the corresponding commit object for the hash of a commit or
@@ -599,9 +681,21 @@ func (memo *Memo) Fetch(ctx context.Context, out ProgressWriter, module *Module,
 	}
 
 	if _, ok := memo.Fetched[module.Remote]; ok {
+		// Another worker may still be fetching the same remote.
+		if done, ok := memo.fetching[module.Remote]; ok {
+			memo.unlocked(ctx, func() {
+				<-done
+			})
+		}
 		return nil
 	}
 	memo.Fetched[module.Remote] = struct{}{}
+	done := make(chan struct{})
+	memo.fetching[module.Remote] = done
+	defer func(remote string) {
+		delete(memo.fetching, remote)
+		close(done)
+	}(module.Remote)
 
 	fetching := fmt.Sprintf("Fetching %s", module.Remote)
 	out.Start(fetching)
@@ -619,7 +713,10 @@ func (memo *Memo) Fetch(ctx context.Context, out ProgressWriter, module *Module,
 	var attempts uint
 	for {
 		start := time.Now()
-		err := GitFetchRootRemote(out, memo.GitDir, module.Root, module.Remote)
+		var err error
+		memo.unlocked(ctx, func() {
+			err = GitFetchRootRemote(out, memo.GitDir, module.Root, module.Remote)
+		})
 		if err != nil {
 			attempts++
 			if attempts > uint(maxAttempts) {
@@ -632,7 +729,9 @@ func (memo *Memo) Fetch(ctx context.Context, out ProgressWriter, module *Module,
 			}
 			wait := time.Duration(rand.Int63n(waitns + 1))
 			fmt.Fprintf(out, "Error fetching %s. Attempt %d. Retrying in %v.\n", module.Remote, attempts, wait)
-			time.Sleep(wait)
+			memo.unlocked(ctx, func() {
+				time.Sleep(wait)
+			})
 
 			// Lose faith in the cached remote for this package, as once
 			// happened for Apache Thrift when they moved their repository.
@@ -717,6 +816,7 @@ func (memo *Memo) DigestRefs(ctx context.Context, out ProgressWriter, module Mod
 // The given module must first be fetched and its references digested.
 // Each module is normalized, read, and cached, so you can depend on
 // all of each version's fields to be populated.
+// ReadVersions omits the versions that gg.toml excludes.
 // The returned modules are in total order based on their version, timestamp,
 // and hash.
 func (memo *Memo) ReadVersions(ctx context.Context, out ProgressWriter, module Module) (Modules, error) {
@@ -725,7 +825,7 @@ func (memo *Memo) ReadVersions(ctx context.Context, out ProgressWriter, module M
 	defer out.Stop(status)
 
 	if modules, ok := memo.FinishedVersions[module.Root]; ok && modules != nil {
-		return modules, nil
+		return memo.Exclusions.Filter(modules), nil
 	}
 
 	modules := make(Modules, 0, 1)
@@ -751,7 +851,89 @@ func (memo *Memo) ReadVersions(ctx context.Context, out ProgressWriter, module M
 	memo.FinishedVersions[module.Root] = modules
 
 	sort.Sort(modules)
-	return modules, nil
+	return memo.Exclusions.Filter(modules), nil
+}
+
+// ReplaceExcluded replaces every version among the given modules that gg.toml
+// excludes with the next acceptable version, for the solver.
+func (memo *Memo) ReplaceExcluded(ctx context.Context, out ProgressWriter, modules Modules) Modules {
+	return ReplaceExcluded(ctx, memo, out, modules, memo.Exclusions)
+}
+
+// PrefetchVersions fetches and reads the versions of all the given modules,
+// fetching several at a time, so that ReadVersions finds them memoized.
+// ReadVersions reports any failure when the versions are needed.
+func (memo *Memo) PrefetchVersions(ctx context.Context, out ProgressWriter, modules Modules) {
+	start := time.Now()
+	out.Start("Fetching versions")
+	done := 0
+	memo.parallel(ctx, len(modules), func(ctx context.Context, i int) {
+		_, _ = memo.ReadVersions(ctx, out, modules[i])
+		done++
+		out.Progress("Fetching versions", done, len(modules), start, time.Now())
+	})
+	out.Stop("Fetching versions")
+}
+
+// FindCachedHash returns a module for every version in the memo of digested
+// references that falls in a range of commit hashes, ordered by root and hash.
+// A commit that more than one reference points to, like a tag and a branch,
+// is one module.
+// The modules are not finished, since finishing every candidate would be
+// wasted on all but the one we pick.
+func (memo *Memo) FindCachedHash(min, max plumbing.Hash, test bool) Modules {
+	roots := make([]string, 0, len(memo.Versions))
+	for root := range memo.Versions {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+
+	var modules Modules
+	for _, root := range roots {
+		seen := make(map[plumbing.Hash]struct{})
+		var hashes []plumbing.Hash
+		for _, hash := range memo.Versions[root] {
+			if _, ok := seen[hash]; ok || !HashBetween(min, hash, max) {
+				continue
+			}
+			seen[hash] = struct{}{}
+			hashes = append(hashes, hash)
+		}
+		sort.Slice(hashes, func(i, j int) bool {
+			return HashBefore(hashes[i], hashes[j])
+		})
+		for _, hash := range hashes {
+			modules = append(modules, Module{
+				Name: memo.nameForRoot(root),
+				Root: root,
+				Hash: hash,
+				Test: test,
+			})
+		}
+	}
+	return modules
+}
+
+// nameForRoot returns the name of a module that we know comes from the given
+// vendor root, preferring the name we read versions under, or else the
+// shortest name with a remote for that root, or else the root itself.
+func (memo *Memo) nameForRoot(root string) string {
+	if modules := memo.FinishedVersions[root]; len(modules) > 0 {
+		return modules[0].Name
+	}
+	name := ""
+	for pkg, remote := range memo.Remotes {
+		if RootForRemote(remote) != root {
+			continue
+		}
+		if name == "" || len(pkg) < len(name) || len(pkg) == len(name) && pkg < name {
+			name = pkg
+		}
+	}
+	if name == "" {
+		return root
+	}
+	return name
 }
 
 func (memo *Memo) readGlideLock(module *Module) (*GlideLock, error) {
@@ -806,6 +988,118 @@ func (memo *Memo) readDepLock(module *Module) (*DepLock, error) {
 	return deplock, err
 }
 
+func (memo *Memo) readModLock(module *Module) (*ModLock, error) {
+	repo := memo.Repository
+
+	blob, err := repo.BlobObject(module.Modlock)
+	if err != nil {
+		return nil, fmt.Errorf("error reading go.mod for commit %s: %s", module.Hash, err)
+	}
+
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("error reading go.mod for commit %s: %s", module.Hash, err)
+	}
+	defer func() {
+		err = multierr.Append(err, reader.Close())
+	}()
+
+	bytes, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading go.mod for commit %s: %s", module.Hash, err)
+	}
+
+	modlock, err := ReadModLock(bytes)
+
+	return modlock, err
+}
+
+// ReadGitLog walks the commit graph in the cache between two commits.
+func (memo *Memo) ReadGitLog(old, new plumbing.Hash) (GitLog, error) {
+	return ReadGitLog(memo.Repository, old, new)
+}
+
+// ReadGitAPI reads the exported API of the packages of a module from the
+// cache.
+func (memo *Memo) ReadGitAPI(ctx context.Context, out ProgressWriter, module Module) (API, []string, error) {
+	commit, err := memo.Commit(ctx, out, module.Hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	tree, err := memo.Repository.TreeObject(commit.TreeHash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error attempting to get a Git tree to read the API of %s: %s", module.Summary(), err)
+	}
+	return ReadGitAPI(memo.Repository, tree, module.Name)
+}
+
+// ReadChangelog reads the CHANGELOG.md of a module from the cache.
+func (memo *Memo) ReadChangelog(module Module) (string, error) {
+	repo := memo.Repository
+
+	blob, err := repo.BlobObject(module.Changelog)
+	if err != nil {
+		return "", fmt.Errorf("error reading CHANGELOG.md for commit %s: %s", module.Hash, err)
+	}
+
+	reader, err := blob.Reader()
+	if err != nil {
+		return "", fmt.Errorf("error reading CHANGELOG.md for commit %s: %s", module.Hash, err)
+	}
+	defer func() {
+		err = multierr.Append(err, reader.Close())
+	}()
+
+	bytes, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("error reading CHANGELOG.md for commit %s: %s", module.Hash, err)
+	}
+
+	return string(bytes), nil
+}
+
+// ResolveModVersion finds the commit hash for a module version from a go.mod,
+// fetching the module if necessary.
+// The module must come from moduleFromModVersion, possibly with an overridden
+// remote.
+// The given version is the source of the module, which differs from the
+// module only if the go.mod replaces it.
+// ResolveModVersion finds tags by version or subdirectory and version, and
+// pseudo-versions by their abbreviated commit hash.
+// The module is not finished.
+func (memo *Memo) ResolveModVersion(ctx context.Context, out ProgressWriter, module *Module, version ModVersion) error {
+	if err := memo.FinishRemote(ctx, out, module); err != nil {
+		return err
+	}
+
+	if err := memo.Fetch(ctx, out, module, FetchMaxAttempts); err != nil {
+		fmt.Fprintf(out, "warning: Failed to fetch for %s: %s\n", module.Summary(), err)
+	}
+
+	if _, prefix, ok := ParseModPseudoVersion(version.Version); ok {
+		hash, err := GitResolveHashPrefix(out, memo.GitDir, prefix)
+		if err != nil {
+			return fmt.Errorf("cannot find version %s of %s: %s", version.Version, version.Path, err)
+		}
+		module.Hash = hash
+		return nil
+	}
+
+	refs := []string{module.Ref}
+	if subdirectory := modPathSubdirectory(module.Name, version.Path); subdirectory != "" {
+		refs = append([]string{"tags/" + subdirectory + "/" + strings.TrimPrefix(module.Ref, "tags/")}, refs...)
+	}
+	for _, ref := range refs {
+		name := plumbing.ReferenceName("refs/vendor/" + module.Root + "/" + ref)
+		if reference, err := memo.Repository.Reference(name, true); err == nil {
+			module.Hash = reference.Hash()
+			module.Ref = ref
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot find version %s of %s at %s", version.Version, version.Path, module.Remote)
+}
+
 // ReadOwnPackages returns the working copy's memoized package name and
 // packages.  The returned packages are not mutable.
 func (memo *Memo) ReadOwnPackages(ctx context.Context, out ProgressWriter) (string, Packages, error) {
@@ -823,6 +1117,8 @@ func (memo *Memo) ReadOwnPackages(ctx context.Context, out ProgressWriter) (stri
 // FindModule finds a module that satisfies the given spec and test requirement.
 // The spec is at least a package name, optionally followed by @version, @ref, or @hash.
 // Without a specific version, FindModule will take recommended packages from gg.toml.
+// FindModule never finds versions that gg.toml excludes, and refuses a spec
+// that names one.
 func (memo *Memo) FindModule(ctx context.Context, out ProgressWriter, spec string, test bool) (Module, error) {
 	parts := strings.SplitN(spec, "@", 2)
 	name := parts[0]
@@ -831,6 +1127,10 @@ func (memo *Memo) FindModule(ctx context.Context, out ProgressWriter, spec strin
 		ref = parts[1]
 	}
 
+	if exclusion, ok := memo.Exclusions.ExcludesSpec(name, ref); ref != "" && ok {
+		return Module{Name: name}, fmt.Errorf("version %s of %s is excluded by the %s", ref, name, exclusion.Reason())
+	}
+
 	min, max := ParseHashPrefix(ref)
 
 	module := Module{
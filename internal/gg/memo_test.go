@@ -0,0 +1,55 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+func TestFindCachedHash(t *testing.T) {
+	careyTag := plumbing.NewHash("abc1000000000000000000000000000000000000")
+	careyOld := plumbing.NewHash("abc0000000000000000000000000000000000000")
+	emery := plumbing.NewHash("abc2000000000000000000000000000000000000")
+	other := plumbing.NewHash("def0000000000000000000000000000000000000")
+
+	memo := &Memo{
+		Versions: map[string][]plumbing.Hash{
+			// A tag and a branch point to the same commit.
+			"carey": {careyTag, other, careyTag, careyOld},
+			"emery": {emery},
+		},
+		FinishedVersions: map[string]Modules{
+			"carey": {{Name: "example.com/carey", Root: "carey"}},
+		},
+	}
+
+	min, max := ParseHashPrefix("abc")
+	assert.Equal(t, Modules{
+		{Name: "example.com/carey", Root: "carey", Hash: careyOld, Test: true},
+		{Name: "example.com/carey", Root: "carey", Hash: careyTag, Test: true},
+		{Name: "emery", Root: "emery", Hash: emery, Test: true},
+	}, memo.FindCachedHash(min, max, true))
+
+	min, max = ParseHashPrefix("abc1")
+	assert.Equal(t, Modules{
+		{Name: "example.com/carey", Root: "carey", Hash: careyTag},
+	}, memo.FindCachedHash(min, max, false))
+
+	min, max = ParseHashPrefix("0123")
+	assert.Empty(t, memo.FindCachedHash(min, max, false))
+}
@@ -31,6 +31,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"go.uber.org/multierr"
 )
 
 const (
@@ -70,15 +72,21 @@ type AddMissingProgress interface {
 // The adder does not attempt to isolate the semantic version that provides
 // compatible types for the packages that import a package.
 // Otherwise, the adder will use the master branch.
+// The adder will not add modules that a policy holds, and will add the head of
+// the branch for modules that a policy has track a branch, reporting both.
 // The adder will visit every transitive dependency in the solution, even
 // as it adds modules to the solution.
-func AddMissing(ctx context.Context, loader AddMissingLoader, out AddMissingProgress, state *State, name string, packages Packages, recommended map[string]Version) (*State, error) {
+// The adder does not stop for packages it cannot find a module for, or modules
+// it cannot fetch, but returns the state it arrives at with a multi-error that
+// accounts for every such package and module.
+func AddMissing(ctx context.Context, loader AddMissingLoader, out AddMissingProgress, state *State, name string, packages Packages, recommended map[string]Version, policies Policies) (*State, error) {
 	tried := make(StringSet)
+	var errs error
 	out.Start("Adding modules for missing packages")
 
 	modules := state.Modules()
 	if err := loader.FinishPackages(ctx, out, modules); err != nil {
-		return nil, err
+		return state, err
 	}
 	max := maxExports(modules, 0)
 	start := time.Now()
@@ -86,38 +94,42 @@ func AddMissing(ctx context.Context, loader AddMissingLoader, out AddMissingProg
 	for {
 		select {
 		case <-ctx.Done():
-			return nil, ctx.Err()
+			return state, multierr.Append(errs, ctx.Err())
 		default:
 		}
 
 		modules := state.Modules()
 		if err := loader.FinishPackages(ctx, out, modules); err != nil {
-			return nil, err
+			return state, AppendUniqueError(errs, err)
 		}
 		max = maxExports(modules, max)
 		imports, testImports := MissingPackages(packages, modules.Packages())
 		missingProgress(out, state, modules, imports, testImports, max, start)
-		if next, ok := addOneMissingModule(ctx, loader, out, state, tried, name, imports, false, recommended); ok {
-			state = next
+		next, ok, err := addOneMissingModule(ctx, loader, out, state, tried, name, imports, false, recommended, policies)
+		errs = AppendUniqueError(errs, err)
+		state = next
+		if ok {
 			continue
 		}
 
 		modules = state.Modules()
 		if err := loader.FinishPackages(ctx, out, modules); err != nil {
-			return nil, err
+			return state, AppendUniqueError(errs, err)
 		}
 		max = maxExports(modules, max)
 		imports, testImports = MissingPackages(packages, modules.Packages())
 		missingProgress(out, state, modules, imports, testImports, max, start)
-		if next, ok := addOneMissingModule(ctx, loader, out, state, tried, name, testImports, true, recommended); ok {
-			state = next
+		next, ok, err = addOneMissingModule(ctx, loader, out, state, tried, name, testImports, true, recommended, policies)
+		errs = AppendUniqueError(errs, err)
+		state = next
+		if ok {
 			continue
 		}
 		break
 	}
 
 	out.Stop("Adding modules for missing packages")
-	return state, nil
+	return state, errs
 }
 
 func maxExports(modules Modules, max int) int {
@@ -138,7 +150,13 @@ func missingProgress(out AddMissingProgress, state *State, modules Modules, impo
 	out.Progress("Adding modules for missing packages", num, tot, start, now)
 }
 
-func addOneMissingModule(ctx context.Context, loader AddMissingLoader, out AddMissingProgress, state *State, tried StringSet, ownPackage string, packages StringSet, test bool, recommended map[string]Version) (*State, bool) {
+// addOneMissingModule adds a module for the first of the given packages that
+// it has not tried before and can find a module for.
+// Returns the new state, whether it made progress, and errors for the packages
+// it could not find a module for because a candidate module failed to fetch or
+// had no suitable version.
+func addOneMissingModule(ctx context.Context, loader AddMissingLoader, out AddMissingProgress, state *State, tried StringSet, ownPackage string, packages StringSet, test bool, recommended map[string]Version, policies Policies) (*State, bool, error) {
+	var errs error
 Scan:
 	for _, name := range packages.Keys() {
 		if tried.Has(name) {
@@ -153,6 +171,8 @@ Scan:
 		}
 
 		parts := strings.Split(name, "/")
+		missing := name
+		var failures error
 
 		// Skip any package that *should* be exported by a module in the
 		// solution.
@@ -180,6 +200,7 @@ Scan:
 			}
 			if err := loader.Fetch(ctx, out, &module, AddMissingFetchMaxAttempts); err != nil {
 				fmt.Fprintf(out, "Error while fetching versions for %s: %s\n", module.Name, err)
+				failures = multierr.Append(failures, fmt.Errorf("cannot fetch %s: %s", module.Name, err))
 			}
 			if err := loader.DigestRefs(ctx, out, module); err != nil {
 				fmt.Fprintf(out, "Error while digesting reference for %s: %s\n", module.Name, err)
@@ -187,42 +208,80 @@ Scan:
 			versions, err := loader.ReadVersions(ctx, out, module)
 			if err != nil {
 				fmt.Fprintf(out, "Error while reading versions for %s: %s\n", module.Name, err)
+				failures = multierr.Append(failures, fmt.Errorf("cannot read versions of %s: %s", module.Name, err))
+				errs = multierr.Append(errs, missingPackageError(missing, failures))
 				continue Scan
 			}
 
 			var ok bool
 			var add Module
-			if version := recommended[module.Name]; version != NoVersion {
+			policy := policies.Find(module.Name)
+			if policy.Mode == PolicyHold && len(versions) > 0 {
+				fmt.Fprintf(out, "Skipping %s for package %s, held by the %s.\n", module.Name, missing, policy.Reason())
+				continue Scan
+			} else if policy.Mode == PolicyTrackBranch {
+				add, ok = versions.FindBranch(policy.Branch)
+				if !ok && len(versions) > 0 {
+					fmt.Fprintf(out, "Skipping %s for package %s, which has no branch %s for the %s.\n", module.Name, missing, policy.Branch, policy.Reason())
+					continue Scan
+				}
+			} else if version := recommended[module.Name]; version != NoVersion {
 				add, ok = versions.FindVersion(version)
 			} else {
 				add, ok = versions.FindBestVersion()
 			}
 
 			if ok {
-				if next, err := state.Add(ctx, loader, out, add); err != nil {
+				next, err := state.Add(ctx, loader, out, add)
+				if err != nil {
 					fmt.Fprintf(out, "%s\n", err)
+					errs = AppendUniqueError(errs, err)
 				} else {
 					fmt.Fprintf(out, "+ %s\n", add.String())
-					state = next
+				}
+				if prior, moved, policy, ok := policyViolation(state, next, policies, add.Name); ok {
+					fmt.Fprintf(out, "Warning: adding %s moved %s to %s against the %s.\n", add.Summary(), prior.Summary(), moved.Summary(), policy.Reason())
 				}
 				// We return instead of continue because this function should
 				// only advance one package forward from the set of missing
 				// packages, so we can provide progress notifications for every
 				// added module.
-				return state, true
+				return next, true, errs
+			}
+
+			if len(versions) > 0 {
+				failures = multierr.Append(failures, fmt.Errorf("no suitable version of %s", module.Name))
 			}
 
 			if module.ExactRemote {
+				errs = multierr.Append(errs, missingPackageError(missing, failures))
 				continue Scan
 			}
 
 			fmt.Fprintf(out, "Could not find a suitable version for %s.\n", name)
 			parts = parts[:len(parts)-1]
 			if len(parts) < 2 {
+				errs = multierr.Append(errs, missingPackageError(missing, failures))
 				continue Scan
 			}
 			fmt.Fprintf(out, "Trying a shorter package name: %s.\n", strings.Join(parts, "/"))
 		}
 	}
-	return state, false
+	return state, false, errs
+}
+
+// missingPackageError explains why no module could be added for a package, or
+// returns nil if no candidate module failed.
+// Packages that no candidate module claims are not failures, since they may
+// be satisfied by other means, like the GOPATH.
+func missingPackageError(name string, failures error) error {
+	if failures == nil {
+		return nil
+	}
+	reasons := multierr.Errors(failures)
+	messages := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		messages = append(messages, reason.Error())
+	}
+	return fmt.Errorf("cannot find a module for package %s: %s", name, strings.Join(messages, ", "))
 }
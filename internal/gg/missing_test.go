@@ -16,10 +16,13 @@ package gg
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/multierr"
 )
 
 func TestAddMissing(t *testing.T) {
@@ -57,7 +60,7 @@ func TestAddMissing(t *testing.T) {
 	recommended := map[string]Version{
 		"example.com/blake": Version{1, 0, 0},
 	}
-	next, err := AddMissing(ctx, loader, progress, state, name, packages, recommended)
+	next, err := AddMissing(ctx, loader, progress, state, name, packages, recommended, nil)
 	require.NoError(t, err)
 
 	modules := Modules{
@@ -89,7 +92,7 @@ func TestAddMissingFavorNonTest(t *testing.T) {
 	packages.TestImport("example.com/avery", "example.com/carey/test")
 
 	var recommended map[string]Version
-	next, err := AddMissing(ctx, loader, progress, state, name, packages, recommended)
+	next, err := AddMissing(ctx, loader, progress, state, name, packages, recommended, nil)
 	require.NoError(t, err)
 
 	modules := Modules{
@@ -101,3 +104,93 @@ func TestAddMissingFavorNonTest(t *testing.T) {
 
 	assert.Equal(t, modules, next.Modules())
 }
+
+func TestAddMissingPolicies(t *testing.T) {
+	ctx := context.Background()
+
+	loader := NewFakeLoader(Modules{
+		{Name: "example.com/blake", Version: Version{1, 0, 0}},
+		{Name: "example.com/blake", Ref: "heads/develop", Time: time.Unix(1, 0)},
+		{Name: "example.com/carey", Version: Version{1, 0, 0}},
+	})
+	progress := &LogSolverProgress{}
+	state := NewState()
+
+	name := "example.com/avery"
+	packages := NewPackages()
+	packages.Command("example.com/avery")
+	packages.Import("example.com/avery", "example.com/blake")
+	packages.Import("example.com/avery", "example.com/carey")
+
+	policies := Policies{
+		{Pattern: "example.com/carey", Match: PatternSplit("example.com/carey"), Mode: PolicyHold},
+		{Pattern: "example.com/*", Match: PatternSplit("example.com/*"), Mode: PolicyTrackBranch, Branch: "develop"},
+	}
+	next, err := AddMissing(ctx, loader, progress, state, name, packages, nil, policies)
+	require.NoError(t, err)
+
+	modules := Modules{
+		{Name: "example.com/blake", Ref: "heads/develop", Time: time.Unix(1, 0)},
+	}
+	err = loader.FinishModules(ctx, progress, modules)
+	require.NoError(t, err)
+
+	assert.Equal(t, modules, next.Modules())
+}
+
+type unreachableLoader struct {
+	FakeLoader
+	unreachable StringSet
+}
+
+func (l unreachableLoader) Fetch(_ context.Context, _ ProgressWriter, module *Module, _ int) error {
+	if l.unreachable.Has(module.Name) {
+		return fmt.Errorf("remote unreachable")
+	}
+	return nil
+}
+
+func TestAddMissingFailures(t *testing.T) {
+	ctx := context.Background()
+
+	loader := unreachableLoader{
+		FakeLoader: NewFakeLoader(Modules{
+			{Name: "example.com/blake", Ref: "heads/master"},
+		}),
+		unreachable: NewStringSet([]string{"example.com/carey", "example.com/carey/command", "example.com/drew"}),
+	}
+	progress := &LogSolverProgress{}
+	state := NewState()
+
+	name := "example.com/avery"
+	packages := NewPackages()
+	packages.Command("example.com/avery")
+	packages.Import("example.com/avery", "example.com/blake")
+	packages.Import("example.com/avery", "example.com/carey/command")
+	packages.Import("example.com/avery", "example.com/drew")
+	packages.Import("example.com/avery", "example.com/bogus")
+
+	var recommended map[string]Version
+	next, err := AddMissing(ctx, loader, progress, state, name, packages, recommended, nil)
+	require.Error(t, err)
+	assert.Equal(t, []string{
+		"cannot find a module for package example.com/carey/command: cannot fetch example.com/carey/command: remote unreachable, cannot fetch example.com/carey: remote unreachable",
+		"cannot find a module for package example.com/drew: cannot fetch example.com/drew: remote unreachable",
+	}, errorMessages(err))
+
+	modules := Modules{
+		{Name: "example.com/blake"},
+	}
+	err = loader.FinishModules(ctx, progress, modules)
+	require.NoError(t, err)
+
+	assert.Equal(t, modules, next.Modules())
+}
+
+func errorMessages(err error) []string {
+	var messages []string
+	for _, err := range multierr.Errors(err) {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
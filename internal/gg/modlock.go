@@ -0,0 +1,445 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+// ModLock is a model of the directives in a go.mod that gg can use.
+// A go.mod does not capture commit hashes, so gg must look up the commit for
+// every required version in the module's git repository.
+type ModLock struct {
+	Module   string
+	Go       string
+	Requires []ModRequirement
+	Replaces []ModReplacement
+	Excludes []ModVersion
+}
+
+// ModVersion is a module path and version pair, as used throughout go.mod and
+// go.sum.
+type ModVersion struct {
+	Path    string
+	Version string
+}
+
+// ModRequirement models a require directive in a go.mod.
+type ModRequirement struct {
+	ModVersion
+	// Indirect indicates that the working copy does not import any package
+	// from the module directly.
+	Indirect bool
+}
+
+// ModReplacement models a replace directive in a go.mod.
+// The old version is empty if the replacement applies to every version.
+// The new version is empty if the replacement is a local directory.
+type ModReplacement struct {
+	Old ModVersion
+	New ModVersion
+}
+
+// ModSum models a line in a go.sum.
+type ModSum struct {
+	ModVersion
+	// Hash is the checksum, like "h1:...".
+	Hash string
+}
+
+// ReadOwnModLock reads the go.mod in the working directory.
+func ReadOwnModLock() (*ModLock, error) {
+	bytes, err := ioutil.ReadFile("go.mod")
+	if err != nil {
+		return &ModLock{}, err
+	}
+	return ReadModLock(bytes)
+}
+
+// ReadOwnModSum reads the go.sum in the working directory.
+func ReadOwnModSum() ([]ModSum, error) {
+	bytes, err := ioutil.ReadFile("go.sum")
+	if err != nil {
+		return nil, err
+	}
+	return ReadModSum(bytes)
+}
+
+// WriteOwnModLock writes a go.mod in the working copy.
+func WriteOwnModLock(lock *ModLock) error {
+	return ioutil.WriteFile("go.mod", WriteModLock(lock), 0644)
+}
+
+// WriteOwnModSum writes a go.sum in the working copy.
+func WriteOwnModSum(sums []ModSum) error {
+	return ioutil.WriteFile("go.sum", WriteModSum(sums), 0644)
+}
+
+// ReadModLock parses the go.mod format from the given data.
+// ReadModLock ignores directives that have no bearing on the dependency
+// solution, like retract.
+func ReadModLock(bytes []byte) (*ModLock, error) {
+	lock := &ModLock{}
+	block := ""
+	for number, line := range strings.Split(string(bytes), "\n") {
+		comment := ""
+		if index := strings.Index(line, "//"); index >= 0 {
+			comment = strings.TrimSpace(line[index+2:])
+			line = line[:index]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+			fields = append([]string{block}, fields...)
+		} else if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		if err := lock.readDirective(fields, comment == "indirect"); err != nil {
+			return lock, fmt.Errorf("go.mod:%d: %s", number+1, err)
+		}
+	}
+	return lock, nil
+}
+
+func (lock *ModLock) readDirective(fields []string, indirect bool) error {
+	for i, field := range fields {
+		if strings.HasPrefix(field, `"`) {
+			unquoted, err := strconv.Unquote(field)
+			if err != nil {
+				return fmt.Errorf("invalid quoted string %s", field)
+			}
+			fields[i] = unquoted
+		}
+	}
+	switch fields[0] {
+	case "module":
+		if len(fields) != 2 {
+			return fmt.Errorf("usage: module path")
+		}
+		lock.Module = fields[1]
+	case "go":
+		if len(fields) != 2 {
+			return fmt.Errorf("usage: go 1.11")
+		}
+		lock.Go = fields[1]
+	case "require":
+		if len(fields) != 3 {
+			return fmt.Errorf("usage: require module/path v1.2.3")
+		}
+		lock.Requires = append(lock.Requires, ModRequirement{
+			ModVersion: ModVersion{Path: fields[1], Version: fields[2]},
+			Indirect:   indirect,
+		})
+	case "exclude":
+		if len(fields) != 3 {
+			return fmt.Errorf("usage: exclude module/path v1.2.3")
+		}
+		lock.Excludes = append(lock.Excludes, ModVersion{Path: fields[1], Version: fields[2]})
+	case "replace":
+		var replace ModReplacement
+		switch {
+		case len(fields) == 4 && fields[2] == "=>":
+			replace.Old = ModVersion{Path: fields[1]}
+			replace.New = ModVersion{Path: fields[3]}
+		case len(fields) == 5 && fields[2] == "=>":
+			replace.Old = ModVersion{Path: fields[1]}
+			replace.New = ModVersion{Path: fields[3], Version: fields[4]}
+		case len(fields) == 5 && fields[3] == "=>":
+			replace.Old = ModVersion{Path: fields[1], Version: fields[2]}
+			replace.New = ModVersion{Path: fields[4]}
+		case len(fields) == 6 && fields[3] == "=>":
+			replace.Old = ModVersion{Path: fields[1], Version: fields[2]}
+			replace.New = ModVersion{Path: fields[4], Version: fields[5]}
+		default:
+			return fmt.Errorf("usage: replace module/path [v1.2.3] => other/module v1.4.5 or ../local/directory")
+		}
+		lock.Replaces = append(lock.Replaces, replace)
+	}
+	return nil
+}
+
+// WriteModLock renders a ModLock in the go.mod format, as the go command would
+// format it.
+func WriteModLock(lock *ModLock) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "module %s\n", modQuote(lock.Module))
+	if lock.Go != "" {
+		fmt.Fprintf(&buf, "\ngo %s\n", lock.Go)
+	}
+
+	if len(lock.Requires) > 0 {
+		lines := make([]string, 0, len(lock.Requires))
+		for _, require := range lock.Requires {
+			line := modQuote(require.Path) + " " + require.Version
+			if require.Indirect {
+				line += " // indirect"
+			}
+			lines = append(lines, line)
+		}
+		writeModDirective(&buf, "require", lines)
+	}
+
+	if len(lock.Excludes) > 0 {
+		lines := make([]string, 0, len(lock.Excludes))
+		for _, exclude := range lock.Excludes {
+			lines = append(lines, modQuote(exclude.Path)+" "+exclude.Version)
+		}
+		writeModDirective(&buf, "exclude", lines)
+	}
+
+	if len(lock.Replaces) > 0 {
+		lines := make([]string, 0, len(lock.Replaces))
+		for _, replace := range lock.Replaces {
+			line := modQuote(replace.Old.Path)
+			if replace.Old.Version != "" {
+				line += " " + replace.Old.Version
+			}
+			line += " => " + modQuote(replace.New.Path)
+			if replace.New.Version != "" {
+				line += " " + replace.New.Version
+			}
+			lines = append(lines, line)
+		}
+		writeModDirective(&buf, "replace", lines)
+	}
+
+	return buf.Bytes()
+}
+
+// writeModDirective writes a directive on a single line, or in a block if
+// there are several.
+func writeModDirective(buf *bytes.Buffer, verb string, lines []string) {
+	if len(lines) == 1 {
+		fmt.Fprintf(buf, "\n%s %s\n", verb, lines[0])
+		return
+	}
+	fmt.Fprintf(buf, "\n%s (\n", verb)
+	for _, line := range lines {
+		fmt.Fprintf(buf, "\t%s\n", line)
+	}
+	fmt.Fprintf(buf, ")\n")
+}
+
+// modQuote quotes a path in go.mod if it would not survive as a bare word.
+func modQuote(path string) string {
+	if path == "" || strings.ContainsAny(path, " \t\r\n\"'`()[]{},") || strings.Contains(path, "//") {
+		return strconv.Quote(path)
+	}
+	return path
+}
+
+// ReadModSum parses the go.sum format from the given data.
+func ReadModSum(bytes []byte) ([]ModSum, error) {
+	var sums []ModSum
+	for number, line := range strings.Split(string(bytes), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return sums, fmt.Errorf("go.sum:%d: expected module path, version, and hash", number+1)
+		}
+		sums = append(sums, ModSum{
+			ModVersion: ModVersion{Path: fields[0], Version: fields[1]},
+			Hash:       fields[2],
+		})
+	}
+	return sums, nil
+}
+
+// WriteModSum renders go.sum lines in the given order.
+func WriteModSum(sums []ModSum) []byte {
+	var buf bytes.Buffer
+	for _, sum := range sums {
+		fmt.Fprintf(&buf, "%s %s %s\n", sum.Path, sum.Version, sum.Hash)
+	}
+	return buf.Bytes()
+}
+
+// Replacement returns the replace directive that applies to the given module
+// version, if any.
+// A replacement for an exact version takes precedence over a replacement for
+// all versions.
+func (lock *ModLock) Replacement(version ModVersion) (ModReplacement, bool) {
+	var found ModReplacement
+	var ok bool
+	for _, replace := range lock.Replaces {
+		if replace.Old.Path != version.Path {
+			continue
+		}
+		if replace.Old.Version == version.Version {
+			return replace, true
+		}
+		if replace.Old.Version == "" {
+			found = replace
+			ok = true
+		}
+	}
+	return found, ok
+}
+
+// Required returns whether the go.mod requires exactly the given module
+// version.
+func (lock *ModLock) Required(version ModVersion) bool {
+	for _, require := range lock.Requires {
+		if require.ModVersion == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Excluded returns whether an exclude directive forbids the given module
+// version.
+func (lock *ModLock) Excluded(version ModVersion) bool {
+	for _, exclude := range lock.Excludes {
+		if exclude == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Requirements returns the module versions the go.mod requires, amended by
+// any module with a source checksum in the corresponding go.sum that the
+// go.mod does not mention.
+// Before Go 1.17, go.mod only needed to mention direct dependencies, but the
+// go command records a checksum for the source of every module in the build
+// list, including transitive dependencies that predate go.mod themselves.
+// Requirements takes the newest version with a source checksum for each such
+// module.
+// Requirements omits excluded versions found in go.sum, but not excluded
+// versions the go.mod requires outright.
+func (lock *ModLock) Requirements(sums []ModSum) []ModVersion {
+	versions := make([]ModVersion, 0, len(lock.Requires))
+	required := make(StringSet, len(lock.Requires))
+	for _, require := range lock.Requires {
+		versions = append(versions, require.ModVersion)
+		required.Add(require.Path)
+	}
+
+	newest := make(map[string]string)
+	for _, sum := range sums {
+		if strings.HasSuffix(sum.Version, "/go.mod") {
+			continue
+		}
+		if required.Has(sum.Path) || lock.Excluded(sum.ModVersion) {
+			continue
+		}
+		if prior, ok := newest[sum.Path]; !ok || ModVersionBefore(prior, sum.Version) {
+			newest[sum.Path] = sum.Version
+		}
+	}
+	for _, path := range StringMapKeys(newest) {
+		versions = append(versions, ModVersion{Path: path, Version: newest[path]})
+	}
+	return versions
+}
+
+// ParseModVersion returns the version number for a go.mod version like
+// "v1.2.3" or "v2.0.0+incompatible", or NoVersion if the version has a
+// pre-release suffix or is a pseudo-version.
+func ParseModVersion(version string) Version {
+	return ParseVersion(strings.TrimSuffix(version, "+incompatible"))
+}
+
+// ParseModPseudoVersion extracts the commit timestamp and abbreviated commit
+// hash from a go.mod pseudo-version like "v0.0.0-20180929084145-3fe1cac16289",
+// and whether the version is a pseudo-version at all.
+func ParseModPseudoVersion(version string) (time.Time, string, bool) {
+	version = strings.TrimSuffix(version, "+incompatible")
+	dash := strings.LastIndex(version, "-")
+	if dash < 0 || len(version)-dash-1 != 12 {
+		return time.Time{}, "", false
+	}
+	hash := version[dash+1:]
+	if _, max := ParseHashPrefix(hash); max == NoHash {
+		return time.Time{}, "", false
+	}
+	rest := version[:dash]
+	if len(rest) < 15 || (rest[len(rest)-15] != '-' && rest[len(rest)-15] != '.') {
+		return time.Time{}, "", false
+	}
+	stamp, err := time.Parse("20060102150405", rest[len(rest)-14:])
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return stamp, hash, true
+}
+
+// ModPseudoVersion returns a go.mod pseudo-version for a commit with the given
+// major version, timestamp, and hash, like
+// "v0.0.0-20180929084145-3fe1cac16289".
+func ModPseudoVersion(major int, stamp time.Time, hash plumbing.Hash) string {
+	return fmt.Sprintf("v%d.0.0-%s-%s", major, stamp.UTC().Format("20060102150405"), hash.String()[:12])
+}
+
+// ModPathMajor returns the major version implied by the suffix of a module
+// path, like 2 for "example.com/module/v2" or "gopkg.in/yaml.v2", or 0 if the
+// path has no major version suffix.
+func ModPathMajor(path string) int {
+	separator := "/v"
+	if strings.HasPrefix(path, "gopkg.in/") {
+		separator = ".v"
+	}
+	index := strings.LastIndex(path, separator)
+	if index < 0 {
+		return 0
+	}
+	major, err := strconv.Atoi(path[index+2:])
+	if err != nil || major < 0 {
+		return 0
+	}
+	return major
+}
+
+// ModVersionBefore returns whether a go.mod version precedes another.
+// Versions are ordered by their version number, and versions with the same
+// number by their pre-release or pseudo-version suffix, which sort
+// lexically, but before the release itself.
+func ModVersionBefore(a, b string) bool {
+	a = strings.TrimSuffix(a, "+incompatible")
+	b = strings.TrimSuffix(b, "+incompatible")
+	aNumber, aSuffix := splitModVersion(a)
+	bNumber, bSuffix := splitModVersion(b)
+	if aNumber != bNumber {
+		return aNumber.Before(bNumber)
+	}
+	if aSuffix == "" || bSuffix == "" {
+		return aSuffix != "" && bSuffix == ""
+	}
+	return aSuffix < bSuffix
+}
+
+func splitModVersion(version string) (Version, string) {
+	if index := strings.Index(version, "-"); index >= 0 {
+		return ParseVersion(version[:index]), version[index+1:]
+	}
+	return ParseVersion(version), ""
+}
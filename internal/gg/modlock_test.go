@@ -0,0 +1,248 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+func TestReadModLock(t *testing.T) {
+	lock, err := ReadModLock([]byte(`module example.com/own // own module
+
+go 1.12
+
+require github.com/pkg/errors v0.8.1
+
+require (
+	go.uber.org/multierr v1.1.0
+	golang.org/x/net v0.0.0-20190311183353-d8887717615a // indirect
+	"gopkg.in/yaml.v2" v2.2.2
+)
+
+exclude go.uber.org/atomic v1.3.0
+
+replace (
+	golang.org/x/net => github.com/golang/net v0.0.0-20190311183353-d8887717615a
+	example.com/local v1.0.0 => ../local
+)
+
+retract v1.0.0
+`))
+	require.NoError(t, err)
+	assert.Equal(t, &ModLock{
+		Module: "example.com/own",
+		Go:     "1.12",
+		Requires: []ModRequirement{
+			{ModVersion: ModVersion{Path: "github.com/pkg/errors", Version: "v0.8.1"}},
+			{ModVersion: ModVersion{Path: "go.uber.org/multierr", Version: "v1.1.0"}},
+			{ModVersion: ModVersion{Path: "golang.org/x/net", Version: "v0.0.0-20190311183353-d8887717615a"}, Indirect: true},
+			{ModVersion: ModVersion{Path: "gopkg.in/yaml.v2", Version: "v2.2.2"}},
+		},
+		Replaces: []ModReplacement{
+			{
+				Old: ModVersion{Path: "golang.org/x/net"},
+				New: ModVersion{Path: "github.com/golang/net", Version: "v0.0.0-20190311183353-d8887717615a"},
+			},
+			{
+				Old: ModVersion{Path: "example.com/local", Version: "v1.0.0"},
+				New: ModVersion{Path: "../local"},
+			},
+		},
+		Excludes: []ModVersion{
+			{Path: "go.uber.org/atomic", Version: "v1.3.0"},
+		},
+	}, lock)
+}
+
+func TestReadModLockError(t *testing.T) {
+	_, err := ReadModLock([]byte("module a\nrequire b\n"))
+	assert.EqualError(t, err, "go.mod:2: usage: require module/path v1.2.3")
+}
+
+func TestModLockRequirements(t *testing.T) {
+	lock := &ModLock{
+		Requires: []ModRequirement{
+			{ModVersion: ModVersion{Path: "a", Version: "v1.0.0"}},
+		},
+		Excludes: []ModVersion{
+			{Path: "c", Version: "v1.2.0"},
+		},
+	}
+	sums, err := ReadModSum([]byte(`a v0.9.0 h1:a=
+a v1.0.0 h1:b=
+b v1.0.0 h1:c=
+b v1.0.0/go.mod h1:d=
+b v1.1.0-rc.1 h1:e=
+b v1.1.0/go.mod h1:f=
+c v1.1.0 h1:g=
+c v1.2.0 h1:h=
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []ModVersion{
+		{Path: "a", Version: "v1.0.0"},
+		{Path: "b", Version: "v1.1.0-rc.1"},
+		{Path: "c", Version: "v1.1.0"},
+	}, lock.Requirements(sums))
+}
+
+func TestModLockReplacement(t *testing.T) {
+	lock := &ModLock{
+		Replaces: []ModReplacement{
+			{Old: ModVersion{Path: "a"}, New: ModVersion{Path: "b", Version: "v1.0.0"}},
+			{Old: ModVersion{Path: "a", Version: "v2.0.0"}, New: ModVersion{Path: "c", Version: "v1.0.0"}},
+		},
+	}
+
+	replace, ok := lock.Replacement(ModVersion{Path: "a", Version: "v1.0.0"})
+	assert.True(t, ok)
+	assert.Equal(t, "b", replace.New.Path)
+
+	replace, ok = lock.Replacement(ModVersion{Path: "a", Version: "v2.0.0"})
+	assert.True(t, ok)
+	assert.Equal(t, "c", replace.New.Path)
+
+	_, ok = lock.Replacement(ModVersion{Path: "b", Version: "v1.0.0"})
+	assert.False(t, ok)
+}
+
+func TestParseModPseudoVersion(t *testing.T) {
+	table := []struct {
+		give   string
+		time   time.Time
+		prefix string
+		ok     bool
+	}{
+		{
+			give:   "v0.0.0-20190311183353-d8887717615a",
+			time:   time.Date(2019, 3, 11, 18, 33, 53, 0, time.UTC),
+			prefix: "d8887717615a",
+			ok:     true,
+		},
+		{
+			give:   "v1.2.4-0.20190311183353-d8887717615a",
+			time:   time.Date(2019, 3, 11, 18, 33, 53, 0, time.UTC),
+			prefix: "d8887717615a",
+			ok:     true,
+		},
+		{
+			give:   "v2.0.1-rc.1.0.20190311183353-d8887717615a+incompatible",
+			time:   time.Date(2019, 3, 11, 18, 33, 53, 0, time.UTC),
+			prefix: "d8887717615a",
+			ok:     true,
+		},
+		{
+			give: "v1.2.3",
+		},
+		{
+			give: "v1.2.3-rc.1",
+		},
+		{
+			give: "v0.0.0-20190311183353-nothexnothex",
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.give, func(t *testing.T) {
+			stamp, prefix, ok := ParseModPseudoVersion(tt.give)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.prefix, prefix)
+			assert.True(t, tt.time.Equal(stamp))
+		})
+	}
+}
+
+func TestModVersionBefore(t *testing.T) {
+	assert.True(t, ModVersionBefore("v1.0.0", "v1.0.1"))
+	assert.True(t, ModVersionBefore("v1.0.0-rc.1", "v1.0.0"))
+	assert.True(t, ModVersionBefore("v1.0.0-rc.1", "v1.0.0-rc.2"))
+	assert.True(t, ModVersionBefore("v1.9.0", "v2.0.0+incompatible"))
+	assert.False(t, ModVersionBefore("v1.0.0", "v1.0.0"))
+	assert.False(t, ModVersionBefore("v1.0.0", "v1.0.0-rc.1"))
+}
+
+func TestModPathSubdirectory(t *testing.T) {
+	assert.Equal(t, "", modPathSubdirectory("github.com/a/b", "github.com/a/b"))
+	assert.Equal(t, "", modPathSubdirectory("github.com/a/b", "github.com/a/b/v2"))
+	assert.Equal(t, "c", modPathSubdirectory("github.com/a/b", "github.com/a/b/c"))
+	assert.Equal(t, "c", modPathSubdirectory("github.com/a/b", "github.com/a/b/c/v3"))
+}
+
+func TestWriteModLock(t *testing.T) {
+	lock := &ModLock{
+		Module: "example.com/own",
+		Go:     "1.11",
+		Requires: []ModRequirement{
+			{ModVersion: ModVersion{Path: "github.com/pkg/errors", Version: "v0.8.1"}},
+			{ModVersion: ModVersion{Path: "golang.org/x/net", Version: "v0.0.0-20190311183353-d8887717615a"}, Indirect: true},
+		},
+		Replaces: []ModReplacement{
+			{
+				Old: ModVersion{Path: "golang.org/x/net"},
+				New: ModVersion{Path: "github.com/golang/net", Version: "v0.0.0-20190311183353-d8887717615a"},
+			},
+		},
+	}
+	want := `module example.com/own
+
+go 1.11
+
+require (
+	github.com/pkg/errors v0.8.1
+	golang.org/x/net v0.0.0-20190311183353-d8887717615a // indirect
+)
+
+replace golang.org/x/net => github.com/golang/net v0.0.0-20190311183353-d8887717615a
+`
+	assert.Equal(t, want, string(WriteModLock(lock)))
+
+	read, err := ReadModLock(WriteModLock(lock))
+	require.NoError(t, err)
+	assert.Equal(t, lock, read)
+}
+
+func TestModLockFromModules(t *testing.T) {
+	stamp := time.Date(2019, 3, 11, 10, 33, 53, 0, time.FixedZone("PST", -8*60*60))
+	modules := Modules{
+		{Name: "example.com/direct", Version: Version{1, 2, 3}, Ref: "tags/v1.2.3"},
+		{Name: "example.com/incompatible", Version: Version{2, 0, 0}, Ref: "tags/v2.0.0"},
+		{Name: "example.com/major/v2", Time: stamp, Hash: plumbing.NewHash("d8887717615a0000000000000000000000000000")},
+		{Name: "example.com/untagged", Version: Version{1, 0, 0}, Ref: "heads/v1", Time: stamp, Hash: plumbing.NewHash("d8887717615a0000000000000000000000000000")},
+		{Name: "gopkg.in/yaml.v2", Version: Version{2, 2, 2}, Ref: "tags/v2.2.2"},
+	}
+	lock := ModLockFromModules("example.com/own", modules, modules[:1])
+	assert.Equal(t, &ModLock{
+		Module: "example.com/own",
+		Requires: []ModRequirement{
+			{ModVersion: ModVersion{Path: "example.com/direct", Version: "v1.2.3"}},
+			{ModVersion: ModVersion{Path: "example.com/incompatible", Version: "v2.0.0+incompatible"}, Indirect: true},
+			{ModVersion: ModVersion{Path: "example.com/major/v2", Version: "v2.0.0-20190311183353-d8887717615a"}, Indirect: true},
+			{ModVersion: ModVersion{Path: "example.com/untagged", Version: "v0.0.0-20190311183353-d8887717615a"}, Indirect: true},
+			{ModVersion: ModVersion{Path: "gopkg.in/yaml.v2", Version: "v2.2.2"}, Indirect: true},
+		},
+	}, lock)
+}
+
+func TestModPathMajor(t *testing.T) {
+	assert.Equal(t, 0, ModPathMajor("example.com/module"))
+	assert.Equal(t, 0, ModPathMajor("example.com/module.v2"))
+	assert.Equal(t, 2, ModPathMajor("example.com/module/v2"))
+	assert.Equal(t, 1, ModPathMajor("gopkg.in/yaml.v1"))
+	assert.Equal(t, 2, ModPathMajor("gopkg.in/yaml.v2"))
+}
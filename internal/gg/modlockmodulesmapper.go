@@ -0,0 +1,94 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import "strings"
+
+// moduleFromModVersion converts a go.mod requirement to GG's internal Module
+// model.
+// A go.mod does not capture the commit hash, so the module must be resolved
+// with Memo.ResolveModVersion before it can be finished.
+func moduleFromModVersion(version ModVersion) Module {
+	module := Module{
+		Name: version.Path,
+	}
+	if stamp, _, ok := ParseModPseudoVersion(version.Version); ok {
+		module.Time = stamp
+	} else {
+		module.Version = ParseModVersion(version.Version)
+		module.Ref = "tags/" + strings.TrimSuffix(version.Version, "+incompatible")
+	}
+	return module
+}
+
+// ModLockFromModules converts GG's internal Modules model into a ModLock
+// model for the named module.
+// The modules in the shallow solution are direct requirements and the rest
+// are indirect.
+func ModLockFromModules(name string, modules, shallow Modules) *ModLock {
+	direct := make(StringSet, len(shallow))
+	for _, module := range shallow {
+		direct.Add(module.Name)
+	}
+	requires := make([]ModRequirement, 0, len(modules))
+	for _, module := range modules {
+		requires = append(requires, ModRequirement{
+			ModVersion: modVersionFromModule(module),
+			Indirect:   !direct.Has(module.Name),
+		})
+	}
+	return &ModLock{
+		Module:   name,
+		Requires: requires,
+	}
+}
+
+// modVersionFromModule renders the go.mod version of a module, using its
+// version number if it has one, or a pseudo-version from its commit timestamp
+// and hash otherwise.
+// The go command only recognizes tags like "v1.2.3", so versions from other
+// tags or branches also render as pseudo-versions.
+// Modules with a version of 2 or more that lack a major version suffix in
+// their path are incompatible with Go modules.
+func modVersionFromModule(module Module) ModVersion {
+	var version string
+	if module.Version != NoVersion && module.Ref == "tags/v"+module.Version.String() {
+		version = "v" + module.Version.String()
+		if module.Version[0] >= 2 && ModPathMajor(module.Name) == 0 {
+			version += "+incompatible"
+		}
+	} else {
+		version = ModPseudoVersion(ModPathMajor(module.Name), module.Time, module.Hash)
+	}
+	return ModVersion{
+		Path:    module.Name,
+		Version: version,
+	}
+}
+
+// modPathSubdirectory returns the directory of a module path within the
+// repository of the given root package, stripping any major version suffix,
+// or the empty string if the module lives at the root of its repository.
+// Tags for a module in a subdirectory are prefixed with the subdirectory, as
+// in "tags/sub/v1.2.3".
+func modPathSubdirectory(name, path string) string {
+	if index := strings.LastIndex(path, "/v"); index >= 0 && ParseVersion(path[index+1:]) != NoVersion {
+		path = path[:index]
+	}
+	if !strings.HasPrefix(path, name+"/") {
+		return ""
+	}
+	return strings.TrimPrefix(path, name+"/")
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// file modsum.go computes go.sum checksums for module versions from git trees,
+// equivalent to the checksums the go command computes from module zip files.
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"go.uber.org/multierr"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+var modSumExcludes = StringSet{
+	".bzr": {},
+	".git": {},
+	".hg":  {},
+	".svn": {},
+}
+
+// ReadGitModSums computes the go.sum entries for the module version in the
+// given git tree: one for the content of the module and one for its go.mod.
+// The module may live in a major version subdirectory of the tree.
+// Like the go command, the content excludes version control directories,
+// nested modules, vendored packages, and anything but regular files.
+// If the module has no go.mod, the go.mod checksum covers the go.mod that the
+// go command would synthesize.
+func ReadGitModSums(repo *git.Repository, tree *object.Tree, version ModVersion) ([]ModSum, error) {
+	tree, err := modSumTree(tree, version.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := version.Path + "@" + version.Version
+	dir, base := "", prefix
+	if index := strings.LastIndex(prefix, "/"); index >= 0 {
+		dir, base = prefix[:index], prefix[index+1:]
+	}
+	walker := Walk(dir, GitEntry{
+		name: base,
+		mode: filemode.Dir,
+		hash: tree.Hash,
+		repo: repo,
+	})
+
+	files := make(map[string][]byte)
+	gomod := []byte(fmt.Sprintf("module %s\n", modQuote(version.Path)))
+	for {
+		path, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if entry, ok := entry.(GitEntry); ok && entry.IsSubmodule() {
+			// Module zip files do not capture submodules.
+			if err == nil {
+				walker.Skip()
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if dir == "" {
+			path = strings.TrimPrefix(path, "/")
+		}
+		name := strings.TrimPrefix(path, prefix+"/")
+		if path == prefix {
+			continue
+		} else if entry.IsDir() {
+			if modSumExcludes.Has(entry.Name()) {
+				walker.Skip()
+			} else if nested, err := hasModLock(entry); err != nil {
+				return nil, err
+			} else if nested {
+				walker.Skip()
+			}
+			continue
+		} else if !isModSumFile(entry) || isVendoredModFile(name) {
+			continue
+		}
+
+		data, err := readTreeEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+		files[path] = data
+		if name == "go.mod" {
+			gomod = data
+		}
+	}
+
+	return []ModSum{
+		{
+			ModVersion: version,
+			Hash:       modHash1(files),
+		},
+		{
+			ModVersion: ModVersion{Path: version.Path, Version: version.Version + "/go.mod"},
+			Hash:       modHash1(map[string][]byte{"go.mod": gomod}),
+		},
+	}, nil
+}
+
+// modSumTree returns the tree of the module with the given path within the
+// tree of its repository.
+// Like the go command, a module with a major version suffix, as in
+// "example.com/example/v2", lives in the major version subdirectory, as in
+// v2/, if that directory has a go.mod for the module, and otherwise at the
+// root of the repository.
+func modSumTree(tree *object.Tree, path string) (*object.Tree, error) {
+	major := ModPathMajor(path)
+	if major < 2 || strings.HasPrefix(path, "gopkg.in/") {
+		return tree, nil
+	}
+	dir := fmt.Sprintf("v%d", major)
+	file, err := tree.File(dir + "/go.mod")
+	if err == object.ErrFileNotFound {
+		return tree, nil
+	} else if err != nil {
+		return nil, err
+	}
+	data, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s/go.mod: %s", dir, err)
+	}
+	lock, err := ReadModLock([]byte(data))
+	if err != nil || lock.Module != path {
+		return tree, nil
+	}
+	return tree.Tree(dir)
+}
+
+// modHash1 computes the "h1:" checksum of a set of named files, as the go
+// command does: the SHA-256 of a summary of the SHA-256 of each file, sorted
+// by name.
+func modHash1(files map[string][]byte) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	summary := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(summary, "%x  %s\n", sha256.Sum256(files[name]), name)
+	}
+	return "h1:" + base64.StdEncoding.EncodeToString(summary.Sum(nil))
+}
+
+// hasModLock returns whether a directory contains a go.mod, which indicates
+// a nested module.
+func hasModLock(entry TreeEntry) (bool, error) {
+	entries, err := entry.List()
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if entry.Name() == "go.mod" && !entry.IsDir() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isModSumFile returns whether the entry is a regular file.
+// Module zip files do not capture symbolic links or submodules.
+func isModSumFile(entry TreeEntry) bool {
+	if entry, ok := entry.(GitEntry); ok {
+		return entry.mode == filemode.Regular || entry.mode == filemode.Executable
+	}
+	return true
+}
+
+// isVendoredModFile returns whether a file within a module belongs to a
+// vendored package, which the go command excludes from module zip files.
+// Files directly within a vendor directory, like vendor/modules.txt, are not
+// in a package.
+func isVendoredModFile(name string) bool {
+	var index int
+	if strings.HasPrefix(name, "vendor/") {
+		index = len("vendor/")
+	} else if found := strings.Index(name, "/vendor/"); found >= 0 {
+		index = found + len("/vendor/")
+	} else {
+		return false
+	}
+	return strings.Contains(name[index:], "/")
+}
+
+func readTreeEntry(entry TreeEntry) (data []byte, err error) {
+	reader, err := entry.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = multierr.Append(err, reader.Close())
+	}()
+	return ioutil.ReadAll(reader)
+}
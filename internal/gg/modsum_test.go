@@ -0,0 +1,138 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/util"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+func TestReadGitModSums(t *testing.T) {
+	files := map[string]string{
+		"go.mod":              "module example.com/example\n",
+		"main.go":             "package main\n",
+		"vendor/modules.txt":  "# example.com/dependency v1.0.0\n",
+		"vendor/a/a.go":       "package a\n",
+		"nested/go.mod":       "module example.com/example/nested\n",
+		"nested/nested.go":    "package nested\n",
+		"internal/example.go": "package internal\n",
+	}
+
+	repo, tree := testModSumTree(t, files)
+
+	sums, err := ReadGitModSums(repo, tree, ModVersion{Path: "example.com/example", Version: "v1.0.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []ModSum{
+		{
+			ModVersion: ModVersion{Path: "example.com/example", Version: "v1.0.0"},
+			Hash: modHash1(map[string][]byte{
+				"example.com/example@v1.0.0/go.mod":              []byte(files["go.mod"]),
+				"example.com/example@v1.0.0/main.go":             []byte(files["main.go"]),
+				"example.com/example@v1.0.0/vendor/modules.txt":  []byte(files["vendor/modules.txt"]),
+				"example.com/example@v1.0.0/internal/example.go": []byte(files["internal/example.go"]),
+			}),
+		},
+		{
+			ModVersion: ModVersion{Path: "example.com/example", Version: "v1.0.0/go.mod"},
+			Hash:       modHash1(map[string][]byte{"go.mod": []byte(files["go.mod"])}),
+		},
+	}, sums)
+}
+
+func TestReadGitModSumsMajorSubdirectory(t *testing.T) {
+	files := map[string]string{
+		"go.mod":     "module example.com/example\n",
+		"main.go":    "package main\n",
+		"v2/go.mod":  "module example.com/example/v2\n",
+		"v2/main.go": "package main // v2\n",
+	}
+	repo, tree := testModSumTree(t, files)
+
+	sums, err := ReadGitModSums(repo, tree, ModVersion{Path: "example.com/example/v2", Version: "v2.0.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []ModSum{
+		{
+			ModVersion: ModVersion{Path: "example.com/example/v2", Version: "v2.0.0"},
+			Hash: modHash1(map[string][]byte{
+				"example.com/example/v2@v2.0.0/go.mod":  []byte(files["v2/go.mod"]),
+				"example.com/example/v2@v2.0.0/main.go": []byte(files["v2/main.go"]),
+			}),
+		},
+		{
+			ModVersion: ModVersion{Path: "example.com/example/v2", Version: "v2.0.0/go.mod"},
+			Hash:       modHash1(map[string][]byte{"go.mod": []byte(files["v2/go.mod"])}),
+		},
+	}, sums)
+
+	// The root module leaves out the major version subdirectory.
+	sums, err = ReadGitModSums(repo, tree, ModVersion{Path: "example.com/example", Version: "v1.0.0"})
+	require.NoError(t, err)
+	assert.Equal(t, modHash1(map[string][]byte{
+		"example.com/example@v1.0.0/go.mod":  []byte(files["go.mod"]),
+		"example.com/example@v1.0.0/main.go": []byte(files["main.go"]),
+	}), sums[0].Hash)
+}
+
+func testModSumTree(t *testing.T, files map[string]string) (*git.Repository, *object.Tree) {
+	fs := memfs.New()
+	for name, content := range files {
+		require.NoError(t, util.WriteFile(fs, name, []byte(content), 0644))
+	}
+	repo, err := git.Init(memory.NewStorage(), fs)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = worktree.Add(".")
+	require.NoError(t, err)
+	hash, err := worktree.Commit("First", &git.CommitOptions{
+		Author: &object.Signature{
+			Name: "Robotto Botdroid",
+		},
+	})
+	require.NoError(t, err)
+	commit, err := repo.CommitObject(hash)
+	require.NoError(t, err)
+	tree, err := repo.TreeObject(commit.TreeHash)
+	require.NoError(t, err)
+
+	return repo, tree
+}
+
+func TestModHash1(t *testing.T) {
+	// The go.mod of go.uber.org/multierr v1.10.0 and its checksum as recorded
+	// by the go command in go.sum.
+	gomod := `module go.uber.org/multierr
+
+go 1.19
+
+require github.com/stretchr/testify v1.7.0
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+`
+	assert.Equal(t, "h1:20+QtiLqy0Nd6FdQB9TLXag12DsQkrbs3htMFfDN80Y=", modHash1(map[string][]byte{
+		"go.mod": []byte(gomod),
+	}))
+}
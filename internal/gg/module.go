@@ -39,17 +39,19 @@ type Module struct {
 	Hash plumbing.Hash
 
 	// Version is the major, minor, and patch version number triple that
-	// denotes this module exactly.
-	// The version indicates an order, but does not capture the semantics or
-	// ordering of semantic versions with suffixes like 1.0.0-rc1.
-	// This tool treats any version number that is more specific than three
-	// bare numbers as versionless and compares them just using timestamps, so
-	// release candidates are ordered by time and inelligible for upgrade using
-	// semver.
+	// denotes this module exactly, together with the pre-release.
 	// The version corresponds to a git tag that resolves to this module's
 	// commit hash.
 	Version Version
 
+	// Prerelease is the pre-release identifiers of the version, like "rc.1"
+	// for the tag v1.0.0-rc.1, or empty for a final release.
+	// Pre-releases precede the final release of the same version, per SemVer
+	// 2.0.
+	// Upgrades consider pre-releases only for modules that opt in with
+	// gg.toml, and the add-missing workflow prefers final releases.
+	Prerelease Prerelease
+
 	// Remote is the URL of the repository that contains this module.
 	// Go uses an HTTP request to the domain name in the first path component
 	// in the package name to look up the remote URL, or failing that,
@@ -89,9 +91,9 @@ type Module struct {
 	// lockfile.
 	Modules Modules
 
-	// NoLock indicates that neither glide.lock nor Gopkg.in were found in the
-	// module.  If Modules are empty and NoLock is true, we can skip looking
-	// for requirements.
+	// NoLock indicates that neither glide.lock, Gopkg.lock, nor go.mod were
+	// found in the module.  If Modules are empty and NoLock is true, we can
+	// skip looking for requirements.
 	NoLock bool
 
 	// Packages is this module's package import graph.
@@ -110,10 +112,19 @@ type Module struct {
 	// absent.
 	Deplock plumbing.Hash
 
+	// Modlock is the git hash of this module's go.mod, or NoHash if absent.
+	Modlock plumbing.Hash
+
 	// Changelog is the git hash of this module's CHANGELOG.md, or NoHash if
 	// absent.
 	Changelog plumbing.Hash
 
+	// Tree is the hash of the git tree that checkout writes to the vendor
+	// directory of this module, as recorded in glide.lock, or NoHash if not
+	// recorded.
+	// This differs from the tree of the commit if the module has submodules.
+	Tree plumbing.Hash
+
 	// GitoliteMirror indicates that the remote is a Gitolite mirror.
 	// The mirror may need to be created before the module can be fetched.
 	GitoliteMirror bool
@@ -129,11 +140,28 @@ type Module struct {
 	// this session.
 	FetchError error
 
+	// FinishError indicates the error produced when this module was
+	// normalized, fetched, and read in this session, if any.
+	FinishError error
+
 	// Finished indicates that this module has already been fetched in this
 	// session.
 	Finished bool
 }
 
+// Failure returns an error if this module could not be fetched or read in
+// this session, or nil.
+// A solution that contains a failed module is incomplete.
+func (module Module) Failure() error {
+	if module.FinishError != nil {
+		return fmt.Errorf("cannot read module %s: %s", module.Summary(), module.FinishError)
+	}
+	if module.FetchError != nil && !module.Fetched {
+		return fmt.Errorf("cannot fetch module %s: %s", module.Summary(), module.FetchError)
+	}
+	return nil
+}
+
 // Summary produces a unique description of the module, suitable for printing
 // inline.
 func (module Module) Summary() string {
@@ -142,7 +170,7 @@ func (module Module) Summary() string {
 		test = "#test"
 	}
 	if module.Version != NoVersion {
-		return module.Name + "@" + module.Version.String() + test
+		return module.Name + "@" + module.VersionString() + test
 	}
 	if module.Ref != "" {
 		return module.Name + "@" + module.Ref + test
@@ -167,7 +195,9 @@ func (module Module) String() string {
 	}
 
 	var version string
-	if module.Version != NoVersion {
+	if module.Prerelease != NoPrerelease {
+		version = fmt.Sprintf("%12s", module.VersionString())
+	} else if module.Version != NoVersion {
 		version = fmt.Sprintf("%4d.%3d.%3d", module.Version[0], module.Version[1], module.Version[2])
 	} else if strings.HasPrefix(module.Ref, "tags/") {
 		version = fmt.Sprintf("%12s", strings.TrimPrefix(module.Ref, "tags/"))
@@ -195,6 +225,8 @@ func (module Module) String() string {
 		lock = "G"
 	} else if module.Deplock != NoHash {
 		lock = "D"
+	} else if module.Modlock != NoHash {
+		lock = "M"
 	}
 
 	refs := strings.Join(module.Refs, " ")
@@ -217,6 +249,20 @@ func (module Module) String() string {
 	return fmt.Sprintf("%s %s %s %s%s%s %s%s%s", hash, date, version, test, lock, cl, name, refs, warnings)
 }
 
+// VersionString returns the version of the module with its pre-release, like
+// "1.0.0-rc.1", or an empty string if the module has no version.
+func (module Module) VersionString() string {
+	if module.Version == NoVersion {
+		return ""
+	}
+	return module.Version.String() + module.Prerelease.String()
+}
+
+// Final returns whether the module has a version that is not a pre-release.
+func (module Module) Final() bool {
+	return module.Version != NoVersion && module.Prerelease == NoPrerelease
+}
+
 // Equal returns whether the given modules have the same hash and test fields.
 func (module Module) Equal(other Module) bool {
 	return module.Name == other.Name && module.Hash == other.Hash && module.Test == other.Test
@@ -245,6 +291,9 @@ func (module Module) Before(other Module) bool {
 	if module.Version != other.Version {
 		return module.Version.Before(other.Version)
 	}
+	if module.Prerelease != other.Prerelease {
+		return module.Prerelease.Before(other.Prerelease)
+	}
 	if !module.Time.Equal(other.Time) {
 		return module.Time.Before(other.Time)
 	}
@@ -254,18 +303,33 @@ func (module Module) Before(other Module) bool {
 // Better returns whether the module is better suited than the other if
 // we needed to add one version of the module and knew nothing else about the
 // code that depends on the module.
-// We will favor a module with a version, the highest version, over all others.
+// We will favor a module with a final release, the highest version, over all
+// others.
 // Otherwise, we will favor the "master" branch over all others.
+// Failing both, we will favor the highest pre-release.
 // This is for the "add missing modules" workflow, and specifically avoiding
 // development branches.
 func (module Module) Better(other Module) bool {
-	if module.Ref == "heads/master" && other.Version == NoVersion {
+	if module.Ref == "heads/master" && !other.Final() {
 		return true
 	}
 	if module.Version == NoVersion {
 		return false
 	}
+	if module.Prerelease != NoPrerelease {
+		if other.Final() || other.Ref == "heads/master" {
+			return false
+		}
+		if other.Prerelease == NoPrerelease {
+			return true
+		}
+	} else if other.Prerelease != NoPrerelease {
+		return true
+	}
 	if module.Version == other.Version {
+		if module.Prerelease != other.Prerelease {
+			return other.Prerelease.Before(module.Prerelease)
+		}
 		return module.Time.After(other.Time)
 	}
 	return other.Version.Before(module.Version)
@@ -275,6 +339,8 @@ func (module Module) Better(other Module) bool {
 // another module.
 // If either package has a semantic version, they are upgradable based on the
 // semver rules.
+// A pre-release can upgrade to a later pre-release or the final release of the
+// same version.
 // If a package has no reference, it can always upgrade to "master", to heal
 // glide.locks written by glide.
 // Otherwise, a module can only upgrade to a newer revision with the same
@@ -290,6 +356,9 @@ func (module Module) CanUpgradeTo(other Module) bool {
 	if module.Version != other.Version {
 		return module.Version.CanUpgradeTo(other.Version)
 	}
+	if module.Prerelease != other.Prerelease {
+		return module.Prerelease.Before(other.Prerelease)
+	}
 	// Otherwise, heal missing references by upgrading to master.
 	if module.Ref == "" {
 		return other.Ref == "heads/master"
@@ -389,11 +458,26 @@ func (modules Modules) FindHash(min, max plumbing.Hash) (Module, bool) {
 	return Module{}, false
 }
 
+// FilterHash returns every module in a range of hashes.
+func (modules Modules) FilterHash(min, max plumbing.Hash) Modules {
+	var filtered Modules
+	for _, module := range modules {
+		if HashBetween(min, module.Hash, max) {
+			filtered = append(filtered, module)
+		}
+	}
+	return filtered
+}
+
 // FindVersion returns the module with the highest version that satisfies the
 // given version's implied semantic version range, and whether one such was
 // found.
+// FindVersion does not consider pre-releases.
 func (modules Modules) FindVersion(version Version) (found Module, ok bool) {
 	for _, module := range modules {
+		if module.Prerelease != NoPrerelease {
+			continue
+		}
 		if version == module.Version || version.CanUpgradeTo(module.Version) {
 			version = module.Version
 			found = module
@@ -424,11 +508,8 @@ func (modules Modules) FindBestSemver(constraint *semver.Constraints) (Module, b
 	var found bool
 	for _, module := range modules {
 		if module.Version != NoVersion {
-			version, err := semver.NewVersion(fmt.Sprintf("%d.%d.%d",
-				module.Version[0],
-				module.Version[1],
-				module.Version[2],
-			))
+			// Glide constraints exclude pre-releases unless they name one.
+			version, err := semver.NewVersion(module.VersionString())
 			if err == nil && constraint.Check(version) && module.Better(best) {
 				best = module
 				found = true
@@ -439,11 +520,11 @@ func (modules Modules) FindBestSemver(constraint *semver.Constraints) (Module, b
 }
 
 // FilterNumberedVersions returns only modules that have an associated version
-// number.
+// number, excluding pre-releases.
 func (modules Modules) FilterNumberedVersions() Modules {
 	var filtered Modules
 	for _, module := range modules {
-		if module.Version != NoVersion {
+		if module.Final() {
 			filtered = append(filtered, module)
 		}
 	}
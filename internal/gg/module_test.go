@@ -30,7 +30,7 @@ func (module Module) LoaderHash() plumbing.Hash {
 		plumbing.CommitObject,
 		[]byte(
 			module.Name+"@"+
-				module.Version.String()+"@"+
+				module.VersionString()+"@"+
 				strconv.Itoa(int(module.Time.Unix())),
 		),
 	)
@@ -404,6 +404,62 @@ func TestModuleCanUpgradeTo(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			msg: "upgrade to pre-release of next minor",
+			this: Module{
+				Version: Version{1, 2, 3},
+			},
+			that: Module{
+				Version:    Version{1, 3, 0},
+				Prerelease: "rc.1",
+			},
+			want: true,
+		},
+		{
+			msg: "upgrade pre-release to later pre-release",
+			this: Module{
+				Version:    Version{1, 3, 0},
+				Prerelease: "rc.1",
+			},
+			that: Module{
+				Version:    Version{1, 3, 0},
+				Prerelease: "rc.2",
+			},
+			want: true,
+		},
+		{
+			msg: "upgrade pre-release to final release",
+			this: Module{
+				Version:    Version{1, 3, 0},
+				Prerelease: "rc.2",
+			},
+			that: Module{
+				Version: Version{1, 3, 0},
+			},
+			want: true,
+		},
+		{
+			msg: "downgrade final release to pre-release",
+			this: Module{
+				Version: Version{1, 3, 0},
+			},
+			that: Module{
+				Version:    Version{1, 3, 0},
+				Prerelease: "rc.2",
+			},
+			want: false,
+		},
+		{
+			msg: "upgrade pre-release of next major",
+			this: Module{
+				Version: Version{1, 3, 0},
+			},
+			that: Module{
+				Version:    Version{2, 0, 0},
+				Prerelease: "beta",
+			},
+			want: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -451,6 +507,20 @@ func TestFindHash(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestFilterHash(t *testing.T) {
+	modules := alphaRevisions
+
+	filtered := modules.FilterHash(twoHash, twoHash)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, twoHash, filtered[0].Hash)
+
+	filtered = modules.FilterHash(NoHash, MaxHash)
+	assert.Equal(t, modules, filtered)
+
+	filtered = modules.FilterHash(careyHash, careyHash)
+	assert.Len(t, filtered, 0)
+}
+
 func TestFindVersion(t *testing.T) {
 	modules := alphaRevisions
 
@@ -494,6 +564,25 @@ func TestFindBestVersion(t *testing.T) {
 	require.False(t, ok)
 }
 
+func TestFindBestVersionPrefersFinalReleases(t *testing.T) {
+	master := Module{Name: "beta", Ref: "heads/master", Time: time.Unix(0, 1)}
+	final := Module{Name: "beta", Version: Version{1, 0, 0}}
+	rc1 := Module{Name: "beta", Version: Version{1, 1, 0}, Prerelease: "rc.1"}
+	rc2 := Module{Name: "beta", Version: Version{1, 1, 0}, Prerelease: "rc.2"}
+
+	module, ok := Modules{final, rc1, rc2, master}.FindBestVersion()
+	require.True(t, ok)
+	assert.Equal(t, final, module)
+
+	module, ok = Modules{rc1, rc2, master}.FindBestVersion()
+	require.True(t, ok)
+	assert.Equal(t, master, module)
+
+	module, ok = Modules{rc2, rc1}.FindBestVersion()
+	require.True(t, ok)
+	assert.Equal(t, rc2, module)
+}
+
 func TestFilterNumberedVersions(t *testing.T) {
 	versioned := alphaRevisions.FilterNumberedVersions()
 	assert.Len(t, versioned, 4)
@@ -0,0 +1,137 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// file outdated.go finds the newer versions of the modules in a solution,
+// both those that the upgrade workflow would choose and those beyond the
+// semantic version range of each module.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// OutdatedByName orders the outdated report by module name.
+	OutdatedByName = "name"
+	// OutdatedByAge orders the outdated report from the oldest current
+	// version to the newest.
+	OutdatedByAge = "age"
+)
+
+// OutdatedLoader reads the versions of modules for the outdated report.
+type OutdatedLoader interface {
+	ReadVersions(context.Context, ProgressWriter, Module) (Modules, error)
+	PrefetchVersions(context.Context, ProgressWriter, Modules)
+}
+
+// Outdated is a module in the solution, the newest version that the upgrade
+// workflow would choose, and the newest version overall, including versions
+// with breaking changes.
+// The Error captures why the versions of the module could not be read, in
+// which case the compatible and latest versions are the current version.
+type Outdated struct {
+	Current    Module
+	Compatible Module
+	Latest     Module
+	Error      error
+}
+
+// Age returns the number of whole days since the commit of the current
+// version, or -1 if the time of the commit is unknown.
+func (outdated Outdated) Age(now time.Time) int {
+	if outdated.Current.Time == (time.Time{}) {
+		return -1
+	}
+	return int(now.Sub(outdated.Current.Time) / (24 * time.Hour))
+}
+
+// CanUpgrade returns whether there is a newer version within the semantic
+// version range of the current version.
+func (outdated Outdated) CanUpgrade() bool {
+	return !outdated.Compatible.Equal(outdated.Current)
+}
+
+// HasNewer returns whether there is a newer version than the compatible
+// version, either beyond the semantic version range of the current version or
+// within it but beyond what the policy for the module allows.
+func (outdated Outdated) HasNewer() bool {
+	return outdated.Compatible.Before(outdated.Latest)
+}
+
+// CanUpgradeMajor returns whether there is a newer version beyond the
+// semantic version range of the current version.
+func (outdated Outdated) CanUpgradeMajor() bool {
+	return outdated.Compatible.Before(outdated.Latest) && !outdated.Compatible.CanUpgradeTo(outdated.Latest)
+}
+
+// FindOutdated reads the versions of every module and finds the newest version
+// within and beyond its semantic version range, in the given order.
+// Like the upgrade workflow, it abides by the given policies and exclusions,
+// and considers pre-releases only for the modules that the given prereleases
+// allow.
+// FindOutdated does not stop for modules it cannot read, but captures the
+// error in the corresponding entry.
+func FindOutdated(ctx context.Context, loader OutdatedLoader, out ProgressWriter, modules Modules, policies Policies, prereleases Prereleases, exclusions Exclusions) ([]Outdated, error) {
+	loader.PrefetchVersions(ctx, out, modules)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	outdated := make([]Outdated, 0, len(modules))
+	for _, module := range modules {
+		entry := Outdated{
+			Current:    module,
+			Compatible: module,
+			Latest:     module,
+		}
+		versions, err := loader.ReadVersions(ctx, out, module)
+		if err != nil {
+			entry.Error = fmt.Errorf("cannot read versions of module %s: %s", module.Summary(), err)
+		} else {
+			versions = exclusions.Filter(versions)
+			entry.Compatible = findUpgradeModule(versions, module, policies.Find(module.Name), prereleases.Allow(module.Name))
+			if latest, ok := versions.FindBestVersion(); ok && entry.Compatible.Before(latest) {
+				entry.Latest = latest
+			} else {
+				entry.Latest = entry.Compatible
+			}
+		}
+		entry.Compatible.Test = module.Test
+		entry.Latest.Test = module.Test
+		outdated = append(outdated, entry)
+	}
+	return outdated, nil
+}
+
+// SortOutdated orders entries of the outdated report by the given column,
+// either OutdatedByName or OutdatedByAge.
+func SortOutdated(outdated []Outdated, by string, now time.Time) error {
+	switch by {
+	case OutdatedByName:
+		sort.SliceStable(outdated, func(i, j int) bool {
+			return outdated[i].Current.Name < outdated[j].Current.Name
+		})
+	case OutdatedByAge:
+		sort.SliceStable(outdated, func(i, j int) bool {
+			return outdated[i].Age(now) > outdated[j].Age(now)
+		})
+	default:
+		return fmt.Errorf("cannot order the outdated report by %q, expected %q or %q", by, OutdatedByName, OutdatedByAge)
+	}
+	return nil
+}
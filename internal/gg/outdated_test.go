@@ -0,0 +1,89 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindOutdated(t *testing.T) {
+	january := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	loader := NewFakeLoader(Modules{
+		{Name: "carey", Version: Version{1, 0, 0}, Time: january},
+		{Name: "carey", Version: Version{1, 1, 0}, Time: january.AddDate(0, 1, 0)},
+		{Name: "carey", Version: Version{2, 0, 0}, Time: january.AddDate(0, 2, 0)},
+		{Name: "drew", Version: Version{1, 0, 0}, Time: january.AddDate(0, 0, 10)},
+		{Name: "erin", Version: Version{1, 0, 0}, Time: january.AddDate(0, 0, 20)},
+		{Name: "erin", Version: Version{1, 0, 1}, Time: january.AddDate(0, 0, 30)},
+		{Name: "erin", Version: Version{1, 1, 0}, Time: january.AddDate(0, 0, 40)},
+	})
+	modules := Modules{
+		loader.MustGetTime("drew", january.AddDate(0, 0, 10)),
+		loader.MustGetTime("carey", january),
+		loader.MustGetTime("erin", january.AddDate(0, 0, 20)),
+	}
+	policies := Policies{
+		{Pattern: "erin", Match: PatternSplit("erin"), Mode: PolicyPatch},
+	}
+	now := january.AddDate(0, 0, 100)
+
+	outdated, err := FindOutdated(context.Background(), loader, &LogSolverProgress{}, modules, policies, Prereleases{}, nil)
+	require.NoError(t, err)
+	require.Len(t, outdated, 3)
+
+	drew := outdated[0]
+	assert.Equal(t, "drew", drew.Current.Name)
+	assert.False(t, drew.CanUpgrade())
+	assert.False(t, drew.CanUpgradeMajor())
+	assert.False(t, drew.HasNewer())
+	assert.Equal(t, 90, drew.Age(now))
+
+	carey := outdated[1]
+	assert.Equal(t, Version{1, 1, 0}, carey.Compatible.Version)
+	assert.Equal(t, Version{2, 0, 0}, carey.Latest.Version)
+	assert.True(t, carey.CanUpgrade())
+	assert.True(t, carey.CanUpgradeMajor())
+	assert.True(t, carey.HasNewer())
+	assert.Equal(t, 100, carey.Age(now))
+
+	// The patch policy holds erin back from the newest minor version, which
+	// is nonetheless the latest.
+	erin := outdated[2]
+	assert.Equal(t, Version{1, 0, 1}, erin.Compatible.Version)
+	assert.Equal(t, Version{1, 1, 0}, erin.Latest.Version)
+	assert.True(t, erin.CanUpgrade())
+	assert.False(t, erin.CanUpgradeMajor())
+	assert.True(t, erin.HasNewer())
+
+	require.NoError(t, SortOutdated(outdated, OutdatedByName, now))
+	assert.Equal(t, "carey", outdated[0].Current.Name)
+	require.NoError(t, SortOutdated(outdated, OutdatedByAge, now))
+	assert.Equal(t, "carey", outdated[0].Current.Name)
+	assert.Error(t, SortOutdated(outdated, "size", now))
+
+	var buf bytes.Buffer
+	require.NoError(t, ShowOutdated(&buf, outdated, now))
+	assert.Equal(t, `Module  Current  Compatible  Latest  Age
+carey   1.0.0    1.1.0       2.0.0   100
+drew    1.0.0    -           -       90
+erin    1.0.0    1.0.1       1.1.0   80
+`, buf.String())
+}
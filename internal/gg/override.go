@@ -0,0 +1,199 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// The solver only ever moves modules forward, so it cannot honor a constraint
+// that pins a module to an older version than some other module in the
+// solution demands.
+// The override mode pins the module, then walks every module that demands a
+// newer version of a pinned module back to its previous version, pinning that
+// module in turn, until no module in the solution demands a newer version of
+// any pinned module.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OverrideLoader loads older versions of modules for the duration of an
+// override.
+type OverrideLoader interface {
+	SolverLoader
+
+	ReadVersions(context.Context, ProgressWriter, Module) (Modules, error)
+}
+
+// OverrideError reports the modules that prevent an override, because they
+// demand a newer version of a pinned module and have no older version to walk
+// back to.
+type OverrideError struct {
+	Override Module
+	Blockers []OverrideBlocker
+}
+
+// OverrideBlocker is a module that blocks an override, and the newer version
+// of a pinned module that it demands.
+type OverrideBlocker struct {
+	Module      Module
+	Requirement Module
+}
+
+func (err *OverrideError) Error() string {
+	blockers := make([]string, 0, len(err.Blockers))
+	for _, blocker := range err.Blockers {
+		blockers = append(blockers, fmt.Sprintf("%s requires %s", blocker.Module.Summary(), blocker.Requirement.Summary()))
+	}
+	return fmt.Sprintf("cannot override %s because %s", err.Override.Summary(), strings.Join(blockers, ", "))
+}
+
+// Override returns a solved state that has the given module, even if it is
+// older than the version that the solution would otherwise settle upon.
+// Override walks modules that demand a newer version of the overridden module
+// back to older versions, until none do.
+// If a module that demands a newer version of a pinned module has no older
+// version, Override returns the solved state with an *OverrideError that
+// captures the blocking modules, among the errors for any module it could not
+// fetch or read.
+func (state *State) Override(ctx context.Context, loader OverrideLoader, out SolverProgress, override Module) (*State, error) {
+	if err := loader.FinishModule(ctx, out, &override); err != nil {
+		return state, err
+	}
+	state, errs := state.Solve(ctx, loader, out)
+	if err := ctx.Err(); err != nil {
+		return state, errs
+	}
+
+	// Every walk back starts over from the modules that the solution is rooted
+	// upon, so that the requirements of newer versions of the modules we walk
+	// back neither linger in the solution nor block the override.
+	solved := state.Modules().Index()
+	if prior, ok := solved[override.Name]; ok {
+		override.Test = prior.Test
+	}
+	roots := ShallowModules(state)
+	pinned := map[string]Module{override.Name: override}
+	rebuild := func() map[string]Module {
+		current := map[string]Module{override.Name: override}
+		for name := range roots {
+			if pin, ok := pinned[name]; ok {
+				current[name] = pin
+			} else {
+				current[name] = solved[name]
+			}
+		}
+		return current
+	}
+	current := rebuild()
+	blocked := make(StringSet)
+	var blockers []OverrideBlocker
+
+	for changed := true; changed; {
+		changed = false
+		names := make([]string, 0, len(current))
+		for name := range current {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+	Modules:
+		for _, name := range names {
+			module := current[name]
+			if blocked.Has(name) {
+				continue
+			}
+			if err := loader.FinishModules(ctx, out, module.Modules); err != nil {
+				return state, AppendUniqueError(errs, err)
+			}
+			for _, requirement := range module.Modules {
+				present, ok := current[requirement.Name]
+				if ok && !present.Before(requirement) {
+					continue
+				}
+
+				pin, ok := pinned[requirement.Name]
+				if !ok || !pin.Before(requirement) {
+					// The requirement is newer than the current version but
+					// does not exceed a pin, so move it forward as the solver
+					// would.
+					requirement.Test = module.Test && (present.Name == "" || present.Test)
+					current[requirement.Name] = requirement
+					changed = true
+					continue
+				}
+
+				// The module demands a newer version of a pinned module, so
+				// walk it back to its previous version and pin it there.
+				older, ok, err := findOverrideModule(ctx, loader, out, module)
+				if err != nil {
+					return state, AppendUniqueError(errs, err)
+				}
+				if !ok || name == override.Name {
+					blocked.Add(name)
+					blockers = append(blockers, OverrideBlocker{
+						Module:      module,
+						Requirement: requirement,
+					})
+					continue Modules
+				}
+				out.Backtrack(state, module, older)
+				pinned[name] = older
+				current = rebuild()
+				blocked = make(StringSet)
+				blockers = nil
+				changed = true
+				break Modules
+			}
+		}
+	}
+
+	if len(blockers) > 0 {
+		return state, AppendUniqueError(errs, &OverrideError{
+			Override: override,
+			Blockers: blockers,
+		})
+	}
+
+	modules := make(Modules, 0, len(current))
+	for _, module := range current {
+		modules = append(modules, module)
+	}
+	sort.Sort(modules)
+	next, err := NewState().Constrain(ctx, loader, out, modules, false)
+	if err != nil {
+		return state, AppendUniqueError(errs, err)
+	}
+	next, err = next.Solve(ctx, loader, out)
+	return next, AppendUniqueError(errs, err)
+}
+
+// findOverrideModule returns the newest version of a module that is older
+// than the given version, and whether there is one.
+func findOverrideModule(ctx context.Context, loader OverrideLoader, out ProgressWriter, module Module) (Module, bool, error) {
+	versions, err := loader.ReadVersions(ctx, out, module)
+	if err != nil {
+		return module, false, err
+	}
+	sort.Sort(versions)
+	for i := len(versions) - 1; i >= 0; i-- {
+		older := versions[i]
+		if older.Before(module) {
+			older.Test = module.Test
+			return older, true, nil
+		}
+	}
+	return module, false, nil
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverride(t *testing.T) {
+	loader := NewFakeLoader(Modules{
+		{
+			Name:    "avery",
+			Version: Version{1, 0, 0},
+			Modules: Modules{
+				{Name: "carey", Version: Version{1, 0, 0}},
+			},
+		},
+		{
+			Name:    "avery",
+			Version: Version{1, 1, 0},
+			Modules: Modules{
+				{Name: "blake", Version: Version{1, 1, 0}},
+				{Name: "carey", Version: Version{1, 1, 0}},
+			},
+		},
+		{
+			Name:    "blake",
+			Version: Version{1, 0, 0},
+		},
+		{
+			Name:    "blake",
+			Version: Version{1, 1, 0},
+			Modules: Modules{
+				{Name: "carey", Version: Version{1, 1, 0}},
+			},
+		},
+		{
+			Name:    "carey",
+			Version: Version{1, 0, 0},
+		},
+		{
+			Name:    "carey",
+			Version: Version{1, 1, 0},
+		},
+		{
+			Name:    "drew",
+			Version: Version{1, 0, 0},
+			Modules: Modules{
+				{Name: "carey", Version: Version{1, 1, 0}},
+			},
+		},
+		{
+			Name:    "emery",
+			Version: Version{1, 0, 0},
+			Modules: Modules{
+				{Name: "carey", Version: Version{1, 0, 0}},
+			},
+		},
+		{
+			Name:    "emery",
+			Version: Version{1, 1, 0},
+			Modules: Modules{
+				{Name: "carey", Version: Version{1, 1, 0}},
+				{Name: "erin", Version: Version{1, 0, 0}},
+			},
+		},
+		{
+			Name:    "erin",
+			Version: Version{1, 0, 0},
+			Modules: Modules{
+				{Name: "carey", Version: Version{1, 1, 0}},
+			},
+		},
+		{
+			Name:    "frank",
+			Version: Version{1, 0, 0},
+		},
+		{
+			Name:    "gale",
+			Version: Version{1, 0, 0},
+			Modules: Modules{
+				{Name: "carey", Version: Version{1, 0, 0}},
+			},
+		},
+		{
+			Name:    "gale",
+			Version: Version{1, 1, 0},
+			Modules: Modules{
+				{Name: "carey", Version: Version{1, 1, 0}},
+				{Name: "frank", Version: Version{1, 0, 0}},
+			},
+		},
+	})
+
+	tests := []struct {
+		name     string
+		give     Modules
+		override Module
+		want     Modules
+		blockers Modules
+	}{
+		{
+			name: "walks back dependees transitively",
+			give: Modules{
+				{Name: "avery", Version: Version{1, 1, 0}},
+				{Name: "blake", Version: Version{1, 0, 0}},
+			},
+			override: Module{Name: "carey", Version: Version{1, 0, 0}},
+			want: Modules{
+				loader.MustGetVersion("avery", Version{1, 0, 0}),
+				loader.MustGetVersion("blake", Version{1, 0, 0}),
+				loader.MustGetVersion("carey", Version{1, 0, 0}),
+			},
+		},
+		{
+			name: "already satisfied",
+			give: Modules{
+				{Name: "avery", Version: Version{1, 0, 0}},
+			},
+			override: Module{Name: "carey", Version: Version{1, 0, 0}},
+			want: Modules{
+				loader.MustGetVersion("avery", Version{1, 0, 0}),
+				loader.MustGetVersion("carey", Version{1, 0, 0}),
+			},
+		},
+		{
+			name: "not blocked by a requirement of the newer version it walks back",
+			give: Modules{
+				{Name: "emery", Version: Version{1, 1, 0}},
+			},
+			override: Module{Name: "carey", Version: Version{1, 0, 0}},
+			want: Modules{
+				loader.MustGetVersion("carey", Version{1, 0, 0}),
+				loader.MustGetVersion("emery", Version{1, 0, 0}),
+			},
+		},
+		{
+			name: "drops the requirements of the newer version it walks back",
+			give: Modules{
+				{Name: "gale", Version: Version{1, 1, 0}},
+			},
+			override: Module{Name: "carey", Version: Version{1, 0, 0}},
+			want: Modules{
+				loader.MustGetVersion("carey", Version{1, 0, 0}),
+				loader.MustGetVersion("gale", Version{1, 0, 0}),
+			},
+		},
+		{
+			name: "blocked by dependee without older version",
+			give: Modules{
+				{Name: "avery", Version: Version{1, 0, 0}},
+				{Name: "drew", Version: Version{1, 0, 0}},
+			},
+			override: Module{Name: "carey", Version: Version{1, 0, 0}},
+			blockers: Modules{
+				loader.MustGetVersion("drew", Version{1, 0, 0}),
+			},
+		},
+	}
+
+	progress := &LogSolverProgress{}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			state, err := NewState().Constrain(ctx, loader, progress, tt.give, false)
+			require.NoError(t, err)
+
+			state, err = state.Override(ctx, loader, progress, tt.override)
+			if tt.blockers != nil {
+				require.Error(t, err)
+				overrideErr, ok := err.(*OverrideError)
+				require.True(t, ok)
+				var blockers Modules
+				for _, blocker := range overrideErr.Blockers {
+					blockers = append(blockers, blocker.Module)
+				}
+				assert.True(t, tt.blockers.Equal(blockers), "blockers %v", blockers)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(state.Modules()), "solution %v", state.Modules())
+		})
+	}
+}
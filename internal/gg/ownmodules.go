@@ -14,6 +14,12 @@
 
 package gg
 
+import (
+	"os"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
 // ReadOwnModules reads a glide.lock and converts them into GG's internal
 // representation of Modules.
 func ReadOwnModules() (Modules, error) {
@@ -25,7 +31,26 @@ func ReadOwnModules() (Modules, error) {
 }
 
 // WriteOwnModules takes GG's internal representation of Modules and
-// converts it into a glide.lock written into the working directory.
-func WriteOwnModules(modules Modules) error {
-	return WriteOwnGlideLock(GlideLockFromModules(modules))
+// converts it into a glide.lock written into the working directory, with the
+// given digest of the vendor checkout, if any.
+func WriteOwnModules(modules Modules, vendor plumbing.Hash) error {
+	lock := GlideLockFromModules(modules)
+	lock.Vendor = HashString(vendor)
+	return WriteOwnGlideLock(lock)
+}
+
+// RecordOwnVendorDigest records the digests of a vendor checkout in the
+// glide.lock in the working directory, if there is one, rewriting it only if
+// the digests changed.
+func RecordOwnVendorDigest(modules Modules, vendor plumbing.Hash) error {
+	lock, err := ReadOwnGlideLock()
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !lock.RecordDigest(modules, vendor) {
+		return nil
+	}
+	return WriteOwnGlideLock(lock)
 }
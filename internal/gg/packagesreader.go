@@ -113,6 +113,12 @@ func readPackages(entry TreeEntry, module *Module, excludes StringSet) error {
 			break
 		}
 		if err != nil {
+			// A submodule that we could not fetch does not prevent us from
+			// reading the rest of the module.
+			if entry, ok := entry.(GitEntry); ok && entry.IsSubmodule() {
+				module.Warnings = append(module.Warnings, fmt.Sprintf("Cannot read packages in submodule %s: %s", path, err))
+				continue
+			}
 			return err
 		}
 		if path == module.Name+"/CHANGELOG.md" {
@@ -121,6 +127,8 @@ func readPackages(entry TreeEntry, module *Module, excludes StringSet) error {
 			module.Glidelock = entry.Hash()
 		} else if path == module.Name+"/Gopkg.lock" {
 			module.Deplock = entry.Hash()
+		} else if path == module.Name+"/go.mod" {
+			module.Modlock = entry.Hash()
 		} else if entry.IsDir() && excludes.Has(entry.Name()) {
 			walker.Skip()
 			continue
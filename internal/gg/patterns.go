@@ -139,3 +139,20 @@ func (pattern Pattern) replace(parts []string) ([]string, []string) {
 	}
 	return matched, replaced
 }
+
+// Matches returns whether a whole string matches the pattern, where "*" stands
+// for any one component and "..." for any remaining components.
+// Unlike Replace, a pattern does not match strings that merely begin with
+// matching components.
+func (pattern Pattern) Matches(str string) bool {
+	parts := PatternSplit(str)
+	for i, part := range pattern.Match {
+		if part == "..." {
+			return true
+		}
+		if i >= len(parts) || (part != "*" && part != parts[i]) {
+			return false
+		}
+	}
+	return len(parts) == len(pattern.Match)
+}
@@ -119,3 +119,24 @@ func TestPatternMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestPatternMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		give    string
+		want    bool
+	}{
+		{"github.com/*/*", "github.com/x/y", true},
+		{"github.com/*/*", "github.com/x", false},
+		{"github.com/*/*", "github.com/x/y/z", false},
+		{"go.uber.org/zap", "go.uber.org/zap", true},
+		{"go.uber.org/zap", "go.uber.org/zapx", false},
+		{"example.com/...", "example.com/x/y/z", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.give, func(t *testing.T) {
+			assert.Equal(t, tt.want, Pattern{Match: PatternSplit(tt.pattern)}.Matches(tt.give))
+		})
+	}
+}
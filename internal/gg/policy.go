@@ -0,0 +1,162 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// file policy.go governs how far the upgrade and add-missing workflows may
+// move each module, by name pattern, as configured with [[policy]] in gg.toml.
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// PolicyHold keeps a module at its current version.
+	PolicyHold = "hold"
+	// PolicyPatch allows upgrades to newer patch versions only.
+	PolicyPatch = "patch"
+	// PolicyMinor allows upgrades to newer minor and patch versions with the
+	// same major version, even for major version zero.
+	PolicyMinor = "minor"
+	// PolicyMajor allows upgrades to any newer version, including breaking
+	// changes.
+	PolicyMajor = "major"
+	// PolicyTrackBranch follows the newest commit of a named branch, as in
+	// "track-branch develop".
+	PolicyTrackBranch = "track-branch"
+)
+
+// Policy governs the versions of the modules with names that match a pattern.
+// The zero policy applies the default rules of the upgrade workflow.
+type Policy struct {
+	// Pattern is the module name pattern, as written in gg.toml.
+	Pattern string
+	// Match is the pattern split into components.
+	Match []string
+	// Mode is one of hold, patch, minor, major, or track-branch.
+	Mode string
+	// Branch is the branch to follow in the track-branch mode.
+	Branch string
+	// Source is the gg.toml this policy came from.
+	Source string
+}
+
+// Policies is an ordered list of policies, the first matching policy taking
+// effect.
+type Policies []Policy
+
+// ParsePolicy parses a module name pattern and a policy mode, like "hold" or
+// "track-branch develop".
+func ParsePolicy(pattern, mode string) (Policy, error) {
+	policy := Policy{
+		Pattern: pattern,
+		Match:   PatternSplit(pattern),
+	}
+	if pattern == "" {
+		return policy, fmt.Errorf("expected a module name pattern for policy %q", mode)
+	}
+	fields := strings.Fields(mode)
+	if len(fields) == 0 {
+		return policy, fmt.Errorf("expected a mode for policy %s", pattern)
+	}
+	policy.Mode = fields[0]
+	switch policy.Mode {
+	case PolicyHold, PolicyPatch, PolicyMinor, PolicyMajor:
+		if len(fields) != 1 {
+			return policy, fmt.Errorf("unexpected arguments to %s policy for %s: %q", policy.Mode, pattern, mode)
+		}
+	case PolicyTrackBranch:
+		if len(fields) != 2 {
+			return policy, fmt.Errorf("expected a branch name for track-branch policy for %s: %q", pattern, mode)
+		}
+		policy.Branch = fields[1]
+	default:
+		return policy, fmt.Errorf("unrecognized policy mode %q for %s, expected %s, %s, %s, %s, or %s <branch>", policy.Mode, pattern, PolicyHold, PolicyPatch, PolicyMinor, PolicyMajor, PolicyTrackBranch)
+	}
+	return policy, nil
+}
+
+// Find returns the first policy that matches a module name, or the zero
+// policy if none match.
+func (policies Policies) Find(name string) Policy {
+	for _, policy := range policies {
+		if (Pattern{Match: policy.Match}).Matches(name) {
+			return policy
+		}
+	}
+	return Policy{}
+}
+
+// String returns the mode of the policy as written in gg.toml, like
+// "track-branch develop".
+func (policy Policy) String() string {
+	if policy.Mode == PolicyTrackBranch {
+		return policy.Mode + " " + policy.Branch
+	}
+	return policy.Mode
+}
+
+// Reason explains which policy applies to a module, for reports of skipped
+// modules.
+func (policy Policy) Reason() string {
+	return fmt.Sprintf("%s policy for %s in %s", policy, policy.Pattern, policy.Source)
+}
+
+// Allows returns whether the policy permits upgrading a module to another
+// version of it.
+// Modules without versions follow the default rules, so the patch, minor, and
+// major policies still upgrade branches by timestamp.
+// The track-branch policy does not compare versions, but follows the branch.
+func (policy Policy) Allows(module, other Module) bool {
+	switch policy.Mode {
+	case PolicyHold, PolicyTrackBranch:
+		return false
+	case PolicyPatch, PolicyMinor, PolicyMajor:
+		if module.Version == NoVersion || other.Version == NoVersion {
+			return module.CanUpgradeTo(other)
+		}
+		newer := module.Version.Before(other.Version) ||
+			module.Version == other.Version && module.Prerelease.Before(other.Prerelease)
+		if module.Time.After(other.Time) || !newer {
+			return false
+		}
+		switch policy.Mode {
+		case PolicyPatch:
+			return module.Version[0] == other.Version[0] && module.Version[1] == other.Version[1]
+		case PolicyMinor:
+			return module.Version[0] == other.Version[0]
+		}
+		return true
+	default:
+		return module.CanUpgradeTo(other)
+	}
+}
+
+// FindBranch returns the module at the head of a branch, and whether there is
+// one, by the best reference or any other reference to each module.
+func (modules Modules) FindBranch(branch string) (Module, bool) {
+	ref := "heads/" + branch
+	for _, module := range modules {
+		if module.Ref == ref {
+			return module, true
+		}
+		for _, other := range module.Refs {
+			if other == ref {
+				return module, true
+			}
+		}
+	}
+	return Module{}, false
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		pattern string
+		mode    string
+		want    Policy
+		err     string
+	}{
+		{
+			pattern: "go.uber.org/*",
+			mode:    "hold",
+			want:    Policy{Pattern: "go.uber.org/*", Match: []string{"go.uber.org", "/", "*"}, Mode: PolicyHold},
+		},
+		{
+			pattern: "example.com/x",
+			mode:    "track-branch  develop",
+			want:    Policy{Pattern: "example.com/x", Match: []string{"example.com", "/", "x"}, Mode: PolicyTrackBranch, Branch: "develop"},
+		},
+		{
+			pattern: "example.com/x",
+			mode:    "track-branch",
+			err:     `expected a branch name for track-branch policy for example.com/x: "track-branch"`,
+		},
+		{
+			pattern: "example.com/x",
+			mode:    "patch 1",
+			err:     `unexpected arguments to patch policy for example.com/x: "patch 1"`,
+		},
+		{
+			pattern: "example.com/x",
+			mode:    "latest",
+			err:     `unrecognized policy mode "latest" for example.com/x, expected hold, patch, minor, major, or track-branch <branch>`,
+		},
+		{
+			pattern: "example.com/x",
+			err:     `expected a mode for policy example.com/x`,
+		},
+		{
+			mode: "hold",
+			err:  `expected a module name pattern for policy "hold"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.mode, func(t *testing.T) {
+			policy, err := ParsePolicy(tt.pattern, tt.mode)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, policy)
+		})
+	}
+}
+
+func TestFindPolicy(t *testing.T) {
+	policies := Policies{
+		{Pattern: "go.uber.org/thriftrw", Match: PatternSplit("go.uber.org/thriftrw"), Mode: PolicyHold},
+		{Pattern: "go.uber.org/*", Match: PatternSplit("go.uber.org/*"), Mode: PolicyPatch},
+	}
+	assert.Equal(t, PolicyHold, policies.Find("go.uber.org/thriftrw").Mode)
+	assert.Equal(t, PolicyPatch, policies.Find("go.uber.org/zap").Mode)
+	assert.Equal(t, Policy{}, policies.Find("go.uber.org/zap/zapcore"))
+	assert.Equal(t, Policy{}, policies.Find("github.com/x/y"))
+}
+
+func TestPolicyAllows(t *testing.T) {
+	v := func(major, minor, patch int) Module {
+		return Module{Version: Version{major, minor, patch}}
+	}
+	tests := []struct {
+		mode       string
+		this, that Module
+		want       bool
+	}{
+		{"", v(1, 0, 0), v(1, 1, 0), true},
+		{"", v(1, 0, 0), v(2, 0, 0), false},
+		{PolicyHold, v(1, 0, 0), v(1, 0, 1), false},
+		{PolicyPatch, v(1, 0, 0), v(1, 0, 1), true},
+		{PolicyPatch, v(1, 0, 0), v(1, 1, 0), false},
+		{PolicyMinor, v(0, 1, 0), v(0, 2, 0), true},
+		{PolicyMinor, v(1, 0, 0), v(2, 0, 0), false},
+		{PolicyMajor, v(1, 0, 0), v(2, 0, 0), true},
+		{PolicyMajor, v(2, 0, 0), v(1, 0, 0), false},
+		{PolicyMajor, v(1, 0, 0), v(1, 0, 0), false},
+		{
+			PolicyPatch,
+			Module{Ref: "heads/master", Time: time.Unix(1, 0)},
+			Module{Ref: "heads/master", Time: time.Unix(2, 0)},
+			true,
+		},
+		{
+			PolicyTrackBranch,
+			Module{Ref: "heads/master", Time: time.Unix(1, 0)},
+			Module{Ref: "heads/master", Time: time.Unix(2, 0)},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode+" "+tt.this.Summary()+" "+tt.that.Summary(), func(t *testing.T) {
+			assert.Equal(t, tt.want, Policy{Mode: tt.mode}.Allows(tt.this, tt.that))
+		})
+	}
+}
+
+func TestFindBranch(t *testing.T) {
+	modules := Modules{
+		{Name: "a", Ref: "heads/master"},
+		{Name: "a", Ref: "tags/v1.0.0", Refs: []string{"heads/release", "tags/v1.0.0"}},
+	}
+	module, ok := modules.FindBranch("release")
+	require.True(t, ok)
+	assert.Equal(t, "tags/v1.0.0", module.Ref)
+
+	_, ok = modules.FindBranch("develop")
+	assert.False(t, ok)
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// file pool.go provides a bounded pool of workers for the memo, so that the
+// memo can fetch and read many modules at once.
+//
+// The go-git repository is not safe for concurrent use, so the workers take
+// turns holding the memo's lock, which guards the repository and every memo
+// map alike.
+// A worker lets go of the lock only while it waits on the network, as for a
+// git fetch or an HTTP request for the remote of a package, so other workers
+// can proceed in the meanwhile.
+// So only the waits run in parallel.
+// Reading git objects, as when DigestRefs reads references or
+// digestGitPackages reads the imports of a module, still happens one worker
+// at a time, and a pool gains nothing over a loop for work that is already
+// fetched.
+// Outside of a pool, the memo is only ever used by one goroutine at a time,
+// which does not take the lock.
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultConcurrency is the number of modules that the memo reads at once,
+// unless gg.toml says otherwise.
+const DefaultConcurrency = 8
+
+// memoLockKey is the context key that marks a worker that holds the memo lock.
+type memoLockKey struct{}
+
+// parallel calls the work function for every index up to the count, with up to
+// the configured number of concurrent workers, each holding the memo lock
+// while it works, and returns when all of the work is done or the context is
+// canceled.
+// Workers overlap only where the work calls unlocked to wait on the network.
+// Work within work, as when reading the versions of a module finishes each
+// version, runs in the same worker, without a pool of its own.
+func (memo *Memo) parallel(ctx context.Context, count int, work func(ctx context.Context, i int)) {
+	workers := memo.Concurrency
+	if workers > count {
+		workers = count
+	}
+	if workers <= 1 || ctx.Value(memoLockKey{}) != nil {
+		for i := 0; i < count; i++ {
+			if ctx.Err() != nil {
+				return
+			}
+			work(ctx, i)
+		}
+		return
+	}
+
+	locked := context.WithValue(ctx, memoLockKey{}, true)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				memo.mu.Lock()
+				work(locked, i)
+				memo.mu.Unlock()
+			}
+		}()
+	}
+
+Feed:
+	for i := 0; i < count; i++ {
+		select {
+		case <-ctx.Done():
+			break Feed
+		case indexes <- i:
+		}
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// unlocked calls a function that waits on the network and must not touch the
+// memo, letting go of the memo lock in the meanwhile if the calling worker
+// holds it.
+func (memo *Memo) unlocked(ctx context.Context, wait func()) {
+	if ctx.Value(memoLockKey{}) == nil {
+		wait()
+		return
+	}
+	memo.mu.Unlock()
+	defer memo.mu.Lock()
+	wait()
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallel(t *testing.T) {
+	memo := &Memo{Concurrency: 4}
+	seen := make(map[int]int)
+	memo.parallel(context.Background(), 100, func(ctx context.Context, i int) {
+		// Workers hold the memo lock, so they may write the map freely.
+		seen[i]++
+		// Work within work runs in the same worker.
+		memo.parallel(ctx, 2, func(ctx context.Context, j int) {
+			seen[100+i*2+j]++
+		})
+	})
+	assert.Len(t, seen, 300)
+	for i, count := range seen {
+		assert.Equal(t, 1, count, "index %d", i)
+	}
+}
+
+func TestParallelUnlocked(t *testing.T) {
+	memo := &Memo{Concurrency: 4}
+	var arrived sync.WaitGroup
+	arrived.Add(4)
+	released := make(chan struct{})
+	go func() {
+		arrived.Wait()
+		close(released)
+	}()
+
+	timeouts := make(chan int, 4)
+	memo.parallel(context.Background(), 4, func(ctx context.Context, i int) {
+		// Every worker must let go of the lock while it waits, or the others
+		// could never arrive.
+		memo.unlocked(ctx, func() {
+			arrived.Done()
+			select {
+			case <-released:
+			case <-time.After(5 * time.Second):
+				timeouts <- i
+			}
+		})
+	})
+	assert.Len(t, timeouts, 0)
+}
+
+func TestParallelCanceled(t *testing.T) {
+	memo := &Memo{Concurrency: 2}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := 0
+	memo.parallel(ctx, 100, func(ctx context.Context, i int) {
+		done++
+		if done == 10 {
+			cancel()
+		}
+	})
+	assert.True(t, done < 100)
+}
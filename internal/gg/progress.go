@@ -19,6 +19,7 @@ import (
 	"io"
 	"io/ioutil"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/chzyer/readline"
@@ -49,7 +50,10 @@ func NewProgress(out, err io.Writer) (io.Writer, *Progress) {
 // Progress wraps an io.Writer that satisfies all of the progress indication
 // methods of the upgrade, add missing, and solve workflows, and debounces
 // progress bar indications.
+// Progress is safe for concurrent use, so that concurrent fetches can report
+// progress and write warnings without garbling the progress bars.
 type Progress struct {
+	mu     sync.Mutex
 	writer io.Writer
 	last   time.Time
 	etas   ETAs
@@ -153,20 +157,20 @@ type progressOut struct {
 }
 
 // ShowState is a solver hook, but does nothing for runtime progress bars.
-func (p Progress) ShowState(_ *State) {}
+func (p *Progress) ShowState(_ *State) {}
 
 // Consider is a solver hook, but does nothing for runtime progress bars.
-func (p Progress) Consider(_ *State, module Module) {
+func (p *Progress) Consider(_ *State, module Module) {
 	// fmt.Fprintf(p, "Consider:   %s\n", module)
 }
 
 // Constrain is a solver hook, but does nothing for runtime progress bars.
-func (p Progress) Constrain(_ *State, module Module) {
+func (p *Progress) Constrain(_ *State, module Module) {
 	// fmt.Fprintf(p, "Constraint: %s\n", module)
 }
 
 // Backtrack is a solver hook, but does nothing for runtime progress bars.
-func (p Progress) Backtrack(_ *State, prev, next Module) {
+func (p *Progress) Backtrack(_ *State, prev, next Module) {
 	// fmt.Fprintf(p, "Back tracking: %v %v\n", prev.Before(next), HashBefore(prev.Hash, next.Hash))
 	// fmt.Fprintf(p, "- %s\n", prev)
 	// fmt.Fprintf(p, "+ %s\n", next)
@@ -174,6 +178,8 @@ func (p Progress) Backtrack(_ *State, prev, next Module) {
 
 // Start indicates that progress has begun for a process of indeterminate duration.
 func (p *Progress) Start(msg string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.erase()
 	now := time.Now()
 	p.etas = p.etas.Update(ETA{msg: msg, start: now})
@@ -182,6 +188,8 @@ func (p *Progress) Start(msg string) {
 
 // Stop indicates that progress has stopped for a process.
 func (p *Progress) Stop(msg string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.erase()
 	p.etas = p.etas.Remove(msg)
 	p.draw(time.Now())
@@ -191,6 +199,8 @@ func (p *Progress) Stop(msg string) {
 // completed steps, total steps, start time, and current time, if a progress
 // indicator has not recently been drawn.
 func (p *Progress) Progress(msg string, num, tot int, start, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.erase()
 
 	if num == 0 || tot == 0 || num == tot {
@@ -220,6 +230,8 @@ func (p *Progress) draw(now time.Time) {
 }
 
 func (p progressOut) Write(bytes []byte) (int, error) {
+	p.err.mu.Lock()
+	defer p.err.mu.Unlock()
 	p.err.erase()
 	count, err := p.out.Write(bytes)
 	p.err.draw(time.Now())
@@ -227,6 +239,8 @@ func (p progressOut) Write(bytes []byte) (int, error) {
 }
 
 func (p *Progress) Write(bytes []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.erase()
 	count, err := p.writer.Write(bytes)
 	p.draw(time.Now())
@@ -0,0 +1,233 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// file releasenotes.go gathers the CHANGELOG.md sections or commit logs for
+// every module that changed between two solutions, and writes them as a
+// Markdown document, suitable for the description of a pull request.
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+// ReleaseNotesLoader reads changelogs and commit logs from the cache.
+type ReleaseNotesLoader interface {
+	ReadChangelog(Module) (string, error)
+	ReadGitLog(old, new plumbing.Hash) (GitLog, error)
+}
+
+// ChangelogSection is a section of a CHANGELOG.md for a version, with the
+// level of its heading and every line of the section including the heading.
+type ChangelogSection struct {
+	Version Version
+	Level   int
+	Lines   []string
+}
+
+// ReleaseNote captures what changed in a module: the changelog sections for
+// every version after the old version up to and including the new version,
+// or failing that, the commit log.
+// Error captures why neither could be read.
+type ReleaseNote struct {
+	Change   ModuleChange
+	Sections []ChangelogSection
+	Log      *GitLog
+	Error    error
+}
+
+// ParseChangelog finds the sections of a CHANGELOG.md that have a version in
+// their heading, as in "## v1.2.0 (2018-01-01)" or "## [1.2.0] - 2018-01-01".
+// A section runs until the next heading at the same or a higher level.
+func ParseChangelog(changelog string) []ChangelogSection {
+	var sections []ChangelogSection
+	var section *ChangelogSection
+	fenced := false
+	for _, line := range strings.Split(changelog, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+		}
+		if level := headingLevel(line); level > 0 && !fenced {
+			if section != nil && level <= section.Level {
+				sections = append(sections, *section)
+				section = nil
+			}
+			if section == nil {
+				if version := headingVersion(line); version != NoVersion {
+					section = &ChangelogSection{Version: version, Level: level}
+				}
+			}
+		}
+		if section != nil {
+			section.Lines = append(section.Lines, line)
+		}
+	}
+	if section != nil {
+		sections = append(sections, *section)
+	}
+	for i := range sections {
+		sections[i].Lines = trimBlankLines(sections[i].Lines)
+	}
+	return sections
+}
+
+// headingLevel returns the level of a Markdown heading, or 0 if the line is
+// not a heading.
+func headingLevel(line string) int {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ') {
+		return 0
+	}
+	return level
+}
+
+// headingVersion returns the first version in the words of a heading.
+func headingVersion(line string) Version {
+	for _, word := range strings.Fields(strings.TrimLeft(line, "#")) {
+		if version := ParseVersion(strings.Trim(word, "[](),:")); version != NoVersion {
+			return version
+		}
+	}
+	return NoVersion
+}
+
+func trimBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// FilterChangelog returns the sections for versions after the old version, up
+// to and including the new version.
+func FilterChangelog(sections []ChangelogSection, old, new Version) []ChangelogSection {
+	var filtered []ChangelogSection
+	for _, section := range sections {
+		if old.Before(section.Version) && !new.Before(section.Version) {
+			filtered = append(filtered, section)
+		}
+	}
+	return filtered
+}
+
+// ReadReleaseNotes reads the release notes for every changed module.
+// Changelogs serve only for modules with versions before and after.
+// Otherwise, or if the changelog has no section for the new versions, the
+// release note has the commit log.
+func ReadReleaseNotes(loader ReleaseNotesLoader, changes []ModuleChange) []ReleaseNote {
+	notes := make([]ReleaseNote, 0, len(changes))
+	for _, change := range changes {
+		note := ReleaseNote{Change: change}
+		if change.HadBefore && change.HasAfter {
+			before, after := change.Before, change.After
+			if before.Version != NoVersion && after.Version != NoVersion && after.Changelog != NoHash {
+				changelog, err := loader.ReadChangelog(after)
+				if err == nil {
+					note.Sections = FilterChangelog(ParseChangelog(changelog), before.Version, after.Version)
+				}
+			}
+			if len(note.Sections) == 0 {
+				log, err := loader.ReadGitLog(before.Hash, after.Hash)
+				if err != nil {
+					note.Error = fmt.Errorf("cannot read the log from %s to %s: %s", before.Summary(), after.Summary(), err)
+				} else {
+					note.Log = &log
+				}
+			}
+		}
+		notes = append(notes, note)
+	}
+	return notes
+}
+
+// WriteReleaseNotes writes release notes as a Markdown document.
+func WriteReleaseNotes(out io.Writer, notes []ReleaseNote) {
+	fmt.Fprintf(out, "# Release notes\n")
+	if len(notes) == 0 {
+		fmt.Fprintf(out, "\nNo modules changed.\n")
+	}
+	for _, note := range notes {
+		change := note.Change
+		fmt.Fprintf(out, "\n")
+		switch {
+		case !change.HadBefore:
+			fmt.Fprintf(out, "## %s (added at %s)\n", change.Name, moduleLabel(change.After))
+			continue
+		case !change.HasAfter:
+			fmt.Fprintf(out, "## %s (removed at %s)\n", change.Name, moduleLabel(change.Before))
+			continue
+		}
+		fmt.Fprintf(out, "## %s %s to %s\n", change.Name, moduleLabel(change.Before), moduleLabel(change.After))
+
+		if note.Error != nil {
+			fmt.Fprintf(out, "\n_%s_\n", note.Error)
+		}
+		for _, section := range note.Sections {
+			fmt.Fprintf(out, "\n")
+			// Nest the version headings under the heading for the module.
+			shift := 3 - section.Level
+			fenced := false
+			for _, line := range section.Lines {
+				if strings.HasPrefix(strings.TrimSpace(line), "```") {
+					fenced = !fenced
+				}
+				if level := headingLevel(line); level > 0 && !fenced {
+					nested := level + shift
+					if nested > 6 {
+						nested = 6
+					}
+					line = strings.Repeat("#", nested) + line[level:]
+				}
+				fmt.Fprintf(out, "%s\n", line)
+			}
+		}
+		if note.Log != nil {
+			if len(note.Log.Commits) > 0 {
+				fmt.Fprintf(out, "\nCommits:\n\n")
+				writeMarkdownCommits(out, note.Log.Commits)
+			}
+			if divergence, warning := note.Log.Divergence(moduleLabel(change.Before), moduleLabel(change.After)); divergence != "" {
+				if warning {
+					fmt.Fprintf(out, "\n**Warning:** %s\n", divergence)
+				} else {
+					fmt.Fprintf(out, "\n%s\n", divergence)
+				}
+				if len(note.Log.Dropped) > 0 {
+					fmt.Fprintf(out, "\nCommits of %s that %s lacks:\n\n", moduleLabel(change.Before), moduleLabel(change.After))
+					writeMarkdownCommits(out, note.Log.Dropped)
+				}
+			}
+		}
+	}
+}
+
+func writeMarkdownCommits(out io.Writer, commits []LogCommit) {
+	for _, commit := range commits {
+		flags := ""
+		if commit.Merge {
+			flags += " **merge**"
+		}
+		if commit.Revert {
+			flags += " **revert**"
+		}
+		fmt.Fprintf(out, "- `%s` %s (%s, %s)%s\n", commit.Hash.String()[:8], commit.Subject, commit.Author, commit.Time.Format("2006-01-02"), flags)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+const testChangelog = `# Changelog
+
+## Unreleased
+- Nothing yet.
+
+## v1.2.0 (2018-03-01)
+### Added
+- Widgets.
+
+` + "```" + `
+# not a heading
+` + "```" + `
+
+## [1.1.0] - 2018-02-01
+- Gadgets.
+
+## 1.0.0
+- Initial release.
+`
+
+func TestParseChangelog(t *testing.T) {
+	sections := ParseChangelog(testChangelog)
+	var versions []Version
+	for _, section := range sections {
+		versions = append(versions, section.Version)
+	}
+	assert.Equal(t, []Version{{1, 2, 0}, {1, 1, 0}, {1, 0, 0}}, versions)
+	assert.Equal(t, []string{
+		"## v1.2.0 (2018-03-01)",
+		"### Added",
+		"- Widgets.",
+		"",
+		"```",
+		"# not a heading",
+		"```",
+	}, sections[0].Lines)
+
+	filtered := FilterChangelog(sections, Version{1, 0, 0}, Version{1, 1, 0})
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, Version{1, 1, 0}, filtered[0].Version)
+}
+
+type fakeReleaseNotesLoader struct {
+	changelogs map[plumbing.Hash]string
+	log        GitLog
+}
+
+func (l fakeReleaseNotesLoader) ReadChangelog(module Module) (string, error) {
+	changelog, ok := l.changelogs[module.Changelog]
+	if !ok {
+		return "", fmt.Errorf("no changelog")
+	}
+	return changelog, nil
+}
+
+func (l fakeReleaseNotesLoader) ReadGitLog(old, new plumbing.Hash) (GitLog, error) {
+	return l.log, nil
+}
+
+func TestWriteReleaseNotes(t *testing.T) {
+	changelog := plumbing.NewHash("c4a2e1000000000000000000000000000000000d")
+	commit := plumbing.NewHash("a28ced3c00000000000000000000000000000000")
+	loader := fakeReleaseNotesLoader{
+		changelogs: map[plumbing.Hash]string{changelog: testChangelog},
+		log: GitLog{
+			Descendant: true,
+			Commits: []LogCommit{
+				{
+					Hash:    commit,
+					Author:  "Robotto Botdroid",
+					Time:    time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC),
+					Subject: "Merge pull request #1",
+					Merge:   true,
+				},
+			},
+		},
+	}
+	before := Modules{
+		{Name: "avery", Version: Version{1, 0, 0}},
+		{Name: "blake", Version: Version{0, 1, 0}},
+		{Name: "drew", Version: Version{2, 0, 0}},
+	}
+	after := Modules{
+		{Name: "avery", Version: Version{1, 2, 0}, Hash: commit, Changelog: changelog},
+		{Name: "blake", Version: Version{0, 1, 1}, Hash: commit},
+		{Name: "carey", Version: Version{3, 0, 0}},
+	}
+
+	var buf bytes.Buffer
+	WriteReleaseNotes(&buf, ReadReleaseNotes(loader, DiffModules(before, after)))
+	assert.Equal(t, "# Release notes\n"+`
+## avery 1.0.0 to 1.2.0
+
+### v1.2.0 (2018-03-01)
+#### Added
+- Widgets.
+
+`+"```"+`
+# not a heading
+`+"```"+`
+
+### [1.1.0] - 2018-02-01
+- Gadgets.
+
+## blake 0.1.0 to 0.1.1
+
+Commits:
+
+- `+"`a28ced3c`"+` Merge pull request #1 (Robotto Botdroid, 2018-01-02) **merge**
+
+## carey (added at 3.0.0)
+
+## drew (removed at 2.0.0)
+`, buf.String())
+}
+
+func TestWriteReleaseNotesDowngrade(t *testing.T) {
+	commit := plumbing.NewHash("a28ced3c00000000000000000000000000000000")
+	loader := fakeReleaseNotesLoader{
+		log: GitLog{
+			Ancestor: true,
+			Dropped: []LogCommit{
+				{
+					Hash:    commit,
+					Author:  "Robotto Botdroid",
+					Time:    time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC),
+					Subject: "Add gadgets",
+				},
+			},
+		},
+	}
+	before := Modules{{Name: "avery", Version: Version{1, 1, 0}, Hash: commit}}
+	after := Modules{{Name: "avery", Version: Version{1, 0, 0}}}
+
+	var buf bytes.Buffer
+	WriteReleaseNotes(&buf, ReadReleaseNotes(loader, DiffModules(before, after)))
+	assert.Equal(t, "# Release notes\n"+`
+## avery 1.1.0 to 1.0.0
+
+1.0.0 is older than 1.1.0, so this is a downgrade.
+
+Commits of 1.1.0 that 1.0.0 lacks:
+
+- `+"`a28ced3c`"+` Add gadgets (Robotto Botdroid, 2018-01-02)
+`, buf.String())
+}
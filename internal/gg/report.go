@@ -0,0 +1,199 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// file report.go provides stable, machine-readable renditions of the reports
+// that gg otherwise writes for humans, for scripts that would otherwise scrape
+// the text.
+// Each report encodes as JSON by its fields, or as tab-separated values with a
+// header row, with one row for each record.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// FormatText writes reports for humans, with colors.
+	FormatText = "text"
+	// FormatJSON writes reports as indented JSON objects.
+	FormatJSON = "json"
+	// FormatTSV writes reports as tab-separated values with a header row.
+	FormatTSV = "tsv"
+)
+
+// ParseFormat validates the name of an output format.
+func ParseFormat(format string) (string, error) {
+	switch format {
+	case FormatText, FormatJSON, FormatTSV:
+		return format, nil
+	}
+	return "", fmt.Errorf("unrecognized output format %q, expected %q, %q, or %q", format, FormatText, FormatJSON, FormatTSV)
+}
+
+// Report is a report with a machine-readable schema.
+type Report interface {
+	// Header returns the names of the columns of the TSV rendition.
+	Header() []string
+	// Rows returns the records of the TSV rendition.
+	Rows() [][]string
+}
+
+// WriteReport writes a report in the given machine-readable format.
+func WriteReport(out io.Writer, format string, report Report) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case FormatTSV:
+		if err := writeTSVRow(out, report.Header()); err != nil {
+			return err
+		}
+		for _, row := range report.Rows() {
+			if err := writeTSVRow(out, row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot write a report in %q format", format)
+}
+
+// tsvEscaper escapes the characters that would otherwise break a row or cell.
+var tsvEscaper = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+func writeTSVRow(out io.Writer, row []string) error {
+	cells := make([]string, len(row))
+	for i, cell := range row {
+		cells[i] = tsvEscaper.Replace(cell)
+	}
+	_, err := fmt.Fprintf(out, "%s\n", strings.Join(cells, "\t"))
+	return err
+}
+
+// ModuleRecord is the machine-readable schema for a module.
+// Hashes of the module's lockfiles and changelog are empty if absent.
+type ModuleRecord struct {
+	Name      string   `json:"name"`
+	Hash      string   `json:"hash"`
+	Version   string   `json:"version"`
+	Ref       string   `json:"ref"`
+	Time      string   `json:"time"`
+	Test      bool     `json:"test"`
+	Remote    string   `json:"remote"`
+	Glidelock string   `json:"glidelock"`
+	Deplock   string   `json:"deplock"`
+	Modlock   string   `json:"modlock"`
+	Changelog string   `json:"changelog"`
+	Warnings  []string `json:"warnings"`
+	Error     string   `json:"error"`
+}
+
+// NewModuleRecord returns the machine-readable record of a module.
+func NewModuleRecord(module Module) ModuleRecord {
+	record := ModuleRecord{
+		Name:      module.Name,
+		Hash:      HashString(module.Hash),
+		Version:   module.VersionString(),
+		Ref:       module.Ref,
+		Test:      module.Test,
+		Remote:    module.Remote,
+		Glidelock: HashString(module.Glidelock),
+		Deplock:   HashString(module.Deplock),
+		Modlock:   HashString(module.Modlock),
+		Changelog: HashString(module.Changelog),
+		Warnings:  append([]string{}, module.Warnings...),
+	}
+	if module.Time != (time.Time{}) {
+		record.Time = module.Time.UTC().Format(time.RFC3339)
+	}
+	if err := module.Failure(); err != nil {
+		record.Error = err.Error()
+	}
+	return record
+}
+
+// NewModuleRecords returns the machine-readable records of modules.
+func NewModuleRecords(modules Modules) []ModuleRecord {
+	records := make([]ModuleRecord, 0, len(modules))
+	for _, module := range modules {
+		records = append(records, NewModuleRecord(module))
+	}
+	return records
+}
+
+// newOptionalModuleRecord returns the record of a module, or nil if there is
+// no such module, which encodes as null.
+func newOptionalModuleRecord(module Module, ok bool) *ModuleRecord {
+	if !ok {
+		return nil
+	}
+	record := NewModuleRecord(module)
+	return &record
+}
+
+// moduleColumns are the names of the columns of a module in a TSV row, which
+// are fewer than the fields of its JSON rendition.
+var moduleColumns = []string{"name", "hash", "version", "ref", "time", "test", "remote"}
+
+// moduleHeader returns the names of the module columns, with a prefix to
+// distinguish multiple modules in the same row.
+func moduleHeader(prefix string) []string {
+	header := make([]string, len(moduleColumns))
+	for i, column := range moduleColumns {
+		header[i] = prefix + column
+	}
+	return header
+}
+
+// Cells returns the module columns of a TSV row, all empty for a nil record.
+func (record *ModuleRecord) Cells() []string {
+	if record == nil {
+		return make([]string, len(moduleColumns))
+	}
+	return []string{
+		record.Name,
+		record.Hash,
+		record.Version,
+		record.Ref,
+		record.Time,
+		strconv.FormatBool(record.Test),
+		record.Remote,
+	}
+}
+
+// ModulesReport is a report that lists modules.
+type ModulesReport struct {
+	Modules []ModuleRecord `json:"modules"`
+}
+
+// Header returns the names of the columns of the TSV rendition.
+func (report ModulesReport) Header() []string {
+	return moduleHeader("")
+}
+
+// Rows returns a TSV row for every module.
+func (report ModulesReport) Rows() [][]string {
+	rows := make([][]string, 0, len(report.Modules))
+	for i := range report.Modules {
+		rows = append(rows, report.Modules[i].Cells())
+	}
+	return rows
+}
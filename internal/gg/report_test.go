@@ -0,0 +1,129 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat(t *testing.T) {
+	for _, format := range []string{FormatText, FormatJSON, FormatTSV} {
+		got, err := ParseFormat(format)
+		require.NoError(t, err)
+		assert.Equal(t, format, got)
+	}
+	_, err := ParseFormat("yaml")
+	assert.EqualError(t, err, `unrecognized output format "yaml", expected "text", "json", or "tsv"`)
+}
+
+func TestNewModuleRecord(t *testing.T) {
+	record := NewModuleRecord(Module{
+		Name:      "example.com/alpha",
+		Hash:      averyHash,
+		Version:   Version{1, 2, 3},
+		Ref:       "tags/v1.2.3",
+		Time:      time.Date(2018, 1, 2, 3, 4, 5, 0, time.FixedZone("PST", -8*60*60)),
+		Remote:    "https://example.com/alpha.git",
+		Glidelock: blakeHash,
+		Warnings:  []string{"tab\tseparated"},
+	})
+	assert.Equal(t, ModuleRecord{
+		Name:      "example.com/alpha",
+		Hash:      averyHashString,
+		Version:   "1.2.3",
+		Ref:       "tags/v1.2.3",
+		Time:      "2018-01-02T11:04:05Z",
+		Remote:    "https://example.com/alpha.git",
+		Glidelock: blakeHashString,
+		Warnings:  []string{"tab\tseparated"},
+	}, record)
+	assert.Equal(t, []string{
+		"example.com/alpha",
+		averyHashString,
+		"1.2.3",
+		"tags/v1.2.3",
+		"2018-01-02T11:04:05Z",
+		"false",
+		"https://example.com/alpha.git",
+	}, record.Cells())
+	assert.Equal(t, make([]string, len(moduleColumns)), (*ModuleRecord)(nil).Cells())
+}
+
+var (
+	reportAlpha = Module{Name: "example.com/alpha", Hash: averyHash, Version: Version{1, 0, 0}, Ref: "tags/v1.0.0"}
+	reportBeta  = Module{Name: "example.com/beta", Hash: blakeHash, Ref: "heads/master", Test: true}
+	reportGamma = Module{Name: "example.com/gamma", Hash: careyHash, Ref: "heads/feature\tbranch"}
+)
+
+func TestWriteDiffReportTSV(t *testing.T) {
+	before := Modules{reportAlpha, reportBeta}
+	after := Modules{reportAlpha, reportGamma}
+
+	var out bytes.Buffer
+	require.NoError(t, WriteReport(&out, FormatTSV, NewDiffReport(before, after)))
+	assert.Equal(t, ""+
+		"name\tbefore_name\tbefore_hash\tbefore_version\tbefore_ref\tbefore_time\tbefore_test\tbefore_remote\tafter_name\tafter_hash\tafter_version\tafter_ref\tafter_time\tafter_test\tafter_remote\n"+
+		"example.com/beta\texample.com/beta\t"+blakeHashString+"\t\theads/master\t\ttrue\t\t\t\t\t\t\t\t\n"+
+		"example.com/gamma\t\t\t\t\t\t\t\texample.com/gamma\t"+careyHashString+"\t\theads/feature\\tbranch\t\tfalse\t\n",
+		out.String())
+}
+
+func TestWriteConflictsReportJSON(t *testing.T) {
+	wantAlpha := reportAlpha
+	wantAlpha.Hash = drewHash
+	wantAlpha.Version = Version{2, 0, 0}
+	wantAlpha.Ref = "tags/v2.0.0"
+	beta := reportBeta
+	beta.Modules = Modules{wantAlpha, reportGamma}
+
+	var out bytes.Buffer
+	require.NoError(t, WriteReport(&out, FormatJSON, NewConflictsReport(Modules{reportAlpha, beta})))
+
+	var report struct {
+		Conflicts []struct {
+			Kind   string
+			Module struct{ Name string }
+			Want   struct{ Name, Version string }
+			Got    *struct{ Name, Version string }
+		}
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	require.Len(t, report.Conflicts, 2)
+
+	missing := report.Conflicts[0]
+	assert.Equal(t, "missing", missing.Kind)
+	assert.Equal(t, "example.com/beta", missing.Module.Name)
+	assert.Equal(t, "example.com/gamma", missing.Want.Name)
+	assert.Nil(t, missing.Got)
+
+	version := report.Conflicts[1]
+	assert.Equal(t, "version", version.Kind)
+	assert.Equal(t, "example.com/beta", version.Module.Name)
+	assert.Equal(t, "2.0.0", version.Want.Version)
+	require.NotNil(t, version.Got)
+	assert.Equal(t, "1.0.0", version.Got.Version)
+}
+
+func TestWriteModulesReportJSONEmpty(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WriteReport(&out, FormatJSON, ModulesReport{Modules: NewModuleRecords(nil)}))
+	assert.Equal(t, "{\n  \"modules\": []\n}\n", out.String())
+}
@@ -0,0 +1,363 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// file session.go saves the states of a driver session to a file in .gg and
+// loads them back, so that a long investigation can be paused and resumed, or
+// shared with a teammate.
+// A session captures the staged and marked states, and the history and future
+// for back and fore.
+// Every state in the session, including those that partial solutions
+// backtrack to, appears once in a table of states, and every module appears
+// once in a table of modules, since consecutive states share most of their
+// modules and back-tracking states.
+// Loading a session finishes every module again from the cache, fetching
+// those that the cache lacks.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+// SessionsPath is the location of saved sessions in the working copy.
+var SessionsPath = filepath.Join(GGCachePath, "sessions")
+
+// NoSessionState indicates the absence of a state in a session, as for a
+// partial solution that has no state to backtrack to.
+const NoSessionState = -1
+
+// Session is the serializable form of the states of a driver.
+// States and modules refer to other states and modules by their index in the
+// session's tables.
+type Session struct {
+	Prev    int             `json:"prev"`
+	Next    int             `json:"next"`
+	History []int           `json:"history"`
+	Future  []int           `json:"future"`
+	States  []SessionState  `json:"states"`
+	Modules []SessionModule `json:"modules"`
+}
+
+// SessionState is the serializable form of a State.
+// The problem is implied by the frontier.
+type SessionState struct {
+	Frontier  []int                     `json:"frontier"`
+	Solution  map[string]SessionPartial `json:"solution"`
+	Dependees map[string][]string       `json:"dependees"`
+}
+
+// SessionPartial is the serializable form of a Partial.
+type SessionPartial struct {
+	Module int `json:"module"`
+	Back   int `json:"back"`
+}
+
+// SessionModule is the serializable form of a Module, with enough to find the
+// module again in the cache or at its remote.
+type SessionModule struct {
+	Name    string `json:"name"`
+	Hash    string `json:"hash,omitempty"`
+	Version string `json:"version,omitempty"`
+	Ref     string `json:"ref,omitempty"`
+	Remote  string `json:"remote,omitempty"`
+	Test    bool   `json:"test,omitempty"`
+}
+
+// SessionLoader finishes the modules of a loaded session.
+type SessionLoader interface {
+	FinishModules(context.Context, ProgressWriter, Modules) error
+}
+
+// NewSession captures the marked and staged states, and the history and
+// future of a driver.
+func NewSession(prev, next *State, history, future []*State) *Session {
+	encoder := sessionEncoder{
+		states:  make(map[*State]int),
+		modules: make(map[SessionModule]int),
+	}
+	session := &Session{
+		Prev:    encoder.state(prev),
+		Next:    encoder.state(next),
+		History: encoder.list(history),
+		Future:  encoder.list(future),
+	}
+	encoder.flush()
+	session.States = encoder.session.States
+	session.Modules = encoder.session.Modules
+	return session
+}
+
+type sessionEncoder struct {
+	session Session
+	states  map[*State]int
+	modules map[SessionModule]int
+	// pending are the states that have an index but have not yet been
+	// encoded, which we visit with a work list instead of recursion, since
+	// chains of back-tracking states can run as long as the solver ran.
+	pending []*State
+}
+
+func (encoder *sessionEncoder) list(states []*State) []int {
+	indexes := make([]int, 0, len(states))
+	for _, state := range states {
+		indexes = append(indexes, encoder.state(state))
+	}
+	return indexes
+}
+
+func (encoder *sessionEncoder) state(state *State) int {
+	if state == nil {
+		return NoSessionState
+	}
+	if index, ok := encoder.states[state]; ok {
+		return index
+	}
+	index := len(encoder.session.States)
+	encoder.states[state] = index
+	encoder.session.States = append(encoder.session.States, SessionState{})
+	encoder.pending = append(encoder.pending, state)
+	return index
+}
+
+func (encoder *sessionEncoder) flush() {
+	for len(encoder.pending) > 0 {
+		state := encoder.pending[0]
+		encoder.pending = encoder.pending[1:]
+		index := encoder.states[state]
+
+		frontier := make([]int, 0, len(state.Frontier))
+		for _, module := range state.Frontier {
+			frontier = append(frontier, encoder.module(module))
+		}
+		solution := make(map[string]SessionPartial, len(state.Solution))
+		for name, partial := range state.Solution {
+			solution[name] = SessionPartial{
+				Module: encoder.module(partial.Module),
+				Back:   encoder.state(partial.Back),
+			}
+		}
+		dependees := make(map[string][]string, len(state.Dependees))
+		for name, targets := range state.Dependees {
+			dependees[name] = targets.Keys()
+		}
+		encoder.session.States[index] = SessionState{
+			Frontier:  frontier,
+			Solution:  solution,
+			Dependees: dependees,
+		}
+	}
+}
+
+func (encoder *sessionEncoder) module(module Module) int {
+	saved := SessionModule{
+		Name:    module.Name,
+		Hash:    HashString(module.Hash),
+		Version: module.VersionString(),
+		Ref:     module.Ref,
+		Remote:  module.Remote,
+		Test:    module.Test,
+	}
+	if index, ok := encoder.modules[saved]; ok {
+		return index
+	}
+	index := len(encoder.session.Modules)
+	encoder.modules[saved] = index
+	encoder.session.Modules = append(encoder.session.Modules, saved)
+	return index
+}
+
+// Restore finishes the modules of a session and returns the marked and staged
+// states, and the history and future.
+func (session *Session) Restore(ctx context.Context, loader SessionLoader, out ProgressWriter) (prev, next *State, history, future []*State, err error) {
+	modules := make(Modules, 0, len(session.Modules))
+	for _, saved := range session.Modules {
+		version, prerelease := ParseSemver(saved.Version)
+		module := Module{
+			Name:       saved.Name,
+			Version:    version,
+			Prerelease: prerelease,
+			Ref:        saved.Ref,
+			Remote:     saved.Remote,
+		}
+		if saved.Hash != "" {
+			module.Hash = plumbing.NewHash(saved.Hash)
+		}
+		modules = append(modules, module)
+	}
+	if err := loader.FinishModules(ctx, out, modules); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	// The memo shares finished modules among modules with the same hash, so
+	// we restore whether each module is needed only for tests afterward.
+	for i, saved := range session.Modules {
+		modules[i].Test = saved.Test
+	}
+
+	get := func(index int) (Module, error) {
+		if index < 0 || index >= len(modules) {
+			return Module{}, fmt.Errorf("session refers to module %d of %d", index, len(modules))
+		}
+		return modules[index], nil
+	}
+
+	states := make([]*State, len(session.States))
+	for i := range states {
+		states[i] = NewState()
+	}
+	state := func(index int) (*State, error) {
+		if index == NoSessionState {
+			return nil, nil
+		}
+		if index < 0 || index >= len(states) {
+			return nil, fmt.Errorf("session refers to state %d of %d", index, len(states))
+		}
+		return states[index], nil
+	}
+
+	for i, saved := range session.States {
+		restored := states[i]
+		for _, index := range saved.Frontier {
+			module, err := get(index)
+			if err != nil {
+				return nil, nil, nil, nil, err
+			}
+			restored.Problem[module.Name] = len(restored.Frontier)
+			restored.Frontier = append(restored.Frontier, module)
+		}
+		for name, partial := range saved.Solution {
+			module, err := get(partial.Module)
+			if err != nil {
+				return nil, nil, nil, nil, err
+			}
+			back, err := state(partial.Back)
+			if err != nil {
+				return nil, nil, nil, nil, err
+			}
+			restored.Solution[name] = Partial{Module: module, Back: back}
+		}
+		for name, targets := range saved.Dependees {
+			restored.Dependees[name] = NewStringSet(targets)
+		}
+	}
+
+	list := func(indexes []int) ([]*State, error) {
+		var restored []*State
+		for _, index := range indexes {
+			state, err := state(index)
+			if err != nil {
+				return nil, err
+			}
+			if state != nil {
+				restored = append(restored, state)
+			}
+		}
+		return restored, nil
+	}
+
+	if prev, err = state(session.Prev); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if next, err = state(session.Next); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if history, err = list(session.History); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if future, err = list(session.Future); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if prev == nil {
+		prev = NewState()
+	}
+	if next == nil {
+		next = NewState()
+	}
+	return prev, next, history, future, nil
+}
+
+// SessionFile returns the location of the file for a named session, or an
+// error if the name is not suitable for a file name.
+func SessionFile(name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid session name %q, expected a name without slashes or a leading dot", name)
+	}
+	return filepath.Join(SessionsPath, name+".json"), nil
+}
+
+// WriteSession writes a session to the file for the given name in .gg.
+func WriteSession(name string, session *Session) error {
+	path, err := SessionFile(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(GGCachePath); os.IsNotExist(err) {
+		// Hide a new .gg directory from the working copy, as for a new
+		// cache.
+		if err := os.MkdirAll(GGCachePath, 0755); err != nil {
+			return err
+		}
+		_ = ioutil.WriteFile(filepath.Join(GGCachePath, ".gitignore"), []byte("*\n"), 0644)
+	}
+	if err := os.MkdirAll(SessionsPath, 0755); err != nil {
+		return err
+	}
+	bytes, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(bytes, '\n'), 0644)
+}
+
+// ReadSession reads the session with the given name from .gg.
+func ReadSession(name string) (*Session, error) {
+	path, err := SessionFile(name)
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		names := SessionNames()
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no session named %q, and no sessions saved in %s", name, SessionsPath)
+		}
+		return nil, fmt.Errorf("no session named %q, expected one of %s", name, strings.Join(names, ", "))
+	} else if err != nil {
+		return nil, err
+	}
+	var session Session
+	if err := json.Unmarshal(bytes, &session); err != nil {
+		return nil, fmt.Errorf("cannot read session %q from %s: %s", name, path, err)
+	}
+	return &session, nil
+}
+
+// SessionNames returns the names of the saved sessions in .gg, in order.
+func SessionNames() []string {
+	paths, _ := filepath.Glob(filepath.Join(SessionsPath, "*.json"))
+	names := make([]string, 0, len(paths))
+	for _, path := range paths {
+		names = append(names, strings.TrimSuffix(filepath.Base(path), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
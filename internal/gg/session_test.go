@@ -0,0 +1,94 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRoundTrip(t *testing.T) {
+	loader := NewFakeLoader(Modules{
+		{
+			Name: "avery",
+			Modules: Modules{
+				{Name: "blake", Version: Version{1, 0, 0}},
+				{Name: "carey", Test: true},
+			},
+		},
+		{
+			Name:    "blake",
+			Version: Version{1, 0, 0},
+		},
+		{
+			Name: "carey",
+		},
+	})
+	progress := &LogSolverProgress{}
+	ctx := context.Background()
+
+	marked := NewState()
+	constrained, err := marked.Constrain(ctx, loader, progress, Modules{{Name: "avery"}}, false)
+	require.NoError(t, err)
+	solved, err := constrained.Solve(ctx, loader, progress)
+	require.NoError(t, err)
+
+	session := NewSession(marked, solved, []*State{constrained, solved}, []*State{marked})
+	data, err := json.Marshal(session)
+	require.NoError(t, err)
+	var read Session
+	require.NoError(t, json.Unmarshal(data, &read))
+
+	prev, next, history, future, err := read.Restore(ctx, loader, progress)
+	require.NoError(t, err)
+
+	require.Len(t, history, 2)
+	require.Len(t, future, 1)
+	assert.True(t, prev == future[0], "the marked state and the future state are the same state")
+	assert.True(t, next == history[1], "the staged state and the last state in history are the same state")
+	assert.True(t, solved.Modules().Equal(next.Modules()))
+	assert.Equal(t, constrained.Frontier.Index(), history[0].Frontier.Index())
+	assert.Equal(t, constrained.Problem, history[0].Problem)
+	assert.Equal(t, solved.Dependees, next.Dependees)
+	assert.True(t, next.Solution["carey"].Module.Test)
+	assert.False(t, next.Solution["blake"].Module.Test)
+
+	// Every partial solution backtracks to the same state as before.
+	states := make(map[*State]*State)
+	for name, partial := range solved.Solution {
+		back := next.Solution[name].Back
+		require.NotNil(t, back, name)
+		if want, ok := states[partial.Back]; ok {
+			assert.True(t, want == back, name)
+		}
+		states[partial.Back] = back
+		assert.True(t, partial.Back.Modules().Equal(back.Modules()), name)
+	}
+}
+
+func TestSessionFile(t *testing.T) {
+	path, err := SessionFile("upgrade")
+	require.NoError(t, err)
+	assert.Equal(t, ".gg/sessions/upgrade.json", path)
+
+	for _, name := range []string{"", ".hidden", "a/b", `a\b`} {
+		_, err := SessionFile(name)
+		assert.Error(t, err, name)
+	}
+}
@@ -51,12 +51,18 @@ package gg
 // explored.
 //
 // The solver is finished when it finds a state where the frontier is empty.
+//
+// The solver does not stop for modules that it cannot fetch or read.
+// It leaves them in the solution without their dependencies and reports every
+// such module in a multi-error alongside the partial solution.
 
 import (
 	"context"
 	"fmt"
 	"sort"
 	"time"
+
+	"go.uber.org/multierr"
 )
 
 // SolverProgress handles progress notifications from the constraint solver.
@@ -71,9 +77,11 @@ type SolverProgress interface {
 
 // SolverLoader ensures that each module has been fetched, then normalizes each
 // module by filling in missing fields.
+// The loader also replaces versions that the configuration excludes.
 type SolverLoader interface {
 	FinishModules(context.Context, ProgressWriter, Modules) error
 	FinishModule(context.Context, ProgressWriter, *Module) error
+	ReplaceExcluded(context.Context, ProgressWriter, Modules) Modules
 }
 
 // State represents a state of the constraint solver.
@@ -165,18 +173,30 @@ func (state *State) Has(name string) bool {
 // Solve is a state trampoline that moves modules from the problem to the
 // solution, considering each module and adding its constraints, until the
 // it exhausts the frontier.
+// Solve returns the solution even if it cannot fetch or read some modules,
+// along with a multi-error that accounts for every failed module.
 func (state *State) Solve(ctx context.Context, loader SolverLoader, out SolverProgress) (*State, error) {
 	start := time.Now()
 
-	var err error
+	var errs error
 	for len(state.Frontier) > 0 {
+		if err := ctx.Err(); err != nil {
+			return state, multierr.Append(errs, err)
+		}
+
 		out.ShowState(state)
 		consider := state.Frontier[0]
 
 		status := fmt.Sprintf("Considering %s", consider.Summary())
 		out.Start(status)
 		state = state.Consider(consider)
-		state, err = state.Constrain(ctx, loader, out, consider.Modules, consider.Test)
+		errs = AppendUniqueError(errs, consider.Failure())
+		next, err := state.Constrain(ctx, loader, out, consider.Modules, consider.Test)
+		if err != nil {
+			errs = AppendUniqueError(errs, fmt.Errorf("cannot add dependencies of module %s: %s", consider.Summary(), err))
+		} else {
+			state = next
+		}
 		out.Stop(status)
 
 		// Progress indicator
@@ -184,20 +204,34 @@ func (state *State) Solve(ctx context.Context, loader SolverLoader, out SolverPr
 		den := len(state.Frontier) + num
 		now := time.Now()
 		out.Progress("Solving dependency graph", num, den, start, now)
+	}
+	out.ShowState(state)
+	return state, errs
+}
 
-		if err != nil {
-			return state, err
+// AppendUniqueError appends an error to a multi-error, unless the multi-error
+// already has an error with the same message.
+// Solver workflows may encounter the same failed module many times.
+func AppendUniqueError(errs error, err error) error {
+	priors := make(StringSet)
+	for _, prior := range multierr.Errors(errs) {
+		priors.Add(prior.Error())
+	}
+	for _, err := range multierr.Errors(err) {
+		if !priors.Has(err.Error()) {
+			priors.Add(err.Error())
+			errs = multierr.Append(errs, err)
 		}
 	}
-	out.ShowState(state)
-	return state, nil
+	return errs
 }
 
 // Add is a shorthand for adding a single constraint and re-running the solver
 // to completion.
+// If the module cannot be read, Add returns the given state with the error.
 func (state *State) Add(ctx context.Context, loader SolverLoader, out SolverProgress, module Module) (*State, error) {
 	if err := loader.FinishModule(ctx, out, &module); err != nil {
-		return nil, err
+		return state, err
 	}
 	return state.lock(loader, out, module).Solve(ctx, loader, out)
 }
@@ -259,11 +293,14 @@ func (state *State) lock(loader SolverLoader, out SolverProgress, choice Module)
 // frontier, if it is not already in the solution with a better version.
 // If necessary, constrain will back-track to a prior solution to upgrade a
 // module that is already in the solution.
+// Constrain moves past any excluded version to the next acceptable version,
+// as the loader reports.
 func (state *State) Constrain(ctx context.Context, loader SolverLoader, out SolverProgress, modules Modules, test bool) (*State, error) {
 	var err error
 	if err = loader.FinishModules(ctx, out, modules); err != nil {
 		return state, err
 	}
+	modules = loader.ReplaceExcluded(ctx, out, modules)
 
 	// Back-track for any module that we have already considered for the
 	// solution but need to upgrade.
@@ -375,12 +412,14 @@ func (state *State) Consider(choice Module) *State {
 // Removing a module does *not* release its transitive dependencies.
 // We do not have enough information to be absolutely certain that nothing in
 // the working copy also retains these modules.
+// Like Solve, Remove returns a partial solution along with the errors for
+// every module it could not fetch or read.
 func (state *State) Remove(ctx context.Context, loader SolverLoader, out SolverProgress, name string) (*State, error) {
 	// Pre-solve to ensure that the dependees table is full and the frontier is
 	// empty.
-	state, err := state.Solve(ctx, loader, out)
-	if err != nil {
-		return nil, err
+	state, errs := state.Solve(ctx, loader, out)
+	if err := ctx.Err(); err != nil {
+		return state, errs
 	}
 
 	// Create a list of existing constraints, less those that are transitive
@@ -393,20 +432,15 @@ func (state *State) Remove(ctx context.Context, loader SolverLoader, out SolverP
 		}
 	}
 
-	state = NewState()
-
-	state, err = state.Constrain(ctx, loader, out, constraints, false)
+	prior := state
+	state, err := NewState().Constrain(ctx, loader, out, constraints, false)
 	if err != nil {
-		return nil, err
+		return prior, AppendUniqueError(errs, err)
 	}
 
 	// Move all entries from frontier to solution.
 	state, err = state.Solve(ctx, loader, out)
-	if err != nil {
-		return nil, err
-	}
-
-	return state, nil
+	return state, AppendUniqueError(errs, err)
 }
 
 // Modules returns a slice of Modules from both the solution and the unsolved
@@ -33,6 +33,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/multierr"
 )
 
 func TestSolver(t *testing.T) {
@@ -269,6 +270,46 @@ func TestSolver(t *testing.T) {
 	}
 }
 
+func TestSolverFailures(t *testing.T) {
+	loader := NewFakeLoader(Modules{
+		{
+			Name: "avery",
+			Modules: Modules{
+				{Name: "carey", Version: Version{1, 0, 0}},
+			},
+		},
+		{
+			Name: "blake",
+			Modules: Modules{
+				{Name: "drew", Version: Version{1, 0, 0}},
+			},
+		},
+		{
+			Name:    "drew",
+			Version: Version{1, 0, 0},
+		},
+	})
+
+	ctx := context.Background()
+	progress := &LogSolverProgress{}
+	state, err := NewState().Constrain(ctx, loader, progress, Modules{
+		{Name: "avery"},
+		{Name: "blake"},
+	}, false)
+	require.NoError(t, err)
+
+	state, err = state.Solve(ctx, loader, progress)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "carey@1.0.0")
+	assert.Len(t, multierr.Errors(err), 1)
+
+	names := make([]string, 0, len(state.Solution))
+	for _, module := range state.Modules() {
+		names = append(names, module.Name)
+	}
+	assert.Equal(t, []string{"avery", "blake", "carey", "drew"}, names)
+}
+
 func TestAdd(t *testing.T) {
 	ctx := context.Background()
 
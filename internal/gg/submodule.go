@@ -0,0 +1,162 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// file submodule.go reads the git submodules of a module, so that packages in
+// submodules appear in the package graph and the vendor checkout as if they
+// were part of the module's own tree.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/multierr"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// Submodule is a git submodule of a module: the path of its gitlink relative
+// to the root of the module, the remote it comes from, and the commit the
+// gitlink refers to.
+type Submodule struct {
+	Path   string
+	Remote string
+	Hash   plumbing.Hash
+}
+
+// ReadGitSubmodules reads the submodules of a git tree from the .gitmodules
+// at its root and the corresponding gitlinks in the tree.
+// Relative submodule URLs are relative to the given remote of the tree's own
+// repository.
+// Like git, we ignore submodules that have no gitlink in the tree.
+func ReadGitSubmodules(tree *object.Tree, remote string) ([]Submodule, error) {
+	file, err := tree.File(".gitmodules")
+	if err == object.ErrFileNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	data, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read .gitmodules: %s", err)
+	}
+	modules := config.NewModules()
+	if err := modules.Unmarshal([]byte(data)); err != nil {
+		return nil, fmt.Errorf("cannot parse .gitmodules: %s", err)
+	}
+
+	submodules := make([]Submodule, 0, len(modules.Submodules))
+	for _, module := range modules.Submodules {
+		if err := module.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid submodule %q in .gitmodules: %s", module.Name, err)
+		}
+		path := strings.Trim(module.Path, "/")
+		entry, err := tree.FindEntry(path)
+		if err != nil || entry.Mode != filemode.Submodule {
+			continue
+		}
+		submodules = append(submodules, Submodule{
+			Path:   path,
+			Remote: SubmoduleRemote(remote, module.URL),
+			Hash:   entry.Hash,
+		})
+	}
+	sort.Slice(submodules, func(i, j int) bool {
+		return submodules[i].Path < submodules[j].Path
+	})
+	return submodules, nil
+}
+
+// SubmoduleRemote resolves the URL of a submodule from .gitmodules, which may
+// be relative to the remote of the parent repository, as in "../other.git".
+func SubmoduleRemote(parent, url string) string {
+	if !strings.HasPrefix(url, "./") && !strings.HasPrefix(url, "../") {
+		return url
+	}
+	base := strings.TrimSuffix(parent, "/")
+	for {
+		if strings.HasPrefix(url, "./") {
+			url = strings.TrimPrefix(url, "./")
+		} else if strings.HasPrefix(url, "../") {
+			url = strings.TrimPrefix(url, "../")
+			if index := strings.LastIndexAny(base, "/:"); index >= 0 {
+				// Retain the colon of an scp-like remote, as in
+				// "git@github.com:".
+				if base[index] == ':' {
+					index++
+				}
+				base = base[:index]
+			}
+		} else {
+			break
+		}
+	}
+	if strings.HasSuffix(base, ":") {
+		return base + url
+	}
+	return base + "/" + url
+}
+
+// FetchSubmodules ensures that the commit of every submodule of a module's
+// commit is in the cache, fetching each submodule's remote into its own
+// vendor references when necessary, and following nested submodules.
+// The tree walker and checkout rely on these commits.
+func (memo *Memo) FetchSubmodules(ctx context.Context, out ProgressWriter, module Module, commit *object.Commit) error {
+	tree, err := memo.Repository.TreeObject(commit.TreeHash)
+	if err != nil {
+		return fmt.Errorf("error attempting to get a Git tree to find submodules of %s: %s", module.Summary(), err)
+	}
+	return memo.fetchSubmodules(ctx, out, module.Name, module.Remote, tree)
+}
+
+func (memo *Memo) fetchSubmodules(ctx context.Context, out ProgressWriter, name, remote string, tree *object.Tree) error {
+	submodules, err := ReadGitSubmodules(tree, remote)
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	for _, submodule := range submodules {
+		path := name + "/" + submodule.Path
+		commit, err := memo.Commit(ctx, out, submodule.Hash)
+		if err != nil {
+			// The submodule is fetched like a module named for its remote,
+			// so the configured remote patterns still apply.
+			fetch := Module{
+				Name:   RootForRemote(submodule.Remote),
+				Remote: submodule.Remote,
+			}
+			if err := memo.Fetch(ctx, out, &fetch, FetchMaxAttempts); err != nil {
+				errs = multierr.Append(errs, fmt.Errorf("cannot fetch submodule %s from %s: %s", path, submodule.Remote, err))
+				continue
+			}
+			if commit, err = memo.Commit(ctx, out, submodule.Hash); err != nil {
+				errs = multierr.Append(errs, fmt.Errorf("submodule %s commit %s does not exist at %s", path, submodule.Hash, submodule.Remote))
+				continue
+			}
+		}
+		tree, err := memo.Repository.TreeObject(commit.TreeHash)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("error attempting to get a Git tree for submodule %s: %s", path, err))
+			continue
+		}
+		errs = multierr.Append(errs, memo.fetchSubmodules(ctx, out, path, submodule.Remote, tree))
+	}
+	return errs
+}
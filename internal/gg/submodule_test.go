@@ -0,0 +1,164 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+func TestSubmoduleRemote(t *testing.T) {
+	table := []struct {
+		parent string
+		url    string
+		want   string
+	}{
+		{"https://github.com/a/b", "https://github.com/c/d", "https://github.com/c/d"},
+		{"https://github.com/a/b", "../c", "https://github.com/a/c"},
+		{"https://github.com/a/b.git", "../../c/d.git", "https://github.com/c/d.git"},
+		{"https://github.com/a/b/", "./c", "https://github.com/a/b/c"},
+		{"git@github.com:a/b", "../c", "git@github.com:a/c"},
+		{"git@github.com:b", "../c", "git@github.com:c"},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.parent+" "+tt.url, func(t *testing.T) {
+			assert.Equal(t, tt.want, SubmoduleRemote(tt.parent, tt.url))
+		})
+	}
+}
+
+func TestGitSubmodules(t *testing.T) {
+	repo, err := git.Init(memory.NewStorage(), nil)
+	require.NoError(t, err)
+
+	lib := testGitTree(t, repo, []object.TreeEntry{
+		{Name: "lib.go", Mode: filemode.Regular, Hash: testGitBlob(t, repo, "package lib\n")},
+	})
+	libCommit := testGitCommit(t, repo, lib)
+	missing := plumbing.NewHash("d8887717615a0000000000000000000000000000")
+
+	gitmodules := `[submodule "lib"]
+	path = third_party/lib
+	url = ../lib.git
+[submodule "missing"]
+	path = missing
+	url = https://example.com/missing.git
+[submodule "removed"]
+	path = removed
+	url = https://example.com/removed.git
+`
+	thirdParty := testGitTree(t, repo, []object.TreeEntry{
+		{Name: "lib", Mode: filemode.Submodule, Hash: libCommit},
+	})
+	root := testGitTree(t, repo, []object.TreeEntry{
+		{Name: ".gitmodules", Mode: filemode.Regular, Hash: testGitBlob(t, repo, gitmodules)},
+		{Name: "main.go", Mode: filemode.Regular, Hash: testGitBlob(t, repo, "package main\n")},
+		{Name: "missing", Mode: filemode.Submodule, Hash: missing},
+		{Name: "third_party", Mode: filemode.Dir, Hash: thirdParty},
+	})
+	tree, err := repo.TreeObject(root)
+	require.NoError(t, err)
+
+	submodules, err := ReadGitSubmodules(tree, "https://example.com/example.git")
+	require.NoError(t, err)
+	assert.Equal(t, []Submodule{
+		{Path: "missing", Remote: "https://example.com/missing.git", Hash: missing},
+		{Path: "third_party/lib", Remote: "https://example.com/lib.git", Hash: libCommit},
+	}, submodules)
+
+	var paths []string
+	var failed []string
+	walker := Walk("example.com", GitEntry{
+		name: "example",
+		mode: filemode.Dir,
+		hash: root,
+		repo: repo,
+	})
+	for {
+		path, _, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			failed = append(failed, path)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	assert.Equal(t, []string{
+		"example.com/example",
+		"example.com/example/.gitmodules",
+		"example.com/example/main.go",
+		"example.com/example/third_party",
+		"example.com/example/third_party/lib",
+		"example.com/example/third_party/lib/lib.go",
+	}, paths)
+	assert.Equal(t, []string{"example.com/example/missing"}, failed)
+
+	module := &Module{Name: "example.com/example"}
+	require.NoError(t, ReadGitPackages(nil, repo, tree, module))
+	assert.Equal(t, []string{
+		"example.com/example/third_party/lib",
+	}, module.Packages.Exports.Keys())
+	assert.Len(t, module.Warnings, 1)
+}
+
+func testGitBlob(t *testing.T, repo *git.Repository, content string) plumbing.Hash {
+	obj := repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	writer, err := obj.Writer()
+	require.NoError(t, err)
+	_, err = writer.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	require.NoError(t, err)
+	return hash
+}
+
+func testGitTree(t *testing.T, repo *git.Repository, entries []object.TreeEntry) plumbing.Hash {
+	obj := repo.Storer.NewEncodedObject()
+	require.NoError(t, (&object.Tree{Entries: entries}).Encode(obj))
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	require.NoError(t, err)
+	return hash
+}
+
+func testGitCommit(t *testing.T, repo *git.Repository, tree plumbing.Hash) plumbing.Hash {
+	signature := object.Signature{
+		Name: "Robotto Botdroid",
+		When: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	obj := repo.Storer.NewEncodedObject()
+	require.NoError(t, (&object.Commit{
+		Author:    signature,
+		Committer: signature,
+		Message:   "First",
+		TreeHash:  tree,
+	}).Encode(obj))
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	require.NoError(t, err)
+	return hash
+}
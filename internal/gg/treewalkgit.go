@@ -15,6 +15,7 @@
 package gg
 
 import (
+	"fmt"
 	"io"
 
 	git "gopkg.in/src-d/go-git.v4"
@@ -37,8 +38,15 @@ func (g GitEntry) Name() string {
 }
 
 // IsDir returns whether the entry is a directory.
+// The walker descends into submodules as directories.
 func (g GitEntry) IsDir() bool {
-	return g.mode == filemode.Dir
+	return g.mode == filemode.Dir || g.mode == filemode.Submodule
+}
+
+// IsSubmodule returns whether the entry is a submodule, which addresses a
+// commit, possibly from another repository.
+func (g GitEntry) IsSubmodule() bool {
+	return g.mode == filemode.Submodule
 }
 
 // Hash returns the hash of the git object this entry addresses.
@@ -46,6 +54,11 @@ func (g GitEntry) Hash() plumbing.Hash {
 	return g.hash
 }
 
+// GitFile returns the mode and hash of a blob entry.
+func (g GitEntry) GitFile() (VendorFile, error) {
+	return VendorFile{Mode: g.mode, Hash: g.hash}, nil
+}
+
 // Reader reads a blob entry.
 func (g GitEntry) Reader() (io.ReadCloser, error) {
 	blob, err := g.repo.BlobObject(g.hash)
@@ -55,9 +68,19 @@ func (g GitEntry) Reader() (io.ReadCloser, error) {
 	return blob.Reader()
 }
 
-// List reads a tree entry.
+// List reads a tree entry, or the tree of the commit of a submodule entry.
+// The commit of a submodule must be fetched into the same repository, as
+// Memo.FetchSubmodules does.
 func (g GitEntry) List() ([]TreeEntry, error) {
-	tree, err := g.repo.TreeObject(g.hash)
+	hash := g.hash
+	if g.IsSubmodule() {
+		commit, err := g.repo.CommitObject(g.hash)
+		if err != nil {
+			return nil, fmt.Errorf("submodule commit %s has not been fetched: %s", g.hash, err)
+		}
+		hash = commit.TreeHash
+	}
+	tree, err := g.repo.TreeObject(hash)
 	if err != nil {
 		return nil, err
 	}
@@ -16,16 +16,19 @@ package gg
 
 import (
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 
 	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
 )
 
 // FSEntry represents an entry in a filesystem directory.
 type FSEntry struct {
 	path  string
 	isDir bool
+	mode  os.FileMode
 }
 
 // Name returns the name of the entry.
@@ -60,6 +63,7 @@ func (n FSEntry) List() ([]TreeEntry, error) {
 		list = append(list, &FSEntry{
 			path:  filepath.Join(n.path, name),
 			isDir: mode.IsDir(),
+			mode:  mode,
 		})
 	}
 	return list, nil
@@ -69,3 +73,26 @@ func (n FSEntry) List() ([]TreeEntry, error) {
 func (n FSEntry) Reader() (io.ReadCloser, error) {
 	return os.Open(n.path)
 }
+
+// GitFile returns the mode and the hash of the blob that git would record for
+// the file, where the blob of a symbolic link is its target.
+func (n FSEntry) GitFile() (VendorFile, error) {
+	mode, err := filemode.NewFromOSFileMode(n.mode)
+	if err != nil {
+		return VendorFile{}, err
+	}
+	var data []byte
+	if mode == filemode.Symlink {
+		target, err := os.Readlink(n.path)
+		if err != nil {
+			return VendorFile{}, err
+		}
+		data = []byte(target)
+	} else if data, err = ioutil.ReadFile(n.path); err != nil {
+		return VendorFile{}, err
+	}
+	return VendorFile{
+		Mode: mode,
+		Hash: plumbing.ComputeHash(plumbing.BlobObject, data),
+	}, nil
+}
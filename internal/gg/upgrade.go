@@ -28,6 +28,19 @@ type UpgradeLoader interface {
 	Fetch(context.Context, ProgressWriter, *Module, int) error
 	DigestRefs(context.Context, ProgressWriter, Module) error
 	ReadVersions(context.Context, ProgressWriter, Module) (Modules, error)
+	PrefetchVersions(context.Context, ProgressWriter, Modules)
+}
+
+// Prereleases indicates which modules may upgrade to pre-release versions,
+// either every module or those named.
+type Prereleases struct {
+	All     bool
+	Modules StringSet
+}
+
+// Allow returns whether the named module may upgrade to a pre-release.
+func (prereleases Prereleases) Allow(name string) bool {
+	return prereleases.All || prereleases.Modules.Has(name)
 }
 
 // UpgradeProgress provides progress notifications and warnings for the
@@ -44,13 +57,37 @@ type UpgradeProgress interface {
 // Otherwise, if the module does not have a known git reference or version, the upgrader
 // will promote a revision to the latest known semantic version or any revision
 // with a newer commit timestamp on the master branch.
-func Upgrade(ctx context.Context, loader UpgradeLoader, out UpgradeProgress, state *State) (*State, error) {
+// The given policies may hold modules back, limit them to narrower or wider
+// version ranges, or have them follow a branch, and the upgrader reports every
+// module that a policy holds back.
+// The upgrader also passes over any upgrade that would move another module,
+// through the lockfile of the upgrade or its dependencies, further than the
+// hold, patch, or minor policy for that module allows.
+// The upgrader passes over pre-releases except for the modules that the given
+// prereleases allow, and passes over every version that the given exclusions
+// rule out.
+// The upgrader does not stop for modules it cannot fetch or upgrade, but
+// returns the upgraded state with a multi-error that accounts for every such
+// module.
+func Upgrade(ctx context.Context, loader UpgradeLoader, out UpgradeProgress, state *State, policies Policies, prereleases Prereleases, exclusions Exclusions) (*State, error) {
 	start := time.Now()
 	reviewed := make(StringSet)
+	var errs error
 	var done bool
 	for !done {
 		done = true
 		modules := state.Modules()
+
+		// Fetch and read the versions of every module we have yet to review
+		// at once, so the upgrade proceeds without waiting on each in turn.
+		unreviewed := make(Modules, 0, len(modules))
+		for _, module := range modules {
+			if !reviewed.Has(module.Name) {
+				unreviewed = append(unreviewed, module)
+			}
+		}
+		loader.PrefetchVersions(ctx, out, unreviewed)
+
 		for _, module := range modules {
 			if _, ok := reviewed[module.Name]; ok {
 				continue
@@ -64,17 +101,23 @@ func Upgrade(ctx context.Context, loader UpgradeLoader, out UpgradeProgress, sta
 			now := time.Now()
 			out.Progress("Upgrading", num, tot, start, now)
 
-			next, err := upgradeModule(ctx, loader, out, state, module)
-			if err != nil {
-				return nil, err
+			next, err := upgradeModule(ctx, loader, out, state, module, policies, prereleases.Allow(module.Name), exclusions)
+			errs = AppendUniqueError(errs, err)
+			if err := ctx.Err(); err != nil {
+				return state, errs
 			}
 			state = next
 		}
 	}
-	return state, nil
+	return state, errs
 }
 
-func upgradeModule(ctx context.Context, loader UpgradeLoader, out UpgradeProgress, state *State, module Module) (*State, error) {
+func upgradeModule(ctx context.Context, loader UpgradeLoader, out UpgradeProgress, state *State, module Module, policies Policies, prereleases bool, exclusions Exclusions) (*State, error) {
+	policy := policies.Find(module.Name)
+	if policy.Mode == PolicyHold {
+		fmt.Fprintf(out, "Skipping %s, held by the %s.\n", module.Summary(), policy.Reason())
+		return state, nil
+	}
 	if err := loader.Fetch(ctx, out, &module, FetchMaxAttempts); err != nil {
 		fmt.Fprintf(out, "warning while attempting to fetch %s: %s\n", module.Summary(), err)
 	}
@@ -84,18 +127,68 @@ func upgradeModule(ctx context.Context, loader UpgradeLoader, out UpgradeProgres
 
 	modules, err := loader.ReadVersions(ctx, out, module)
 	if err != nil {
-		return state, err
+		return state, fmt.Errorf("cannot read versions of module %s: %s", module.Summary(), err)
+	}
+	modules = exclusions.Filter(modules)
+	upgrade := findUpgradeModule(modules, module, policy, prereleases)
+	if policy.Mode == PolicyTrackBranch {
+		if _, ok := modules.FindBranch(policy.Branch); !ok {
+			fmt.Fprintf(out, "Skipping %s, which has no branch %s for the %s.\n", module.Summary(), policy.Branch, policy.Reason())
+		}
+	} else if policy.Mode != "" {
+		if beyond := findUpgradeModule(modules, module, Policy{}, prereleases); upgrade.Before(beyond) {
+			fmt.Fprintf(out, "Holding %s short of %s by the %s.\n", upgrade.Summary(), beyond.Summary(), policy.Reason())
+		}
 	}
-	upgrade := findUpgradeModule(modules, module)
 	if upgrade.Equal(module) {
 		return state, nil
 	}
-	return state.Add(ctx, loader, out, upgrade)
+	next, err := state.Add(ctx, loader, out, upgrade)
+	if prior, moved, policy, ok := policyViolation(state, next, policies, upgrade.Name); ok {
+		fmt.Fprintf(out, "Skipping %s, which would move %s to %s against the %s.\n", upgrade.Summary(), prior.Summary(), moved.Summary(), policy.Reason())
+		return state, nil
+	}
+	return next, err
 }
 
-func findUpgradeModule(modules Modules, module Module) Module {
+// policyViolation returns the first module, by name, that moved from its
+// version in the prior state to another version in the next state further
+// than the hold, patch, or minor policy for it allows, with both versions and
+// the policy, and whether there is one, passing over the named module.
+func policyViolation(prior, next *State, policies Policies, skip string) (Module, Module, Policy, bool) {
+	for _, moved := range next.Modules() {
+		if moved.Name == skip {
+			continue
+		}
+		partial, ok := prior.Solution[moved.Name]
+		if !ok || partial.Module.Hash == moved.Hash {
+			continue
+		}
+		policy := policies.Find(moved.Name)
+		switch policy.Mode {
+		case PolicyHold, PolicyPatch, PolicyMinor:
+			if !policy.Allows(partial.Module, moved) {
+				return partial.Module, moved, policy, true
+			}
+		}
+	}
+	return Module{}, Module{}, Policy{}, false
+}
+
+// findUpgradeModule returns the newest version of a module that the policy
+// allows, or the module itself if there is none.
+func findUpgradeModule(modules Modules, module Module, policy Policy, prereleases bool) Module {
+	if policy.Mode == PolicyTrackBranch {
+		if head, ok := modules.FindBranch(policy.Branch); ok {
+			return head
+		}
+		return module
+	}
 	for _, upgrade := range modules {
-		if module.CanUpgradeTo(upgrade) {
+		if upgrade.Prerelease != NoPrerelease && !prereleases {
+			continue
+		}
+		if policy.Allows(module, upgrade) {
 			module = upgrade
 		}
 	}
@@ -36,6 +36,10 @@ func TestUpgrade(t *testing.T) {
 			Name:    "avery",
 			Version: Version{1, 0, 0},
 		},
+		{
+			Name:    "avery",
+			Version: Version{1, 0, 1},
+		},
 		{
 			Name:    "avery",
 			Version: Version{1, 1, 0},
@@ -49,17 +53,61 @@ func TestUpgrade(t *testing.T) {
 			Ref:  "heads/master",
 			Time: time.Unix(0, 0),
 		},
+		{
+			Name:    "casey",
+			Version: Version{1, 0, 0},
+		},
+		{
+			Name:       "casey",
+			Version:    Version{1, 1, 0},
+			Prerelease: "rc.1",
+		},
+		{
+			Name:       "casey",
+			Version:    Version{1, 1, 0},
+			Prerelease: "rc.2",
+		},
 		{
 			Name: "blake",
 			Ref:  "heads/master",
 			Time: time.Unix(0, 0).Add(24 * time.Hour),
 		},
+		{
+			Name: "blake",
+			Ref:  "heads/develop",
+			Time: time.Unix(0, 0).Add(12 * time.Hour),
+		},
+		{
+			Name:    "drew",
+			Version: Version{1, 0, 0},
+			Modules: Modules{
+				{Name: "erin", Version: Version{1, 0, 0}},
+			},
+		},
+		{
+			Name:    "drew",
+			Version: Version{1, 1, 0},
+			Modules: Modules{
+				{Name: "erin", Version: Version{1, 1, 0}},
+			},
+		},
+		{
+			Name:    "erin",
+			Version: Version{1, 0, 0},
+		},
+		{
+			Name:    "erin",
+			Version: Version{1, 1, 0},
+		},
 	})
 
 	tests := []struct {
-		name string
-		give Modules
-		want Modules
+		name        string
+		give        Modules
+		policies    Policies
+		prereleases Prereleases
+		exclusions  Exclusions
+		want        Modules
 	}{
 		{
 			name: "ex nihilo nihil fit",
@@ -82,6 +130,134 @@ func TestUpgrade(t *testing.T) {
 				loader.MustGetVersion("avery", NoVersion),
 			},
 		},
+		{
+			name: "avery 1.0 to 1.0.1 passing over excluded 1.1",
+			give: Modules{
+				{Name: "avery", Version: Version{1, 0, 0}},
+			},
+			exclusions: Exclusions{
+				{Match: PatternSplit("avery"), Versions: []string{"v1.1.0"}},
+			},
+			want: Modules{
+				loader.MustGetVersion("avery", Version{1, 0, 1}),
+			},
+		},
+		{
+			name: "avery held at 1.0",
+			give: Modules{
+				{Name: "avery", Version: Version{1, 0, 0}},
+			},
+			policies: Policies{
+				{Match: PatternSplit("avery"), Mode: PolicyHold},
+			},
+			want: Modules{
+				loader.MustGetVersion("avery", Version{1, 0, 0}),
+			},
+		},
+		{
+			name: "avery 1.0 to 1.0.1 by patch policy",
+			give: Modules{
+				{Name: "avery", Version: Version{1, 0, 0}},
+			},
+			policies: Policies{
+				{Match: PatternSplit("avery"), Mode: PolicyPatch},
+			},
+			want: Modules{
+				loader.MustGetVersion("avery", Version{1, 0, 1}),
+			},
+		},
+		{
+			name: "avery 1.0 to 2.0 by major policy",
+			give: Modules{
+				{Name: "avery", Version: Version{1, 0, 0}},
+			},
+			policies: Policies{
+				{Match: PatternSplit("*"), Mode: PolicyMajor},
+			},
+			want: Modules{
+				loader.MustGetVersion("avery", Version{2, 0, 0}),
+			},
+		},
+		{
+			name: "blake tracks develop",
+			give: Modules{
+				loader.MustGetTime("blake", time.Unix(0, 0)),
+			},
+			policies: Policies{
+				{Match: PatternSplit("blake"), Mode: PolicyTrackBranch, Branch: "develop"},
+			},
+			want: Modules{
+				loader.MustGetTime("blake", time.Unix(0, 0).Add(12*time.Hour)),
+			},
+		},
+		{
+			name: "drew held at 1.0 because its lock would move held erin",
+			give: Modules{
+				{Name: "drew", Version: Version{1, 0, 0}},
+			},
+			policies: Policies{
+				{Match: PatternSplit("erin"), Mode: PolicyHold},
+			},
+			want: Modules{
+				loader.MustGetVersion("drew", Version{1, 0, 0}),
+				loader.MustGetVersion("erin", Version{1, 0, 0}),
+			},
+		},
+		{
+			name: "drew held at 1.0 because its lock would move erin past a patch policy",
+			give: Modules{
+				{Name: "drew", Version: Version{1, 0, 0}},
+			},
+			policies: Policies{
+				{Match: PatternSplit("erin"), Mode: PolicyPatch},
+			},
+			want: Modules{
+				loader.MustGetVersion("drew", Version{1, 0, 0}),
+				loader.MustGetVersion("erin", Version{1, 0, 0}),
+			},
+		},
+		{
+			name: "drew 1.0 to 1.1 moves erin within a minor policy",
+			give: Modules{
+				{Name: "drew", Version: Version{1, 0, 0}},
+			},
+			policies: Policies{
+				{Match: PatternSplit("erin"), Mode: PolicyMinor},
+			},
+			want: Modules{
+				loader.MustGetVersion("drew", Version{1, 1, 0}),
+				loader.MustGetVersion("erin", Version{1, 1, 0}),
+			},
+		},
+		{
+			name: "casey passes over pre-releases",
+			give: Modules{
+				{Name: "casey", Version: Version{1, 0, 0}},
+			},
+			want: Modules{
+				loader.MustGetVersion("casey", Version{1, 0, 0}),
+			},
+		},
+		{
+			name: "casey upgrades to the latest pre-release if allowed",
+			give: Modules{
+				{Name: "casey", Version: Version{1, 0, 0}},
+			},
+			prereleases: Prereleases{Modules: NewStringSet([]string{"casey"})},
+			want: Modules{
+				loader.MustGetPrerelease("casey", Version{1, 1, 0}, "rc.2"),
+			},
+		},
+		{
+			name: "casey upgrades to pre-releases if all are allowed",
+			give: Modules{
+				{Name: "casey", Version: Version{1, 0, 0}},
+			},
+			prereleases: Prereleases{All: true},
+			want: Modules{
+				loader.MustGetPrerelease("casey", Version{1, 1, 0}, "rc.2"),
+			},
+		},
 		{
 			name: "master branch upgrades based on timestamp",
 			give: Modules{
@@ -114,7 +290,7 @@ func TestUpgrade(t *testing.T) {
 			require.NoError(t, err)
 			state, err = state.Solve(ctx, loader, progress)
 			require.NoError(t, err)
-			state, err = Upgrade(ctx, loader, progress, state)
+			state, err = Upgrade(ctx, loader, progress, state, tt.policies, tt.prereleases, tt.exclusions)
 			require.NoError(t, err)
 			assert.True(t, tt.want.Equal(state.Modules()))
 		})
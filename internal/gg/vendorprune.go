@@ -0,0 +1,197 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// file vendorprune.go decides which files of each module the vendor checkout
+// leaves out, as configured with [prune] and [[prune-module]] in gg.toml, or
+// [prune] in Gopkg.toml.
+// Pruning depends only on the names of files, the settings, and the package
+// graph of the solution and the working copy, so the vendor digest in
+// glide.lock stays stable from one checkout to the next.
+// Vendor pruning is not to be confused with the prune command, which removes
+// unnecessary modules from the solution.
+
+import (
+	"path"
+	"strings"
+)
+
+// VendorPrune is what to leave out of the vendor checkout of a module.
+type VendorPrune struct {
+	// GoTests leaves out _test.go files and testdata directories.
+	GoTests bool
+	// UnusedPackages leaves out the packages that the working copy does not
+	// need, transitively, for its commands and tests.
+	UnusedPackages bool
+	// NonGo leaves out files that the go tool does not build, except for
+	// licences and other legal notices.
+	NonGo bool
+}
+
+// VendorPruneRule sets some or all of the pruning of modules with names that
+// match a pattern.
+// A rule leaves the settings it does not set to the rules that follow.
+type VendorPruneRule struct {
+	// Pattern is the module name pattern, as written in gg.toml, or "..." for
+	// every module.
+	Pattern string
+	// Match is the pattern split into components.
+	Match []string
+	// GoTests sets whether to leave out tests, if not nil.
+	GoTests *bool
+	// UnusedPackages sets whether to leave out unused packages, if not nil.
+	UnusedPackages *bool
+	// NonGo sets whether to leave out non-Go files, if not nil.
+	NonGo *bool
+}
+
+// VendorPruning is an ordered list of pruning rules, the first matching rule
+// that sets each setting taking effect.
+type VendorPruning []VendorPruneRule
+
+// Find returns the pruning of the module with the given name.
+func (pruning VendorPruning) Find(name string) VendorPrune {
+	var goTests, unusedPackages, nonGo *bool
+	for _, rule := range pruning {
+		if !(Pattern{Match: rule.Match}).Matches(name) {
+			continue
+		}
+		if goTests == nil {
+			goTests = rule.GoTests
+		}
+		if unusedPackages == nil {
+			unusedPackages = rule.UnusedPackages
+		}
+		if nonGo == nil {
+			nonGo = rule.NonGo
+		}
+	}
+	return VendorPrune{
+		GoTests:        goTests != nil && *goTests,
+		UnusedPackages: unusedPackages != nil && *unusedPackages,
+		NonGo:          nonGo != nil && *nonGo,
+	}
+}
+
+// Merge returns the rules of this pruning followed by the rules of a farther
+// source of settings, so the farther rules only decide the settings that none
+// of the nearer rules for a module set.
+func (pruning VendorPruning) Merge(farther VendorPruning) VendorPruning {
+	merged := make(VendorPruning, 0, len(pruning)+len(farther))
+	merged = append(merged, pruning...)
+	return append(merged, farther...)
+}
+
+// PrunesUnusedPackages returns whether any of the modules leaves out unused
+// packages, which requires the package graph of the working copy.
+func (pruning VendorPruning) PrunesUnusedPackages(modules Modules) bool {
+	for _, module := range modules {
+		if pruning.Find(module.Name).UnusedPackages {
+			return true
+		}
+	}
+	return false
+}
+
+// VendorPruner decides which files of the vendor checkout to keep, given the
+// pruning rules and the packages that the working copy needs.
+// The zero pruner keeps every file.
+type VendorPruner struct {
+	Pruning VendorPruning
+	// Packages are the packages that the commands and tests of the working
+	// copy import, transitively, which unused package pruning keeps.
+	Packages StringSet
+}
+
+// Keep returns whether the vendor checkout keeps the file of a module with
+// the given slash-separated path relative to the root of the module.
+// Licences and other legal notices are always kept.
+func (pruner VendorPruner) Keep(module, name string) bool {
+	dir, base := path.Split(name)
+	dir = strings.TrimSuffix(dir, "/")
+	if isLegalFile(base) {
+		return true
+	}
+	prune := pruner.Pruning.Find(module)
+	if prune.GoTests && (strings.HasSuffix(base, "_test.go") || isTestdata(dir)) {
+		return false
+	}
+	if prune.NonGo && !isGoSourceFile(base) {
+		return false
+	}
+	if prune.UnusedPackages {
+		pkg := module
+		if dir != "" {
+			pkg = module + "/" + dir
+		}
+		if !pruner.Packages.Has(pkg) {
+			return false
+		}
+	}
+	return true
+}
+
+// legalPrefixes are the beginnings of the lowercase names of files that
+// carry licences and other legal notices, which pruning keeps.
+var legalPrefixes = []string{
+	"licence",
+	"license",
+	"copying",
+	"unlicense",
+	"copyright",
+	"copyleft",
+	"legal",
+	"notice",
+	"disclaimer",
+	"patent",
+	"third-party",
+	"thirdparty",
+}
+
+func isLegalFile(base string) bool {
+	lower := strings.ToLower(base)
+	for _, prefix := range legalPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// goSourceExtensions are the extensions of the files that the go tool builds,
+// including assembly, cgo, and swig sources.
+var goSourceExtensions = NewStringSet([]string{
+	".go",
+	".s", ".S",
+	".c", ".h",
+	".cc", ".cpp", ".cxx", ".hh", ".hpp", ".hxx",
+	".m",
+	".f", ".F", ".for", ".f90",
+	".swig", ".swigcxx",
+	".syso",
+})
+
+func isGoSourceFile(base string) bool {
+	return goSourceExtensions.Has(path.Ext(base))
+}
+
+func isTestdata(dir string) bool {
+	for _, part := range strings.Split(dir, "/") {
+		if part == "testdata" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVendorPrunerKeep(t *testing.T) {
+	yes, no := true, false
+	pruner := VendorPruner{
+		Pruning: VendorPruning{
+			{Pattern: "github.com/gogo/protobuf", Match: PatternSplit("github.com/gogo/protobuf"), NonGo: &no},
+			{Pattern: "example.com/all", Match: PatternSplit("example.com/all"), UnusedPackages: &yes},
+			{Pattern: "example.com/none", Match: PatternSplit("example.com/none"), GoTests: &no, NonGo: &no},
+			{Pattern: "...", Match: PatternSplit("..."), GoTests: &yes, NonGo: &yes},
+		},
+		Packages: NewStringSet([]string{
+			"example.com/all",
+			"example.com/all/used",
+		}),
+	}
+	tests := []struct {
+		msg    string
+		module string
+		name   string
+		want   bool
+	}{
+		{"source", "example.com/some", "some.go", true},
+		{"assembly", "example.com/some", "asm/sum_amd64.s", true},
+		{"test", "example.com/some", "some_test.go", false},
+		{"testdata", "example.com/some", "testdata/fixture.go", false},
+		{"nested testdata", "example.com/some", "sub/testdata/fixture.json", false},
+		{"readme", "example.com/some", "README.md", false},
+		{"licence", "example.com/some", "LICENSE", true},
+		{"licence in package", "example.com/some", "sub/LICENSE.txt", true},
+		{"notice", "example.com/some", "NOTICE", true},
+		{"overridden non-go", "github.com/gogo/protobuf", "gogoproto/gogo.proto", true},
+		{"inherited tests", "github.com/gogo/protobuf", "proto/all_test.go", false},
+		{"overridden everything", "example.com/none", "testdata/README.md", true},
+		{"used root package", "example.com/all", "all.go", true},
+		{"used package", "example.com/all", "used/used.go", true},
+		{"unused package", "example.com/all", "unused/unused.go", false},
+		{"licence of unused package", "example.com/all", "unused/COPYING", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			assert.Equal(t, tt.want, pruner.Keep(tt.module, tt.name))
+		})
+	}
+	assert.True(t, VendorPruner{}.Keep("example.com/some", "testdata/README.md"))
+}
+
+func TestVendorPruningMerge(t *testing.T) {
+	yes, no := true, false
+	config := VendorPruning{
+		{Pattern: "example.com/config", Match: PatternSplit("example.com/config"), NonGo: &no},
+		{Pattern: "...", Match: PatternSplit("..."), NonGo: &yes},
+	}
+	manifest := DepManifestPrune{
+		GoTests:        true,
+		UnusedPackages: true,
+		Projects: []DepManifestPruneProject{
+			{Name: "example.com/dep", NonGo: &no, UnusedPackages: &no},
+		},
+	}.VendorPruning()
+	pruning := config.Merge(manifest)
+
+	patterns := make([]string, 0, len(pruning))
+	for _, rule := range pruning {
+		patterns = append(patterns, rule.Pattern)
+	}
+	assert.Equal(t, []string{"example.com/config", "...", "example.com/dep", "..."}, patterns)
+	assert.Equal(t, VendorPrune{GoTests: true, UnusedPackages: true, NonGo: true}, pruning.Find("example.com/other"))
+	assert.Equal(t, VendorPrune{GoTests: true, UnusedPackages: true}, pruning.Find("example.com/config"))
+	// The settings of gg.toml for every module precede those of Gopkg.toml
+	// for particular modules.
+	assert.Equal(t, VendorPrune{GoTests: true, NonGo: true}, pruning.Find("example.com/dep"))
+	assert.True(t, pruning.PrunesUnusedPackages(Modules{{Name: "example.com/dep"}, {Name: "example.com/other"}}))
+	assert.False(t, pruning.PrunesUnusedPackages(Modules{{Name: "example.com/dep"}}))
+	assert.Equal(t, VendorPrune{}, DepManifestPrune{}.VendorPruning().Find("example.com/other"))
+}
@@ -0,0 +1,374 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// file verify.go compares the vendor checkout in the working copy with the
+// locked commits in the cache, by the same tree hashes git would compute, so
+// we can tell whether anyone has edited, removed, or added vendored files
+// since the last checkout.
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.uber.org/multierr"
+
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// VendorFile is the mode and blob hash that git records for a file.
+type VendorFile struct {
+	Mode filemode.FileMode
+	Hash plumbing.Hash
+}
+
+// VendorFiles are the files of a tree, by their slash-separated path relative
+// to the root of the tree.
+type VendorFiles map[string]VendorFile
+
+// gitFileEntry is a tree entry that can tell the mode and blob hash that git
+// records for a file, which both GitEntry and FSEntry can.
+type gitFileEntry interface {
+	GitFile() (VendorFile, error)
+}
+
+// ReadVendorFiles walks a git tree or a directory in the working copy and
+// returns its files, skipping the given directories, which belong to other
+// modules.
+// Like checkout, the walk replaces submodules with the trees of their commits.
+func ReadVendorFiles(entry TreeEntry, skip StringSet) (VendorFiles, error) {
+	files := make(VendorFiles)
+	walker := Walk("", entry)
+	root, _, err := walker.Next()
+	if err != nil {
+		return nil, err
+	}
+	prefix := root + "/"
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		name = strings.TrimPrefix(name, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s: %s", name, err)
+		}
+		if entry.IsDir() {
+			if skip.Has(name) {
+				walker.Skip()
+			}
+			continue
+		}
+		gitFile, ok := entry.(gitFileEntry)
+		if !ok {
+			return nil, fmt.Errorf("cannot hash %s", name)
+		}
+		file, err := gitFile.GitFile()
+		if err != nil {
+			return nil, fmt.Errorf("cannot hash %s: %s", name, err)
+		}
+		files[name] = file
+	}
+}
+
+// Paths returns the paths of the files in order.
+func (files VendorFiles) Paths() []string {
+	paths := make([]string, 0, len(files))
+	for name := range files {
+		paths = append(paths, name)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// TreeHash returns the hash of the git tree that holds exactly these files.
+// Directories have no entries of their own in git, so an empty directory does
+// not change the hash.
+func (files VendorFiles) TreeHash() plumbing.Hash {
+	return files.treeHash(files.Paths(), "")
+}
+
+// treeHash returns the hash of the tree of the given sorted paths, which all
+// begin with the prefix of the tree.
+func (files VendorFiles) treeHash(paths []string, prefix string) plumbing.Hash {
+	var entries []object.TreeEntry
+	for i := 0; i < len(paths); {
+		rest := strings.TrimPrefix(paths[i], prefix)
+		slash := strings.Index(rest, "/")
+		if slash < 0 {
+			file := files[paths[i]]
+			entries = append(entries, object.TreeEntry{Name: rest, Mode: file.Mode, Hash: file.Hash})
+			i++
+			continue
+		}
+		// The paths of a subtree are contiguous in sorted order.
+		dir := prefix + rest[:slash+1]
+		j := i
+		for j < len(paths) && strings.HasPrefix(paths[j], dir) {
+			j++
+		}
+		entries = append(entries, object.TreeEntry{
+			Name: rest[:slash],
+			Mode: filemode.Dir,
+			Hash: files.treeHash(paths[i:j], dir),
+		})
+		i = j
+	}
+
+	// Git orders the entries of a tree as if the name of every subtree ended
+	// with a slash.
+	sort.Slice(entries, func(i, j int) bool {
+		return gitTreeEntryKey(entries[i]) < gitTreeEntryKey(entries[j])
+	})
+	obj := &plumbing.MemoryObject{}
+	// Encoding to memory does not fail.
+	_ = (&object.Tree{Entries: entries}).Encode(obj)
+	return obj.Hash()
+}
+
+func gitTreeEntryKey(entry object.TreeEntry) string {
+	if entry.Mode == filemode.Dir {
+		return entry.Name + "/"
+	}
+	return entry.Name
+}
+
+// VendorVerification is the result of comparing the vendor checkout of a
+// module with the tree of its locked commit: the hash of each tree, the paths
+// of the files that differ, and an error if the comparison was not possible.
+type VendorVerification struct {
+	Module   Module
+	Locked   plumbing.Hash
+	Vendor   plumbing.Hash
+	Modified []string
+	Missing  []string
+	Extra    []string
+	Error    error
+}
+
+// OK returns whether the vendor checkout of the module matches its locked
+// commit.
+func (verification VendorVerification) OK() bool {
+	return verification.Error == nil && verification.Locked == verification.Vendor
+}
+
+// VerifyVendor compares the checkout of every module in the given vendor
+// directory with the tree of the module's locked commit, less the files that
+// the pruner leaves out.
+// Verification reads only the cache, never the network, so the commits of the
+// modules and their submodules must be in the cache, which they are if the
+// vendor directory was checked out from the same cache.
+func VerifyVendor(repo *git.Repository, vendor string, modules Modules, pruner VendorPruner) []VendorVerification {
+	verifications := make([]VendorVerification, 0, len(modules))
+	for _, module := range modules {
+		verifications = append(verifications, verifyModule(repo, vendor, module, nestedModules(module, modules), pruner))
+	}
+	return verifications
+}
+
+func verifyModule(repo *git.Repository, vendor string, module Module, nested StringSet, pruner VendorPruner) VendorVerification {
+	verification := VendorVerification{Module: module}
+
+	locked, err := readLockedVendorFiles(repo, module, nested, pruner)
+	if err != nil {
+		verification.Error = err
+		return verification
+	}
+	verification.Locked = locked.TreeHash()
+	if module.Tree != NoHash && module.Tree != verification.Locked {
+		verification.Error = fmt.Errorf("the lockfile records tree %s for %s, but its commit has tree %s", module.Tree, module.Summary(), verification.Locked)
+		return verification
+	}
+
+	checkout := make(VendorFiles)
+	dir := filepath.Join(vendor, filepath.FromSlash(module.Name))
+	info, err := os.Stat(dir)
+	if err == nil && info.IsDir() {
+		checkout, err = ReadVendorFiles(FSEntry{path: dir, isDir: true}, nested)
+		if err != nil {
+			verification.Error = fmt.Errorf("cannot read %s: %s", dir, err)
+			return verification
+		}
+	} else if err == nil {
+		verification.Error = fmt.Errorf("cannot verify %s because it is not a directory", dir)
+		return verification
+	} else if !os.IsNotExist(err) {
+		verification.Error = err
+		return verification
+	}
+
+	verification.Vendor = checkout.TreeHash()
+	for _, name := range locked.Paths() {
+		if file, ok := checkout[name]; !ok {
+			verification.Missing = append(verification.Missing, name)
+		} else if file != locked[name] {
+			verification.Modified = append(verification.Modified, name)
+		}
+	}
+	for _, name := range checkout.Paths() {
+		if _, ok := locked[name]; !ok {
+			verification.Extra = append(verification.Extra, name)
+		}
+	}
+	return verification
+}
+
+// VendorDigestVerification is the result of comparing the digest of the whole
+// vendor directory with the digest of the checkout of the locked commits, and
+// the files in vendor that belong to no module.
+type VendorDigestVerification struct {
+	Locked plumbing.Hash
+	Vendor plumbing.Hash
+	Stray  []string
+	Error  error
+}
+
+// OK returns whether the vendor directory matches the checkout of the locked
+// commits.
+func (verification VendorDigestVerification) OK() bool {
+	return verification.Error == nil && verification.Locked == verification.Vendor
+}
+
+// VerifyVendorDigest compares the digest of the given vendor directory with
+// the digest of the checkout of every module's locked commit, as glide.lock
+// records it, and finds the files in vendor that belong to no module, which
+// the verification of each module does not see.
+func VerifyVendorDigest(repo *git.Repository, vendor string, modules Modules, pruner VendorPruner) VendorDigestVerification {
+	var verification VendorDigestVerification
+	_, locked, err := DigestVendor(repo, modules, pruner)
+	if err != nil {
+		verification.Error = err
+		return verification
+	}
+	verification.Locked = locked
+
+	checkout := make(VendorFiles)
+	if _, err := os.Stat(vendor); err == nil {
+		checkout, err = ReadVendorFiles(FSEntry{path: vendor, isDir: true}, nil)
+		if err != nil {
+			verification.Error = fmt.Errorf("cannot read %s: %s", vendor, err)
+			return verification
+		}
+	} else if !os.IsNotExist(err) {
+		verification.Error = err
+		return verification
+	}
+	verification.Vendor = checkout.TreeHash()
+
+	for _, name := range checkout.Paths() {
+		if !vendorFileHasModule(name, modules) {
+			verification.Stray = append(verification.Stray, name)
+		}
+	}
+	return verification
+}
+
+// vendorFileHasModule returns whether the file with the given path, relative
+// to vendor, lies in the checkout of one of the modules.
+func vendorFileHasModule(name string, modules Modules) bool {
+	for _, module := range modules {
+		if strings.HasPrefix(name, module.Name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// DigestVendor returns the modules with the hash of the tree that checkout
+// writes for each in its Tree, and the hash of the tree that checkout writes
+// to the whole vendor directory, which is the digest of the vendor checkout.
+// Like verification, digesting reads only the cache.
+// Modules that cannot be read have no Tree, and then there is no digest of the
+// whole vendor directory, but the other modules retain theirs, along with a
+// multi-error that accounts for every module that cannot be read.
+func DigestVendor(repo *git.Repository, modules Modules, pruner VendorPruner) (Modules, plumbing.Hash, error) {
+	digested := make(Modules, 0, len(modules))
+	vendor := make(VendorFiles)
+	var errs error
+	for _, module := range modules {
+		files, err := readLockedVendorFiles(repo, module, nestedModules(module, modules), pruner)
+		if err != nil {
+			module.Tree = NoHash
+			digested = append(digested, module)
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		module.Tree = files.TreeHash()
+		digested = append(digested, module)
+		for name, file := range files {
+			vendor[module.Name+"/"+name] = file
+		}
+	}
+	if errs != nil {
+		return digested, NoHash, errs
+	}
+	return digested, vendor.TreeHash(), nil
+}
+
+// ReadVendorDigest returns the hash of the git tree of the files in the given
+// vendor directory of the working copy.
+func ReadVendorDigest(vendor string) (plumbing.Hash, error) {
+	files, err := ReadVendorFiles(FSEntry{path: vendor, isDir: true}, nil)
+	if err != nil {
+		return NoHash, err
+	}
+	return files.TreeHash(), nil
+}
+
+// readLockedVendorFiles reads the files that checkout writes for a module
+// from the tree of its locked commit in the cache, less the files that the
+// pruner leaves out.
+func readLockedVendorFiles(repo *git.Repository, module Module, nested StringSet, pruner VendorPruner) (VendorFiles, error) {
+	commit, err := repo.CommitObject(module.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find commit %s of %s in the cache: %s", module.Hash, module.Name, err)
+	}
+	files, err := ReadVendorFiles(GitEntry{
+		name: path.Base(module.Name),
+		mode: filemode.Dir,
+		hash: commit.TreeHash,
+		repo: repo,
+	}, nested)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the tree of %s: %s", module.Summary(), err)
+	}
+	for name := range files {
+		if !pruner.Keep(module.Name, name) {
+			delete(files, name)
+		}
+	}
+	return files, nil
+}
+
+// nestedModules returns the paths, relative to the checkout of a module, of
+// the checkouts of other modules that lie within it, which belong to those
+// modules alone.
+func nestedModules(module Module, modules Modules) StringSet {
+	nested := make(StringSet)
+	for _, other := range modules {
+		if strings.HasPrefix(other.Name, module.Name+"/") {
+			nested.Add(strings.TrimPrefix(other.Name, module.Name+"/"))
+		}
+	}
+	return nested
+}
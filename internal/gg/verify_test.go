@@ -0,0 +1,180 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+func TestVerifyVendor(t *testing.T) {
+	repo, err := git.Init(memory.NewStorage(), nil)
+	require.NoError(t, err)
+
+	// Git orders the subtree a after a-b.go and a.go, unlike a sort by name.
+	sub := testGitTree(t, repo, []object.TreeEntry{
+		{Name: "sub.go", Mode: filemode.Regular, Hash: testGitBlob(t, repo, "package sub\n")},
+	})
+	root := testGitTree(t, repo, []object.TreeEntry{
+		{Name: "a-b.go", Mode: filemode.Regular, Hash: testGitBlob(t, repo, "package a\n")},
+		{Name: "a.go", Mode: filemode.Regular, Hash: testGitBlob(t, repo, "package a\n")},
+		{Name: "a", Mode: filemode.Dir, Hash: sub},
+		{Name: "run.sh", Mode: filemode.Executable, Hash: testGitBlob(t, repo, "#!/bin/sh\n")},
+	})
+	commit := testGitCommit(t, repo, root)
+	nested := testGitCommit(t, repo, sub)
+
+	dir, err := ioutil.TempDir("", "gg-verify")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	write := func(name, content string, mode os.FileMode) {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(name), 0755))
+		require.NoError(t, ioutil.WriteFile(name, []byte(content), mode))
+		require.NoError(t, os.Chmod(name, mode))
+	}
+	write("example.com/a/a-b.go", "package a\n", 0644)
+	write("example.com/a/a.go", "package a\n", 0644)
+	write("example.com/a/a/sub.go", "package sub\n", 0644)
+	write("example.com/a/run.sh", "#!/bin/sh\n", 0755)
+	write("example.com/a/nested/sub.go", "package sub\n", 0644)
+	write("example.com/b/a-b.go", "package b\n", 0644)
+	write("example.com/b/a.go", "package a\n", 0644)
+	write("example.com/b/run.sh", "#!/bin/sh\n", 0644)
+	write("example.com/b/extra.go", "package a\n", 0644)
+
+	modules := Modules{
+		{Name: "example.com/a", Hash: commit},
+		{Name: "example.com/a/nested", Hash: nested},
+		{Name: "example.com/b", Hash: commit},
+		{Name: "example.com/c", Hash: commit},
+		{Name: "example.com/d", Hash: NoHash},
+	}
+	verifications := VerifyVendor(repo, dir, modules, VendorPruner{})
+	require.Len(t, verifications, 5)
+
+	a := verifications[0]
+	assert.NoError(t, a.Error)
+	assert.True(t, a.OK())
+	assert.Equal(t, root, a.Locked)
+	assert.Equal(t, root, a.Vendor)
+
+	assert.True(t, verifications[1].OK())
+	assert.Equal(t, sub, verifications[1].Vendor)
+
+	b := verifications[2]
+	assert.NoError(t, b.Error)
+	assert.False(t, b.OK())
+	assert.Equal(t, root, b.Locked)
+	assert.Equal(t, []string{"a-b.go", "run.sh"}, b.Modified)
+	assert.Equal(t, []string{"a/sub.go"}, b.Missing)
+	assert.Equal(t, []string{"extra.go"}, b.Extra)
+
+	c := verifications[3]
+	assert.NoError(t, c.Error)
+	assert.False(t, c.OK())
+	assert.Equal(t, []string{"a-b.go", "a.go", "a/sub.go", "run.sh"}, c.Missing)
+
+	assert.Error(t, verifications[4].Error)
+	assert.False(t, verifications[4].OK())
+	assert.Equal(t, 3, countUnverified(verifications))
+}
+
+func TestDigestVendor(t *testing.T) {
+	repo, err := git.Init(memory.NewStorage(), nil)
+	require.NoError(t, err)
+	root := testGitTree(t, repo, []object.TreeEntry{
+		{Name: "a.go", Mode: filemode.Regular, Hash: testGitBlob(t, repo, "package a\n")},
+	})
+	commit := testGitCommit(t, repo, root)
+
+	dir, err := ioutil.TempDir("", "gg-digest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"example.com/a", "example.com/b"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, name), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name, "a.go"), []byte("package a\n"), 0644))
+	}
+
+	modules := Modules{
+		{Name: "example.com/a", Hash: commit},
+		{Name: "example.com/b", Hash: commit},
+	}
+	digested, digest, err := DigestVendor(repo, modules, VendorPruner{})
+	require.NoError(t, err)
+	require.Len(t, digested, 2)
+	assert.Equal(t, root, digested[0].Tree)
+	assert.Equal(t, root, digested[1].Tree)
+	assert.Equal(t, NoHash, modules[0].Tree)
+
+	// Modules that are not in the cache have no tree, and then there is no
+	// digest, but the others retain their trees.
+	missing := append(Modules{{Name: "example.com/0", Hash: root}}, modules...)
+	partial, none, err := DigestVendor(repo, missing, VendorPruner{})
+	assert.Error(t, err)
+	assert.Equal(t, NoHash, none)
+	require.Len(t, partial, 3)
+	assert.Equal(t, NoHash, partial[0].Tree)
+	assert.Equal(t, root, partial[1].Tree)
+	assert.Equal(t, root, partial[2].Tree)
+
+	got, err := ReadVendorDigest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, digest, got)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "stray.go"), []byte("package stray\n"), 0644))
+	got, err = ReadVendorDigest(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, digest, got)
+
+	// Stray files belong to no module, so only the digest reveals them.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "example.com/c"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "example.com/c/c.go"), []byte("package c\n"), 0644))
+	for _, verification := range VerifyVendor(repo, dir, modules, VendorPruner{}) {
+		assert.True(t, verification.OK())
+	}
+	verification := VerifyVendorDigest(repo, dir, modules, VendorPruner{})
+	require.NoError(t, verification.Error)
+	assert.False(t, verification.OK())
+	assert.Equal(t, digest, verification.Locked)
+	assert.Equal(t, []string{"example.com/c/c.go", "stray.go"}, verification.Stray)
+
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "example.com/c")))
+	require.NoError(t, os.Remove(filepath.Join(dir, "stray.go")))
+	verification = VerifyVendorDigest(repo, dir, modules, VendorPruner{})
+	assert.True(t, verification.OK())
+	assert.Empty(t, verification.Stray)
+
+	// A lockfile that records a tree other than that of the commit cannot be
+	// verified.
+	modules[0].Tree = commit
+	verifications := VerifyVendor(repo, dir, modules, VendorPruner{})
+	assert.Error(t, verifications[0].Error)
+	assert.True(t, verifications[1].OK())
+}
+
+func TestVendorFilesTreeHashEmpty(t *testing.T) {
+	// The well-known hash of the empty git tree.
+	assert.Equal(t, "4b825dc642cb6eb9a060e54bf8d69288fbee4904", VendorFiles{}.TreeHash().String())
+}
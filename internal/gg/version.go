@@ -26,7 +26,10 @@
 
 package gg
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 // Version represents a major, minor, patch version number.
 type Version [3]int
@@ -83,8 +86,8 @@ func (v Version) nextBreak() Version {
 //  v1.2
 //  v1.2.3
 // All other patterns return NoVersion including versions with beta or release
-// candidate trailers, since these need to be compared by timestamp and do
-// not imply compatibility ranges.
+// candidate trailers.
+// ParseSemver recognizes those.
 func ParseVersion(str string) (version Version) {
 	runes := []rune(str)
 	length := len(runes)
@@ -149,3 +152,118 @@ func ParseVersion(str string) (version Version) {
 		}
 	}
 }
+
+// Prerelease is the dot separated pre-release identifiers of a semantic
+// version, like "rc.1" for 1.2.0-rc.1, or empty for a final release.
+type Prerelease string
+
+// NoPrerelease is the zero value of a pre-release, indicating a final release.
+const NoPrerelease Prerelease = ""
+
+// String returns the pre-release as a version suffix, like "-rc.1", or an
+// empty string for a final release.
+func (p Prerelease) String() string {
+	if p == NoPrerelease {
+		return ""
+	}
+	return "-" + string(p)
+}
+
+// Before returns whether a pre-release precedes another pre-release of the
+// same version, according to SemVer 2.0.
+// A final release follows all of its pre-releases.
+// Otherwise, identifiers compare from left to right, numerically if both are
+// numbers and lexically if neither are, and numbers precede other
+// identifiers.
+// If all of its identifiers are equal, the pre-release with fewer identifiers
+// comes first.
+func (p Prerelease) Before(q Prerelease) bool {
+	if p == q || p == NoPrerelease {
+		return false
+	}
+	if q == NoPrerelease {
+		return true
+	}
+	ps := strings.Split(string(p), ".")
+	qs := strings.Split(string(q), ".")
+	for i := 0; i < len(ps) && i < len(qs); i++ {
+		if ps[i] == qs[i] {
+			continue
+		}
+		pn := isNumericIdentifier(ps[i])
+		qn := isNumericIdentifier(qs[i])
+		switch {
+		case pn && qn:
+			// Numeric identifiers have no leading zeros, so the shorter is
+			// the lesser, and comparing digits avoids overflow.
+			if len(ps[i]) != len(qs[i]) {
+				return len(ps[i]) < len(qs[i])
+			}
+			return ps[i] < qs[i]
+		case pn:
+			return true
+		case qn:
+			return false
+		default:
+			return ps[i] < qs[i]
+		}
+	}
+	return len(ps) < len(qs)
+}
+
+// ParseSemver recognizes the same versions as ParseVersion, optionally
+// followed by pre-release identifiers and build metadata, as in
+// v1.2.0-rc.1+build.5.
+// Build metadata does not bear on the order of versions, so ParseSemver
+// discards it.
+// All other patterns return NoVersion and NoPrerelease.
+func ParseSemver(str string) (Version, Prerelease) {
+	if index := strings.IndexByte(str, '+'); index >= 0 {
+		if !validIdentifiers(str[index+1:], false) {
+			return NoVersion, NoPrerelease
+		}
+		str = str[:index]
+	}
+	prerelease := NoPrerelease
+	if index := strings.IndexByte(str, '-'); index >= 0 {
+		if !validIdentifiers(str[index+1:], true) {
+			return NoVersion, NoPrerelease
+		}
+		prerelease = Prerelease(str[index+1:])
+		str = str[:index]
+	}
+	version := ParseVersion(str)
+	if version == NoVersion {
+		return NoVersion, NoPrerelease
+	}
+	return version, prerelease
+}
+
+// validIdentifiers returns whether a string is a dot separated list of
+// non-empty alphanumeric or hyphen identifiers.
+// Numeric pre-release identifiers must not have leading zeros.
+func validIdentifiers(str string, prerelease bool) bool {
+	for _, identifier := range strings.Split(str, ".") {
+		if identifier == "" {
+			return false
+		}
+		for _, r := range identifier {
+			if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-') {
+				return false
+			}
+		}
+		if prerelease && len(identifier) > 1 && identifier[0] == '0' && isNumericIdentifier(identifier) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNumericIdentifier(identifier string) bool {
+	for _, r := range identifier {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return identifier != ""
+}
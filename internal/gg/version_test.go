@@ -171,3 +171,51 @@ func TestCanUpgradeTo(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSemver(t *testing.T) {
+	tests := []struct {
+		give       string
+		version    Version
+		prerelease Prerelease
+	}{
+		{"v1.2.3", Version{1, 2, 3}, NoPrerelease},
+		{"v1.2.0-rc.1", Version{1, 2, 0}, "rc.1"},
+		{"1.2.0-alpha-2.beta", Version{1, 2, 0}, "alpha-2.beta"},
+		{"v1.2.0+build.5", Version{1, 2, 0}, NoPrerelease},
+		{"v1.2.0-rc.1+build.5", Version{1, 2, 0}, "rc.1"},
+		{"v0.0.1-beta1", Version{0, 0, 1}, "beta1"},
+		{"v1.2.0-", NoVersion, NoPrerelease},
+		{"v1.2.0-rc..1", NoVersion, NoPrerelease},
+		{"v1.2.0-rc.01", NoVersion, NoPrerelease},
+		{"v1.2.0-rc_1", NoVersion, NoPrerelease},
+		{"v1.2.0+", NoVersion, NoPrerelease},
+		{"release-1.2.0", NoVersion, NoPrerelease},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			version, prerelease := ParseSemver(tt.give)
+			assert.Equal(t, tt.version, version)
+			assert.Equal(t, tt.prerelease, prerelease)
+		})
+	}
+}
+
+func TestPrereleaseBefore(t *testing.T) {
+	// The order of precedence from the SemVer 2.0 specification.
+	ordered := []Prerelease{
+		"alpha",
+		"alpha.1",
+		"alpha.beta",
+		"beta",
+		"beta.2",
+		"beta.11",
+		"rc.1",
+		NoPrerelease,
+	}
+	for i, p := range ordered {
+		for j, q := range ordered {
+			assert.Equal(t, i < j, p.Before(q), "%q before %q", p, q)
+		}
+	}
+}
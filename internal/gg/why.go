@@ -0,0 +1,193 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+// file why.go explains how the version of a module entered a solution, by
+// walking the dependees of the module back to the shallow dependencies of the
+// working copy.
+
+import (
+	"fmt"
+	"sort"
+)
+
+// WhyMaxPaths is the most paths that Explain collects, since the number of
+// paths through a large dependency graph can grow exponentially.
+const WhyMaxPaths = 100
+
+// Why explains how a module entered a solution.
+type Why struct {
+	// Module is the version of the module in the solution.
+	Module Module
+	// Shallow indicates that the working copy depends on the module directly.
+	Shallow bool
+	// Requests are the versions of the module that the lockfiles of its
+	// dependees in the solution want.
+	Requests []Dependency
+	// Paths are the paths from the shallow dependencies of the working copy to
+	// the module.
+	Paths []WhyPath
+	// Truncated indicates that there are more than WhyMaxPaths paths.
+	Truncated bool
+}
+
+// WhyPath is a path from a shallow dependency of the working copy to a
+// module, with the version that each module on the path wants of the next.
+type WhyPath struct {
+	Root Module
+	Hops []Dependency
+}
+
+// Demanders returns the requests for exactly the version in the solution.
+func (why Why) Demanders() []Dependency {
+	var demanders []Dependency
+	for _, request := range why.Requests {
+		if DependencyChange(request.Want, request.Got) == "same" {
+			demanders = append(demanders, request)
+		}
+	}
+	return demanders
+}
+
+// Beaten returns the requests for older versions than the version in the
+// solution.
+func (why Why) Beaten() []Dependency {
+	var beaten []Dependency
+	for _, request := range why.Requests {
+		if DependencyChange(request.Want, request.Got) != "same" && request.Want.Before(request.Got) {
+			beaten = append(beaten, request)
+		}
+	}
+	return beaten
+}
+
+// Explain returns how the module with the given name entered the solution of
+// a state.
+func Explain(state *State, name string) (Why, error) {
+	partial, ok := state.Solution[name]
+	if !ok {
+		return Why{}, fmt.Errorf("module %s is not in the staged solution", name)
+	}
+	shallow := ShallowModules(state)
+	why := Why{
+		Module:  partial.Module,
+		Shallow: shallow.Has(name),
+	}
+
+	for _, dependee := range solutionDependees(state, name) {
+		if want, ok := wantOf(state.Solution[dependee].Module, name); ok {
+			why.Requests = append(why.Requests, Dependency{
+				Module: state.Solution[dependee].Module,
+				Want:   want,
+				Got:    partial.Module,
+			})
+		}
+	}
+
+	// Walk from the module back through its dependees to every shallow
+	// dependency, never visiting a module twice on the same path.
+	var walk func(path []string)
+	visiting := StringSet{name: {}}
+	walk = func(path []string) {
+		if why.Truncated {
+			return
+		}
+		last := path[len(path)-1]
+		if shallow.Has(last) && len(path) > 1 {
+			if len(why.Paths) >= WhyMaxPaths {
+				why.Truncated = true
+				return
+			}
+			why.Paths = append(why.Paths, whyPath(state, path))
+		}
+		for _, dependee := range solutionDependees(state, last) {
+			if visiting.Has(dependee) {
+				continue
+			}
+			visiting.Add(dependee)
+			walk(append(path, dependee))
+			delete(visiting, dependee)
+		}
+	}
+	walk([]string{name})
+
+	return why, nil
+}
+
+// whyPath renders a path that runs from a module back to a shallow dependency
+// as a path from the shallow dependency forward to the module.
+func whyPath(state *State, path []string) WhyPath {
+	root := state.Solution[path[len(path)-1]].Module
+	hops := make([]Dependency, 0, len(path)-1)
+	for i := len(path) - 1; i > 0; i-- {
+		dependee := state.Solution[path[i]].Module
+		got := state.Solution[path[i-1]].Module
+		want, _ := wantOf(dependee, got.Name)
+		hops = append(hops, Dependency{Module: dependee, Want: want, Got: got})
+	}
+	return WhyPath{Root: root, Hops: hops}
+}
+
+// wantOf returns the version of a module that a dependee's lockfile wants.
+func wantOf(dependee Module, name string) (Module, bool) {
+	for _, want := range dependee.Modules {
+		if want.Name == name {
+			return want, true
+		}
+	}
+	return Module{}, false
+}
+
+// solutionDependees returns the names of the modules in the solution that
+// depend on the named module, in order.
+func solutionDependees(state *State, name string) []string {
+	names := make([]string, 0, len(state.Dependees[name]))
+	for dependee := range state.Dependees[name] {
+		if _, ok := state.Solution[dependee]; ok {
+			names = append(names, dependee)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ShallowModules returns the names of the modules in a solution that the
+// working copy depends upon directly.
+// These include the modules on the frontier of the state before the solver
+// considered any module, which we find at the end of the chain of states that
+// partial solutions back-track to, and the modules that no other module in
+// the solution depends upon, like those added to the solution later.
+func ShallowModules(state *State) StringSet {
+	shallow := make(StringSet)
+	for name := range state.Solution {
+		if len(solutionDependees(state, name)) == 0 {
+			shallow.Add(name)
+		}
+	}
+
+	origin := state
+	for origin != nil && len(origin.Solution) > 0 {
+		names := origin.Solution.Names()
+		origin = origin.Solution[names[0]].Back
+	}
+	if origin != nil {
+		for _, module := range origin.Frontier {
+			if _, ok := state.Solution[module.Name]; ok {
+				shallow.Add(module.Name)
+			}
+		}
+	}
+	return shallow
+}
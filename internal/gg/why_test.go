@@ -0,0 +1,143 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gg
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	loader := NewFakeLoader(Modules{
+		{
+			Name: "avery",
+			Modules: Modules{
+				{Name: "carey", Version: Version{1, 0, 0}},
+				{Name: "drew"},
+			},
+		},
+		{
+			Name: "blake",
+			Modules: Modules{
+				{Name: "carey", Version: Version{1, 0, 0}},
+			},
+		},
+		{
+			Name:    "carey",
+			Version: Version{1, 0, 0},
+		},
+		{
+			Name:    "carey",
+			Version: Version{1, 1, 0},
+		},
+		{
+			Name: "drew",
+			Modules: Modules{
+				{Name: "carey", Version: Version{1, 1, 0}},
+			},
+		},
+	})
+	progress := &LogSolverProgress{}
+	ctx := context.Background()
+
+	state, err := NewState().Constrain(ctx, loader, progress, Modules{{Name: "avery"}, {Name: "blake"}}, false)
+	require.NoError(t, err)
+	state, err = state.Solve(ctx, loader, progress)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"avery", "blake"}, ShallowModules(state).Keys())
+
+	why, err := Explain(state, "carey")
+	require.NoError(t, err)
+	assert.Equal(t, Version{1, 1, 0}, why.Module.Version)
+	assert.False(t, why.Shallow)
+
+	summaries := func(dependencies []Dependency) []string {
+		var summaries []string
+		for _, dependency := range dependencies {
+			summaries = append(summaries, dependency.Module.Name+" wants "+dependency.Want.Summary())
+		}
+		return summaries
+	}
+	assert.Equal(t, []string{"drew wants carey@1.1.0"}, summaries(why.Demanders()))
+	assert.Equal(t, []string{"avery wants carey@1.0.0", "blake wants carey@1.0.0"}, summaries(why.Beaten()))
+
+	var paths [][]string
+	for _, path := range why.Paths {
+		names := []string{path.Root.Name}
+		for _, hop := range path.Hops {
+			names = append(names, hop.Got.Name)
+		}
+		paths = append(paths, names)
+	}
+	assert.Equal(t, [][]string{
+		{"avery", "carey"},
+		{"blake", "carey"},
+		{"avery", "drew", "carey"},
+	}, paths)
+
+	why, err = Explain(state, "avery")
+	require.NoError(t, err)
+	assert.True(t, why.Shallow)
+	assert.Empty(t, why.Paths)
+
+	_, err = Explain(state, "evelyn")
+	assert.EqualError(t, err, "module evelyn is not in the staged solution")
+}
+
+func TestExplainTruncated(t *testing.T) {
+	// Every root wants the target.
+	// The last root is also in a cycle that leads to no other root, which the
+	// walk visits after it collects the path from the last root.
+	explain := func(roots int) Why {
+		versions := Modules{
+			{Name: "target"},
+			{Name: "zcycle", Modules: Modules{{Name: fmt.Sprintf("root%03d", roots-1)}}},
+		}
+		constraints := make(Modules, 0, roots)
+		for i := 0; i < roots; i++ {
+			name := fmt.Sprintf("root%03d", i)
+			wants := Modules{{Name: "target"}}
+			if i == roots-1 {
+				wants = append(wants, Module{Name: "zcycle"})
+			}
+			versions = append(versions, Module{Name: name, Modules: wants})
+			constraints = append(constraints, Module{Name: name})
+		}
+		loader := NewFakeLoader(versions)
+		progress := &LogSolverProgress{}
+		ctx := context.Background()
+
+		state, err := NewState().Constrain(ctx, loader, progress, constraints, false)
+		require.NoError(t, err)
+		state, err = state.Solve(ctx, loader, progress)
+		require.NoError(t, err)
+		why, err := Explain(state, "target")
+		require.NoError(t, err)
+		return why
+	}
+
+	why := explain(WhyMaxPaths)
+	assert.Len(t, why.Paths, WhyMaxPaths)
+	assert.False(t, why.Truncated)
+
+	why = explain(WhyMaxPaths + 1)
+	assert.Len(t, why.Paths, WhyMaxPaths)
+	assert.True(t, why.Truncated)
+}